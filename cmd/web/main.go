@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"golectro-payment/internal/command"
 	"golectro-payment/internal/config"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -14,28 +21,71 @@ func main() {
 	validate := config.NewValidator(viper)
 	redis := config.NewRedis(viper, log)
 	kafkaWriter := config.NewKafkaWriter(viper, log)
+	kafkaReader := config.NewKafkaReader(viper, log)
+	kafkaDLQWriter := config.NewKafkaDLQWriter(viper, log)
+	paymentGateway := config.NewPaymentGateway(viper, log)
+	mailSender := config.NewMailSender(viper, log)
+	branding := config.NewBranding(viper, log)
 	app := config.NewGin(viper, log, mongo, redis)
-	executor := command.NewCommandExecutor(viper, db)
-
-	config.Bootstrap(&config.BootstrapConfig{
-		Viper:       viper,
-		Log:         log,
-		DB:          db,
-		Mongo:       mongo,
-		Validate:    validate,
-		App:         app,
-		Redis:       redis,
-		KafkaWriter: kafkaWriter,
+
+	application := config.Bootstrap(&config.BootstrapConfig{
+		Viper:          viper,
+		Log:            log,
+		DB:             db,
+		Mongo:          mongo,
+		Validate:       validate,
+		App:            app,
+		Redis:          redis,
+		KafkaWriter:    kafkaWriter,
+		KafkaReader:    kafkaReader,
+		KafkaDLQWriter: kafkaDLQWriter,
+		PaymentGateway: paymentGateway,
+		MailSender:     mailSender,
+		Branding:       branding,
 	})
 
 	defer kafkaWriter.Close()
+	defer kafkaReader.Close()
+	defer kafkaDLQWriter.Close()
 
-	if !executor.Execute(log) {
+	if !application.Executor.Execute(log) {
 		return
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, w := range application.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Infof("Starting worker %s", w.Name())
+			w.Run(ctx)
+		}()
+	}
+
 	webPort := viper.GetInt("PORT")
-	if err := app.Run(fmt.Sprintf(":%d", webPort)); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	server := &http.Server{
+		Addr:    fmt.Sprintf(":%d", webPort),
+		Handler: app,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Info("Shutting down server")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.WithError(err).Error("Failed to shut down server gracefully")
 	}
+
+	wg.Wait()
 }
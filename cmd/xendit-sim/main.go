@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"golectro-payment/internal/config"
+)
+
+func main() {
+	viper := config.NewViper()
+	log := config.NewLogger(viper)
+
+	viper.SetDefault("PORT", 8082)
+	viper.SetDefault("XENDIT_SIM_PORT", 4010)
+	viper.SetDefault("XENDIT_SIM_CALLBACK_URL", fmt.Sprintf("http://localhost:%d/api/v1/payment/xendit/callback", viper.GetInt("PORT")))
+	viper.SetDefault("XENDIT_SIM_REFUND_CALLBACK_URL", fmt.Sprintf("http://localhost:%d/api/v1/payment/xendit/refund/callback", viper.GetInt("PORT")))
+	viper.SetDefault("XENDIT_SIM_VA_CALLBACK_URL", fmt.Sprintf("http://localhost:%d/api/v1/payment/xendit/virtual-account/callback", viper.GetInt("PORT")))
+	viper.SetDefault("XENDIT_SIM_QR_CALLBACK_URL", fmt.Sprintf("http://localhost:%d/api/v1/payment/xendit/qr-code/callback", viper.GetInt("PORT")))
+
+	port := viper.GetInt("XENDIT_SIM_PORT")
+	simulator := NewSimulator(log, &SimulatorConfig{
+		BaseURL:           fmt.Sprintf("http://localhost:%d", port),
+		SecretKey:         viper.GetString("XENDIT_SECRET_KEY"),
+		CallbackURL:       viper.GetString("XENDIT_SIM_CALLBACK_URL"),
+		RefundCallbackURL: viper.GetString("XENDIT_SIM_REFUND_CALLBACK_URL"),
+		VACallbackURL:     viper.GetString("XENDIT_SIM_VA_CALLBACK_URL"),
+		QRCallbackURL:     viper.GetString("XENDIT_SIM_QR_CALLBACK_URL"),
+		CallbackToken:     viper.GetString("XENDIT_TOKEN"),
+	})
+
+	log.Infof("Xendit simulator listening on :%d, callbacks go to %s", port, viper.GetString("XENDIT_SIM_CALLBACK_URL"))
+	if err := simulator.Router().Run(fmt.Sprintf(":%d", port)); err != nil {
+		log.Fatalf("Failed to start Xendit simulator: %v", err)
+	}
+}
@@ -0,0 +1,764 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/xendit/xendit-go"
+	"github.com/xendit/xendit-go/invoice"
+	"github.com/xendit/xendit-go/virtualaccount"
+)
+
+type SimulatorConfig struct {
+	BaseURL           string
+	SecretKey         string
+	CallbackURL       string
+	RefundCallbackURL string
+	VACallbackURL     string
+	QRCallbackURL     string
+	CallbackToken     string
+}
+
+type Simulator struct {
+	Log        *logrus.Logger
+	Config     *SimulatorConfig
+	HTTPClient *http.Client
+	mu         sync.RWMutex
+	invoices   map[string]*xendit.Invoice
+	refunds    map[string]*refund
+	accounts   map[string]*xendit.VirtualAccount
+	qrCodes    map[string]*qrCode
+}
+
+type refund struct {
+	ID          string    `json:"id"`
+	InvoiceID   string    `json:"invoice_id"`
+	ReferenceID string    `json:"reference_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Reason      string    `json:"reason"`
+	Status      string    `json:"status"`
+	FailureCode string    `json:"failure_code,omitempty"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+type refundCallbackPayload struct {
+	Event   string    `json:"event"`
+	Created time.Time `json:"created"`
+	Data    *refund   `json:"data"`
+}
+
+type virtualAccountPaymentPayload struct {
+	ID                       string    `json:"id"`
+	PaymentID                string    `json:"payment_id"`
+	CallbackVirtualAccountID string    `json:"callback_virtual_account_id"`
+	OwnerID                  string    `json:"owner_id"`
+	ExternalID               string    `json:"external_id"`
+	AccountNumber            string    `json:"account_number"`
+	BankCode                 string    `json:"bank_code"`
+	Amount                   float64   `json:"amount"`
+	Currency                 string    `json:"currency"`
+	MerchantCode             string    `json:"merchant_code"`
+	TransactionTimestamp     time.Time `json:"transaction_timestamp"`
+	Created                  time.Time `json:"created"`
+	Updated                  time.Time `json:"updated"`
+}
+
+type qrCode struct {
+	ID          string     `json:"id"`
+	ReferenceID string     `json:"reference_id"`
+	BusinessID  string     `json:"business_id"`
+	Type        string     `json:"type"`
+	Currency    string     `json:"currency"`
+	Amount      float64    `json:"amount"`
+	ChannelCode string     `json:"channel_code"`
+	Status      string     `json:"status"`
+	QRString    string     `json:"qr_string"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	Created     time.Time  `json:"created"`
+	Updated     time.Time  `json:"updated"`
+}
+
+type qrCodePayment struct {
+	ID          string    `json:"id"`
+	QRID        string    `json:"qr_id"`
+	ReferenceID string    `json:"reference_id"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Status      string    `json:"status"`
+	ChannelCode string    `json:"channel_code"`
+	Created     time.Time `json:"created"`
+}
+
+type qrCodePaymentPayload struct {
+	Event      string         `json:"event"`
+	BusinessID string         `json:"business_id"`
+	Created    time.Time      `json:"created"`
+	Data       *qrCodePayment `json:"data"`
+}
+
+type simulatePaymentRequest struct {
+	PaymentMethod  string  `json:"payment_method"`
+	PaymentChannel string  `json:"payment_channel"`
+	PaidAmount     float64 `json:"paid_amount"`
+}
+
+type callbackPayload struct {
+	ID             string     `json:"id"`
+	ExternalID     string     `json:"external_id"`
+	UserID         string     `json:"user_id"`
+	Status         string     `json:"status"`
+	MerchantName   string     `json:"merchant_name"`
+	Amount         float64    `json:"amount"`
+	PaidAmount     float64    `json:"paid_amount,omitempty"`
+	PayerEmail     string     `json:"payer_email"`
+	Description    string     `json:"description"`
+	Currency       string     `json:"currency"`
+	PaymentMethod  string     `json:"payment_method,omitempty"`
+	PaymentChannel string     `json:"payment_channel,omitempty"`
+	PaidAt         *time.Time `json:"paid_at,omitempty"`
+	Created        *time.Time `json:"created"`
+	Updated        *time.Time `json:"updated"`
+}
+
+type callbackResult struct {
+	Invoice            *xendit.Invoice `json:"invoice,omitempty"`
+	Payload            any             `json:"payload,omitempty"`
+	CallbackURL        string          `json:"callback_url"`
+	CallbackStatusCode int             `json:"callback_status_code"`
+	CallbackResponse   json.RawMessage `json:"callback_response,omitempty"`
+	CallbackError      string          `json:"callback_error,omitempty"`
+}
+
+func NewSimulator(log *logrus.Logger, config *SimulatorConfig) *Simulator {
+	return &Simulator{
+		Log:        log,
+		Config:     config,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		invoices:   make(map[string]*xendit.Invoice),
+		refunds:    make(map[string]*refund),
+		accounts:   make(map[string]*xendit.VirtualAccount),
+		qrCodes:    make(map[string]*qrCode),
+	}
+}
+
+func (s *Simulator) Router() *gin.Engine {
+	app := gin.Default()
+
+	api := app.Group("/", s.authenticate)
+	api.POST("/v2/invoices", s.createInvoice)
+	api.GET("/v2/invoices/:id", s.getInvoice)
+	api.POST("/invoices/:id/expire!", s.expireInvoice)
+	api.POST("/refunds", s.createRefund)
+	api.GET("/refunds/:id", s.getRefund)
+	api.POST("/callback_virtual_accounts", s.createVirtualAccount)
+	api.GET("/callback_virtual_accounts/:id", s.getVirtualAccount)
+	api.POST("/qr_codes", s.createQRCode)
+	api.GET("/qr_codes/:id", s.getQRCode)
+
+	simulate := app.Group("/simulate")
+	simulate.GET("/invoices", s.listInvoices)
+	simulate.POST("/invoices/:id/pay", s.payInvoice)
+	simulate.POST("/invoices/:id/expire", s.expireInvoiceWithCallback)
+	simulate.POST("/refunds/:id/succeed", s.completeRefund("SUCCEEDED"))
+	simulate.POST("/refunds/:id/fail", s.completeRefund("FAILED"))
+	simulate.GET("/virtual_accounts", s.listVirtualAccounts)
+	simulate.POST("/virtual_accounts/:id/pay", s.payVirtualAccount)
+	simulate.GET("/qr_codes", s.listQRCodes)
+	simulate.POST("/qr_codes/:id/pay", s.payQRCode)
+
+	return app
+}
+
+func (s *Simulator) authenticate(ctx *gin.Context) {
+	if s.Config.SecretKey == "" {
+		ctx.Next()
+		return
+	}
+
+	username, _, ok := ctx.Request.BasicAuth()
+	if !ok || username != s.Config.SecretKey {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, &xendit.Error{
+			ErrorCode: "INVALID_API_KEY",
+			Message:   "API key is invalid",
+		})
+		return
+	}
+
+	ctx.Next()
+}
+
+func (s *Simulator) createInvoice(ctx *gin.Context) {
+	params := new(invoice.CreateParams)
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: err.Error()})
+		return
+	}
+	if params.ExternalID == "" || params.Amount <= 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: "external_id and amount are required"})
+		return
+	}
+
+	currency := params.Currency
+	if currency == "" {
+		currency = "IDR"
+	}
+
+	duration := params.InvoiceDuration
+	if duration <= 0 {
+		duration = 86400
+	}
+
+	now := time.Now().UTC()
+	expiry := now.Add(time.Duration(duration) * time.Second)
+	id := uuid.NewString()
+
+	inv := &xendit.Invoice{
+		ID:                 id,
+		InvoiceURL:         fmt.Sprintf("%s/web/%s", s.Config.BaseURL, id),
+		ExternalID:         params.ExternalID,
+		Status:             "PENDING",
+		MerchantName:       "Golectro Simulator",
+		Amount:             params.Amount,
+		PayerEmail:         params.PayerEmail,
+		Description:        params.Description,
+		ExpiryDate:         &expiry,
+		Currency:           currency,
+		Items:              params.Items,
+		Customer:           params.Customer,
+		Locale:             params.Locale,
+		SuccessRedirectURL: params.SuccessRedirectURL,
+		FailureRedirectURL: params.FailureRedirectURL,
+		Created:            &now,
+		Updated:            &now,
+	}
+
+	s.mu.Lock()
+	s.invoices[id] = inv
+	s.mu.Unlock()
+
+	s.Log.WithField("xendit_id", id).Infof("Simulated invoice created for external ID %s", params.ExternalID)
+	ctx.JSON(http.StatusOK, inv)
+}
+
+func (s *Simulator) getInvoice(ctx *gin.Context) {
+	inv, ok := s.findInvoice(ctx.Param("id"))
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "INVOICE_NOT_FOUND_ERROR", Message: "Invoice not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, inv)
+}
+
+func (s *Simulator) listInvoices(ctx *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	invoices := make([]*xendit.Invoice, 0, len(s.invoices))
+	for _, inv := range s.invoices {
+		invoices = append(invoices, inv)
+	}
+	ctx.JSON(http.StatusOK, invoices)
+}
+
+func (s *Simulator) expireInvoice(ctx *gin.Context) {
+	inv, err := s.transition(ctx.Param("id"), "EXPIRED", nil)
+	if err != nil {
+		s.abortWithTransitionError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, inv)
+}
+
+func (s *Simulator) payInvoice(ctx *gin.Context) {
+	request := new(simulatePaymentRequest)
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: err.Error()})
+			return
+		}
+	}
+	if request.PaymentMethod == "" {
+		request.PaymentMethod = "BANK_TRANSFER"
+	}
+	if request.PaymentChannel == "" {
+		request.PaymentChannel = "BCA"
+	}
+
+	inv, err := s.transition(ctx.Param("id"), "PAID", request)
+	if err != nil {
+		s.abortWithTransitionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.sendCallback(ctx, inv))
+}
+
+func (s *Simulator) expireInvoiceWithCallback(ctx *gin.Context) {
+	inv, err := s.transition(ctx.Param("id"), "EXPIRED", nil)
+	if err != nil {
+		s.abortWithTransitionError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, s.sendCallback(ctx, inv))
+}
+
+func (s *Simulator) createRefund(ctx *gin.Context) {
+	request := new(refund)
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invoices[request.InvoiceID]
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "DATA_NOT_FOUND", Message: "Invoice not found"})
+		return
+	}
+	if inv.Status != "PAID" && inv.Status != "SETTLED" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "INELIGIBLE_TRANSACTION_STATUS", Message: "Invoice is not paid"})
+		return
+	}
+
+	var refunded float64
+	for _, r := range s.refunds {
+		if r.ReferenceID == request.ReferenceID {
+			ctx.JSON(http.StatusOK, r)
+			return
+		}
+		if r.InvoiceID == inv.ID && r.Status != "FAILED" {
+			refunded += r.Amount
+		}
+	}
+	if request.Amount <= 0 || refunded+request.Amount > inv.PaidAmount {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "REFUND_AMOUNT_EXCEEDED", Message: "Refund amount exceeds the paid amount"})
+		return
+	}
+
+	now := time.Now().UTC()
+	request.ID = "rfd-" + uuid.NewString()
+	request.Currency = inv.Currency
+	request.Status = "PENDING"
+	request.Created = now
+	request.Updated = now
+	s.refunds[request.ID] = request
+
+	s.Log.WithField("refund_id", request.ID).Infof("Simulated refund created for invoice %s", inv.ID)
+	ctx.JSON(http.StatusOK, request)
+}
+
+func (s *Simulator) getRefund(ctx *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.refunds[ctx.Param("id")]
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "DATA_NOT_FOUND", Message: "Refund not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, r)
+}
+
+func (s *Simulator) completeRefund(status string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		s.mu.Lock()
+		r, ok := s.refunds[ctx.Param("id")]
+		if !ok {
+			s.mu.Unlock()
+			ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "DATA_NOT_FOUND", Message: "Refund not found"})
+			return
+		}
+		if r.Status != "PENDING" {
+			s.mu.Unlock()
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "INVALID_STATUS_TRANSITION", Message: "refund is already " + r.Status})
+			return
+		}
+
+		r.Status = status
+		r.Updated = time.Now().UTC()
+		if status == "FAILED" {
+			r.FailureCode = "INSUFFICIENT_BALANCE"
+		}
+		result := *r
+		s.mu.Unlock()
+
+		payload := &refundCallbackPayload{
+			Event:   "refund." + strings.ToLower(status),
+			Created: result.Updated,
+			Data:    &result,
+		}
+		ctx.JSON(http.StatusOK, s.deliver(ctx, s.Config.RefundCallbackURL, result.ID, payload))
+	}
+}
+
+func (s *Simulator) createVirtualAccount(ctx *gin.Context) {
+	params := new(virtualaccount.CreateFixedVAParams)
+	if err := ctx.ShouldBindJSON(params); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: err.Error()})
+		return
+	}
+	if params.ExternalID == "" || params.BankCode == "" || params.Name == "" {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: "external_id, bank_code and name are required"})
+		return
+	}
+
+	expiry := params.ExpirationDate
+	if expiry == nil {
+		later := time.Now().UTC().AddDate(31, 0, 0)
+		expiry = &later
+	}
+
+	id := uuid.NewString()
+	va := &xendit.VirtualAccount{
+		ID:             id,
+		OwnerID:        "simulator",
+		ExternalID:     params.ExternalID,
+		BankCode:       params.BankCode,
+		MerchantCode:   "88608",
+		Name:           params.Name,
+		AccountNumber:  fmt.Sprintf("88608%09d", time.Now().UnixNano()%1e9),
+		IsClosed:       params.IsClosed,
+		IsSingleUse:    params.IsSingleUse,
+		Status:         "ACTIVE",
+		Currency:       "IDR",
+		ExpirationDate: expiry,
+		ExpectedAmount: params.ExpectedAmount,
+		Description:    params.Description,
+	}
+
+	s.mu.Lock()
+	s.accounts[id] = va
+	s.mu.Unlock()
+
+	s.Log.WithField("virtual_account_id", id).Infof("Simulated %s virtual account created for external ID %s", va.BankCode, va.ExternalID)
+	ctx.JSON(http.StatusOK, va)
+}
+
+func (s *Simulator) getVirtualAccount(ctx *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	va, ok := s.accounts[ctx.Param("id")]
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "CALLBACK_VIRTUAL_ACCOUNT_NOT_FOUND_ERROR", Message: "Virtual account not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, va)
+}
+
+func (s *Simulator) listVirtualAccounts(ctx *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	accounts := make([]*xendit.VirtualAccount, 0, len(s.accounts))
+	for _, va := range s.accounts {
+		accounts = append(accounts, va)
+	}
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+// payVirtualAccount pays the expected amount unless the body names another
+// one, and closes single-use accounts the way Xendit does.
+func (s *Simulator) payVirtualAccount(ctx *gin.Context) {
+	request := new(simulatePaymentRequest)
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(request); err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: err.Error()})
+			return
+		}
+	}
+
+	s.mu.Lock()
+	va, ok := s.accounts[ctx.Param("id")]
+	if !ok {
+		s.mu.Unlock()
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "CALLBACK_VIRTUAL_ACCOUNT_NOT_FOUND_ERROR", Message: "Virtual account not found"})
+		return
+	}
+	now := time.Now().UTC()
+	if va.Status != "ACTIVE" || (va.ExpirationDate != nil && va.ExpirationDate.Before(now)) {
+		s.mu.Unlock()
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "INACTIVE_VIRTUAL_ACCOUNT_ERROR", Message: "Virtual account is no longer active"})
+		return
+	}
+
+	amount := va.ExpectedAmount
+	if request.PaidAmount > 0 {
+		amount = request.PaidAmount
+	}
+	if va.IsClosed != nil && *va.IsClosed && amount != va.ExpectedAmount {
+		s.mu.Unlock()
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "INVALID_AMOUNT_ERROR", Message: "Closed virtual account only accepts the expected amount"})
+		return
+	}
+	if va.IsSingleUse != nil && *va.IsSingleUse {
+		va.Status = "INACTIVE"
+	}
+	payload := &virtualAccountPaymentPayload{
+		ID:                       uuid.NewString(),
+		PaymentID:                "pmt-" + uuid.NewString(),
+		CallbackVirtualAccountID: va.ID,
+		OwnerID:                  va.OwnerID,
+		ExternalID:               va.ExternalID,
+		AccountNumber:            va.AccountNumber,
+		BankCode:                 va.BankCode,
+		Amount:                   amount,
+		Currency:                 va.Currency,
+		MerchantCode:             va.MerchantCode,
+		TransactionTimestamp:     now,
+		Created:                  now,
+		Updated:                  now,
+	}
+	s.mu.Unlock()
+
+	ctx.JSON(http.StatusOK, s.deliver(ctx, s.Config.VACallbackURL, va.ID, payload))
+}
+
+// createQRCode replays the stored code for a repeated Idempotency-Key, so a
+// retried request does not mint a second QR for the same reference.
+func (s *Simulator) createQRCode(ctx *gin.Context) {
+	var request struct {
+		ReferenceID string     `json:"reference_id"`
+		Type        string     `json:"type"`
+		Currency    string     `json:"currency"`
+		Amount      float64    `json:"amount"`
+		ExpiresAt   *time.Time `json:"expires_at"`
+	}
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: err.Error()})
+		return
+	}
+	if request.ReferenceID == "" || request.Type != "DYNAMIC" || request.Amount <= 0 {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: xendit.APIValidationErrCode, Message: "reference_id, type DYNAMIC and a positive amount are required"})
+		return
+	}
+	if request.Currency == "" {
+		request.Currency = "IDR"
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key := ctx.GetHeader("Idempotency-Key"); key != "" {
+		for _, qr := range s.qrCodes {
+			if qr.ReferenceID == key {
+				ctx.JSON(http.StatusOK, qr)
+				return
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	expiry := request.ExpiresAt
+	if expiry == nil {
+		later := now.Add(48 * time.Hour)
+		expiry = &later
+	}
+
+	id := "qr_" + uuid.NewString()
+	qr := &qrCode{
+		ID:          id,
+		ReferenceID: request.ReferenceID,
+		BusinessID:  "simulator",
+		Type:        request.Type,
+		Currency:    request.Currency,
+		Amount:      request.Amount,
+		ChannelCode: "ID_DANA",
+		Status:      "ACTIVE",
+		QRString:    fmt.Sprintf("00020101021226590016ID.CO.SIMULATOR0118%s5204599953033605404%.0f5802ID6304", strings.ReplaceAll(id[3:15], "-", ""), request.Amount),
+		ExpiresAt:   expiry,
+		Created:     now,
+		Updated:     now,
+	}
+	s.qrCodes[id] = qr
+
+	s.Log.WithField("qr_id", id).Infof("Simulated QR code created for reference ID %s", qr.ReferenceID)
+	ctx.JSON(http.StatusCreated, qr)
+}
+
+func (s *Simulator) getQRCode(ctx *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	qr, ok := s.qrCodes[ctx.Param("id")]
+	if !ok {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "DATA_NOT_FOUND", Message: "QR code not found"})
+		return
+	}
+	ctx.JSON(http.StatusOK, qr)
+}
+
+func (s *Simulator) listQRCodes(ctx *gin.Context) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	codes := make([]*qrCode, 0, len(s.qrCodes))
+	for _, qr := range s.qrCodes {
+		codes = append(codes, qr)
+	}
+	ctx.JSON(http.StatusOK, codes)
+}
+
+// payQRCode settles a dynamic code for its full amount and deactivates it, as
+// a scan from a payer's e-wallet would.
+func (s *Simulator) payQRCode(ctx *gin.Context) {
+	s.mu.Lock()
+	qr, ok := s.qrCodes[ctx.Param("id")]
+	if !ok {
+		s.mu.Unlock()
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "DATA_NOT_FOUND", Message: "QR code not found"})
+		return
+	}
+	now := time.Now().UTC()
+	if qr.Status != "ACTIVE" || (qr.ExpiresAt != nil && qr.ExpiresAt.Before(now)) {
+		s.mu.Unlock()
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "INACTIVE_QR_CODE", Message: "QR code is no longer active"})
+		return
+	}
+	qr.Status = "INACTIVE"
+	qr.Updated = now
+	payload := &qrCodePaymentPayload{
+		Event:      "qr.payment",
+		BusinessID: qr.BusinessID,
+		Created:    now,
+		Data: &qrCodePayment{
+			ID:          "qrpy_" + uuid.NewString(),
+			QRID:        qr.ID,
+			ReferenceID: qr.ReferenceID,
+			Amount:      qr.Amount,
+			Currency:    qr.Currency,
+			Status:      "SUCCEEDED",
+			ChannelCode: qr.ChannelCode,
+			Created:     now,
+		},
+	}
+	s.mu.Unlock()
+
+	ctx.JSON(http.StatusOK, s.deliver(ctx, s.Config.QRCallbackURL, qr.ID, payload))
+}
+
+var errInvoiceNotFound = errors.New("invoice not found")
+
+func (s *Simulator) findInvoice(id string) (*xendit.Invoice, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inv, ok := s.invoices[id]
+	if !ok {
+		return nil, false
+	}
+	result := *inv
+	return &result, true
+}
+
+func (s *Simulator) transition(id, status string, payment *simulatePaymentRequest) (*xendit.Invoice, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv, ok := s.invoices[id]
+	if !ok {
+		return nil, errInvoiceNotFound
+	}
+	if inv.Status != "PENDING" {
+		return nil, fmt.Errorf("invoice is already %s", inv.Status)
+	}
+
+	now := time.Now().UTC()
+	inv.Status = status
+	inv.Updated = &now
+
+	if payment != nil {
+		inv.PaymentMethod = payment.PaymentMethod
+		inv.PaymentChannel = payment.PaymentChannel
+		inv.PaidAmount = inv.Amount
+		if payment.PaidAmount > 0 {
+			inv.PaidAmount = payment.PaidAmount
+		}
+		inv.PaidAt = &now
+	}
+
+	result := *inv
+	return &result, nil
+}
+
+func (s *Simulator) abortWithTransitionError(ctx *gin.Context, err error) {
+	if err == errInvoiceNotFound {
+		ctx.AbortWithStatusJSON(http.StatusNotFound, &xendit.Error{ErrorCode: "INVOICE_NOT_FOUND_ERROR", Message: err.Error()})
+		return
+	}
+	ctx.AbortWithStatusJSON(http.StatusBadRequest, &xendit.Error{ErrorCode: "INVALID_STATUS_TRANSITION", Message: err.Error()})
+}
+
+func (s *Simulator) sendCallback(ctx context.Context, inv *xendit.Invoice) *callbackResult {
+	result := s.deliver(ctx, s.Config.CallbackURL, inv.ID, &callbackPayload{
+		ID:             inv.ID,
+		ExternalID:     inv.ExternalID,
+		UserID:         inv.UserID,
+		Status:         inv.Status,
+		MerchantName:   inv.MerchantName,
+		Amount:         inv.Amount,
+		PaidAmount:     inv.PaidAmount,
+		PayerEmail:     inv.PayerEmail,
+		Description:    inv.Description,
+		Currency:       inv.Currency,
+		PaymentMethod:  inv.PaymentMethod,
+		PaymentChannel: inv.PaymentChannel,
+		PaidAt:         inv.PaidAt,
+		Created:        inv.Created,
+		Updated:        inv.Updated,
+	})
+	result.Invoice = inv
+	result.Payload = nil
+	return result
+}
+
+func (s *Simulator) deliver(ctx context.Context, url, resourceID string, payload any) *callbackResult {
+	result := &callbackResult{Payload: payload, CallbackURL: url}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		result.CallbackError = err.Error()
+		return result
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		result.CallbackError = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-callback-token", s.Config.CallbackToken)
+	req.Header.Set("webhook-id", uuid.NewString())
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		s.Log.WithError(err).Error("Failed to deliver simulated callback")
+		result.CallbackError = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	var response json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&response); err == nil {
+		result.CallbackResponse = response
+	}
+	result.CallbackStatusCode = resp.StatusCode
+
+	s.Log.WithField("resource_id", resourceID).Infof("Simulated callback delivered to %s with status %d", url, resp.StatusCode)
+	return result
+}
@@ -1,10 +1,13 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"golectro-payment/internal/migrations"
+	"golectro-payment/internal/usecase"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
@@ -12,14 +15,18 @@ import (
 )
 
 type CommandExecutor struct {
-	DB    *gorm.DB
-	Viper *viper.Viper
+	DB                    *gorm.DB
+	Viper                 *viper.Viper
+	WebhookUseCase        *usecase.WebhookUseCase
+	ReconciliationUseCase *usecase.ReconciliationUseCase
 }
 
-func NewCommandExecutor(viper *viper.Viper, db *gorm.DB) *CommandExecutor {
+func NewCommandExecutor(viper *viper.Viper, db *gorm.DB, webhookUseCase *usecase.WebhookUseCase, reconciliationUseCase *usecase.ReconciliationUseCase) *CommandExecutor {
 	return &CommandExecutor{
-		DB:    db,
-		Viper: viper,
+		DB:                    db,
+		Viper:                 viper,
+		WebhookUseCase:        webhookUseCase,
+		ReconciliationUseCase: reconciliationUseCase,
 	}
 }
 
@@ -29,6 +36,8 @@ func (ce *CommandExecutor) Execute(logger *logrus.Logger) bool {
 		return true
 	}
 
+	options := parseOptions(args)
+
 	run := false
 	for _, arg := range args {
 		switch arg {
@@ -42,6 +51,10 @@ func (ce *CommandExecutor) Execute(logger *logrus.Logger) bool {
 			ce.handleDropDB(logger)
 		case "--drop-table":
 			ce.handleDropTable(logger)
+		case "--replay-webhooks":
+			ce.handleReplayWebhooks(logger, options)
+		case "--reconcile":
+			ce.handleReconcile(logger, options)
 		case "--run":
 			run = true
 		}
@@ -50,6 +63,16 @@ func (ce *CommandExecutor) Execute(logger *logrus.Logger) bool {
 	return run
 }
 
+func parseOptions(args []string) map[string]string {
+	options := make(map[string]string)
+	for _, arg := range args {
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			options[name] = value
+		}
+	}
+	return options
+}
+
 func (ce *CommandExecutor) handleMigrate(logger *logrus.Logger) {
 	if err := migrations.Migrate(ce.DB); err != nil {
 		logger.Fatalf("❌ Migration failed: %v", err)
@@ -110,3 +133,57 @@ func (ce *CommandExecutor) handleDropTable(logger *logrus.Logger) {
 		logger.Printf("✅ Table '%s' dropped\n", table)
 	}
 }
+
+func (ce *CommandExecutor) handleReplayWebhooks(logger *logrus.Logger, options map[string]string) {
+	var eventIDs []string
+	for id := range strings.SplitSeq(options["--webhook-ids"], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			eventIDs = append(eventIDs, id)
+		}
+	}
+
+	var from, to time.Time
+	if len(eventIDs) == 0 {
+		if options["--from"] == "" {
+			logger.Fatal("❌ --replay-webhooks requires --webhook-ids=<id,...> or --from=<RFC3339> [--to=<RFC3339>]")
+		}
+
+		var err error
+		if from, err = time.Parse(time.RFC3339, options["--from"]); err != nil {
+			logger.Fatalf("❌ Invalid --from value: %v", err)
+		}
+
+		to = time.Now()
+		if options["--to"] != "" {
+			if to, err = time.Parse(time.RFC3339, options["--to"]); err != nil {
+				logger.Fatalf("❌ Invalid --to value: %v", err)
+			}
+		}
+	}
+
+	processed, failed, err := ce.WebhookUseCase.Replay(context.Background(), eventIDs, from, to)
+	if err != nil {
+		logger.Fatalf("❌ Webhook replay failed: %v", err)
+	}
+	logger.Printf("✅ Webhook replay completed: %d processed, %d failed\n", processed, failed)
+}
+
+func (ce *CommandExecutor) handleReconcile(logger *logrus.Logger, options map[string]string) {
+	var olderThan time.Duration
+	if options["--older-than"] != "" {
+		var err error
+		if olderThan, err = time.ParseDuration(options["--older-than"]); err != nil {
+			logger.Fatalf("❌ Invalid --older-than value: %v", err)
+		}
+	}
+
+	report, err := ce.ReconciliationUseCase.Reconcile(context.Background(), "command", olderThan)
+	if err != nil {
+		logger.Fatalf("❌ Reconciliation failed: %v", err)
+	}
+	if report == nil {
+		logger.Println("⚠️ Reconciliation skipped, another run is in progress")
+		return
+	}
+	logger.Printf("✅ Reconciliation completed: %d checked, %d updated, %d discrepancies\n", report.Checked, report.Updated, len(report.Discrepancies))
+}
@@ -1,12 +1,19 @@
 package config
 
 import (
+	"golectro-payment/internal/command"
 	"golectro-payment/internal/delivery/grpc/client"
+	"golectro-payment/internal/delivery/grpc/server"
 	"golectro-payment/internal/delivery/http"
 	"golectro-payment/internal/delivery/http/middleware"
 	"golectro-payment/internal/delivery/http/route"
+	"golectro-payment/internal/delivery/messaging"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/mailer"
+	"golectro-payment/internal/model"
 	"golectro-payment/internal/repository"
 	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/worker"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -20,33 +27,98 @@ import (
 )
 
 type BootstrapConfig struct {
-	DB          *gorm.DB
-	Mongo       *mongo.Database
-	App         *gin.Engine
-	Redis       *redis.Client
-	Log         *logrus.Logger
-	Validate    *validator.Validate
-	Viper       *viper.Viper
-	GRPCClient  *grpc.ClientConn
-	KafkaWriter *kafka.Writer
+	DB             *gorm.DB
+	Mongo          *mongo.Database
+	App            *gin.Engine
+	Redis          *redis.Client
+	Log            *logrus.Logger
+	Validate       *validator.Validate
+	Viper          *viper.Viper
+	GRPCClient     *grpc.ClientConn
+	KafkaWriter    *kafka.Writer
+	KafkaReader    *kafka.Reader
+	KafkaDLQWriter *kafka.Writer
+	PaymentGateway gateway.PaymentGateway
+	MailSender     mailer.Sender
+	Branding       *model.Branding
 }
 
-func Bootstrap(config *BootstrapConfig) {
+type Application struct {
+	Executor *command.CommandExecutor
+	Workers  []worker.Worker
+}
+
+func Bootstrap(config *BootstrapConfig) *Application {
 	orderClient := client.NewOrderClient(config.Log, config.Viper)
 
 	invoiceRepository := repository.NewInvoiceRepository(config.Log)
+	refundRepository := repository.NewRefundRepository(config.Log)
+	webhookEventRepository := repository.NewWebhookEventRepository(config.Log)
+	outboxRepository := repository.NewOutboxRepository(config.Log)
+	invoiceStatusHistoryRepository := repository.NewInvoiceStatusHistoryRepository(config.Log)
+	exchangeRateRepository := repository.NewExchangeRateRepository(config.Log)
+	notificationRepository := repository.NewNotificationRepository(config.Log)
+	voucherRepository := repository.NewVoucherRepository(config.Log)
+	voucherRedemptionRepository := repository.NewVoucherRedemptionRepository(config.Log)
+	paymentAttemptRepository := repository.NewPaymentAttemptRepository(config.Log)
+	raisedAnomalyRepository := repository.NewRaisedAnomalyRepository(config.Log)
+
+	notificationUseCase := usecase.NewNotificationUsecase(config.DB, config.Log, config.Viper, config.Branding, notificationRepository, invoiceRepository, config.MailSender)
+	voucherUseCase := usecase.NewVoucherUsecase(config.DB, config.Log, config.Validate, config.Viper, voucherRepository, voucherRedemptionRepository)
+	invoiceStatusUseCase := usecase.NewInvoiceStatusUsecase(config.DB, config.Log, config.Redis, invoiceRepository, invoiceStatusHistoryRepository, notificationUseCase, voucherUseCase)
+	outboxUseCase := usecase.NewOutboxUsecase(config.Log, outboxRepository)
+	anomalyUseCase := usecase.NewAnomalyUsecase(config.Log, config.Mongo, raisedAnomalyRepository)
+	exchangeRateUseCase := usecase.NewExchangeRateUsecase(config.DB, config.Log, config.Validate, config.Viper, config.Redis, exchangeRateRepository)
+	paymentUseCase := usecase.NewPaymentUsecase(config.DB, config.Log, config.Validate, config.Viper, invoiceRepository, invoiceStatusUseCase, outboxUseCase, exchangeRateUseCase, anomalyUseCase, voucherUseCase, config.PaymentGateway, orderClient)
+
+	directPaymentUseCase := usecase.NewDirectPaymentUsecase(config.DB, config.Log, config.Validate, config.Viper, config.Branding, config.Redis, invoiceRepository, paymentAttemptRepository, invoiceStatusUseCase, outboxUseCase, anomalyUseCase, config.PaymentGateway)
+
+	orderEventUseCase := usecase.NewOrderEventUsecase(config.DB, config.Log, config.Validate, invoiceRepository, invoiceStatusUseCase, outboxUseCase, exchangeRateUseCase, voucherUseCase, paymentUseCase, config.PaymentGateway, orderClient)
+
+	refundUseCase := usecase.NewRefundUsecase(config.DB, config.Log, config.Validate, config.Viper, invoiceRepository, refundRepository, invoiceStatusUseCase, outboxUseCase, config.PaymentGateway, orderClient)
 
-	paymentUseCase := usecase.NewPaymentUsecase(config.DB, config.Log, config.Validate, config.Viper, invoiceRepository)
+	reconciliationUseCase := usecase.NewReconciliationUsecase(config.DB, config.Log, config.Viper, config.Redis, config.Mongo, invoiceRepository, paymentUseCase, config.PaymentGateway)
 
-	paymentController := http.NewPaymentController(config.Log, config.Viper, paymentUseCase, config.KafkaWriter, orderClient)
+	adminUseCase := usecase.NewAdminUsecase(config.DB, config.Log, invoiceRepository, invoiceStatusHistoryRepository, refundRepository, paymentUseCase, config.PaymentGateway)
+	logUseCase := usecase.NewLogUsecase(config.Mongo)
+	receiptUseCase := usecase.NewReceiptUsecase(config.DB, config.Log, config.Viper, config.Branding, config.Redis, invoiceRepository)
+
+	webhookUseCase := usecase.NewWebhookUsecase(config.DB, config.Log, webhookEventRepository, paymentUseCase, refundUseCase, directPaymentUseCase)
+
+	paymentController := http.NewPaymentController(config.Log, config.Viper, paymentUseCase, directPaymentUseCase, webhookUseCase, receiptUseCase)
+	refundController := http.NewRefundController(config.Log, config.Viper, refundUseCase, webhookUseCase)
+	adminController := http.NewAdminController(config.Log, paymentUseCase, adminUseCase, refundUseCase, exchangeRateUseCase, voucherUseCase, logUseCase)
 
 	authMiddleware := middleware.NewAuth(config.Viper)
+	idempotencyMiddleware := middleware.NewIdempotency(config.Viper, config.Redis)
+
+	adminRoles := config.Viper.GetStringSlice("ADMIN_ROLES")
+	if len(adminRoles) == 0 {
+		adminRoles = []string{"payment-admin", "finance"}
+	}
+	adminMiddleware := middleware.NewRole(adminRoles...)
 
 	routeConfig := route.RouteConfig{
-		App:               config.App,
-		AuthMiddleware:    authMiddleware,
-		Viper:             config.Viper,
-		PaymentController: paymentController,
+		App:                   config.App,
+		AuthMiddleware:        authMiddleware,
+		IdempotencyMiddleware: idempotencyMiddleware,
+		AdminMiddleware:       adminMiddleware,
+		Viper:                 config.Viper,
+		PaymentController:     paymentController,
+		RefundController:      refundController,
+		AdminController:       adminController,
 	}
 	routeConfig.Setup()
+
+	return &Application{
+		Executor: command.NewCommandExecutor(config.Viper, config.DB, webhookUseCase, reconciliationUseCase),
+		Workers: []worker.Worker{
+			worker.NewOutboxRelay(config.DB, config.Log, config.Viper, outboxRepository, config.KafkaWriter),
+			server.NewServer(config.Log, config.Viper, server.NewPaymentServer(config.Log, paymentUseCase, invoiceStatusUseCase)),
+			messaging.NewOrderConsumer(config.Log, config.Viper, config.KafkaReader, config.KafkaDLQWriter, orderEventUseCase),
+			worker.NewReconciliationWorker(config.Log, config.Viper, reconciliationUseCase),
+			worker.NewNotificationDispatcher(config.DB, config.Log, config.Viper, notificationRepository, notificationUseCase),
+			worker.NewVoucherReservationSweeper(config.Log, config.Viper, voucherUseCase),
+		},
+	}
 }
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"golectro-payment/internal/model"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewBranding(viper *viper.Viper, log *logrus.Logger) *model.Branding {
+	name := viper.GetString("RECEIPT_BRAND")
+	if name == "" {
+		name = "Golectro"
+	}
+
+	timezone := viper.GetString("RECEIPT_TIMEZONE")
+	if timezone == "" {
+		timezone = "Asia/Jakarta"
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.WithError(err).Warnf("Unknown receipt timezone %s, using UTC", timezone)
+		location = time.UTC
+	}
+
+	return &model.Branding{
+		Name:     name,
+		Location: location,
+	}
+}
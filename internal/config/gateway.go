@@ -0,0 +1,22 @@
+package config
+
+import (
+	"golectro-payment/internal/gateway"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewPaymentGateway(viper *viper.Viper, log *logrus.Logger) gateway.PaymentGateway {
+	switch viper.GetString("PAYMENT_GATEWAY") {
+	case "fake":
+		log.Warn("Using in-memory fake payment gateway")
+		return gateway.NewFakeGateway(log)
+	default:
+		secretKey := viper.GetString("XENDIT_SECRET_KEY")
+		if secretKey == "" {
+			log.Warn("XENDIT_SECRET_KEY is not set in configuration")
+		}
+		return gateway.NewXenditGateway(log, secretKey, viper.GetString("XENDIT_BASE_URL"))
+	}
+}
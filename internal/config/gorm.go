@@ -31,6 +31,7 @@ func NewDatabase(viper *viper.Viper, log *logrus.Logger) *gorm.DB {
 			ParameterizedQueries:      true,
 			LogLevel:                  logger.Info,
 		}),
+		TranslateError: true,
 	})
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
@@ -10,16 +10,12 @@ import (
 	"github.com/spf13/viper"
 )
 
-func ensureKafkaTopic(viper *viper.Viper, log *logrus.Logger) {
+func ensureKafkaTopic(viper *viper.Viper, log *logrus.Logger, topic string) {
 	brokers := viper.GetStringSlice("KAFKA_BROKERS")
-	topic := viper.GetString("KAFKA_TOPIC")
 
 	if len(brokers) == 0 {
 		log.Fatal("KAFKA_BROKERS is not set in configuration")
 	}
-	if topic == "" {
-		log.Fatal("KAFKA_TOPIC is not set in configuration")
-	}
 
 	conn, err := kafka.Dial("tcp", brokers[0])
 	if err != nil {
@@ -60,15 +56,30 @@ func ensureKafkaTopic(viper *viper.Viper, log *logrus.Logger) {
 }
 
 func NewKafkaWriter(viper *viper.Viper, log *logrus.Logger) *kafka.Writer {
-	ensureKafkaTopic(viper, log)
+	topic := viper.GetString("KAFKA_TOPIC")
+	if topic == "" {
+		log.Fatal("KAFKA_TOPIC is not set in configuration")
+	}
+	return newKafkaWriter(viper, log, topic)
+}
+
+func NewKafkaDLQWriter(viper *viper.Viper, log *logrus.Logger) *kafka.Writer {
+	topic := viper.GetString("KAFKA_DLQ_TOPIC")
+	if topic == "" {
+		topic = viper.GetString("KAFKA_ORDER_TOPIC") + ".dlq"
+	}
+	return newKafkaWriter(viper, log, topic)
+}
+
+func newKafkaWriter(viper *viper.Viper, log *logrus.Logger, topic string) *kafka.Writer {
+	ensureKafkaTopic(viper, log, topic)
 
 	brokers := viper.GetStringSlice("KAFKA_BROKERS")
-	topic := viper.GetString("KAFKA_TOPIC")
 
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(brokers...),
 		Topic:        topic,
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{},
 		RequiredAcks: kafka.RequireAll,
 		Async:        false,
 	}
@@ -78,11 +89,19 @@ func NewKafkaWriter(viper *viper.Viper, log *logrus.Logger) *kafka.Writer {
 }
 
 func NewKafkaReader(viper *viper.Viper, log *logrus.Logger) *kafka.Reader {
-	ensureKafkaTopic(viper, log)
+	topic := viper.GetString("KAFKA_ORDER_TOPIC")
+	groupID := viper.GetString("KAFKA_GROUP_ID")
+
+	if topic == "" {
+		log.Fatal("KAFKA_ORDER_TOPIC is not set in configuration")
+	}
+	if groupID == "" {
+		log.Fatal("KAFKA_GROUP_ID is not set in configuration")
+	}
+
+	ensureKafkaTopic(viper, log, topic)
 
 	brokers := viper.GetStringSlice("KAFKA_BROKERS")
-	topic := viper.GetString("KAFKA_TOPIC")
-	groupID := viper.GetString("KAFKA_GROUP_ID")
 
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  brokers,
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"golectro-payment/internal/mailer"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+func NewMailSender(viper *viper.Viper, log *logrus.Logger) mailer.Sender {
+	host := viper.GetString("SMTP_HOST")
+	if host == "" {
+		log.Warn("SMTP_HOST is not set, payer emails will only be logged")
+		return mailer.NewLogSender(log)
+	}
+
+	port := viper.GetInt("SMTP_PORT")
+	if port == 0 {
+		port = 1025
+	}
+	from := viper.GetString("SMTP_FROM")
+	if from == "" {
+		from = "no-reply@golectro.local"
+	}
+	fromName := viper.GetString("SMTP_FROM_NAME")
+	if fromName == "" {
+		fromName = "Golectro"
+	}
+
+	return mailer.NewSMTPSender(host, port, viper.GetString("SMTP_USERNAME"), viper.GetString("SMTP_PASSWORD"), from, fromName)
+}
@@ -1,10 +1,14 @@
 package config
 
 import (
+	"golectro-payment/internal/utils"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
 func NewValidator(viper *viper.Viper) *validator.Validate {
-	return validator.New()
+	validate := validator.New()
+	utils.RegisterValidations(validate)
+	return validate
 }
@@ -27,6 +27,10 @@ var (
 		"en": "Invalid token",
 		"id": "Token tidak valid",
 	}
+	ForbiddenAccess = model.Message{
+		"en": "You do not have permission to access this resource",
+		"id": "Anda tidak memiliki izin untuk mengakses sumber daya ini",
+	}
 	InvalidRequestData = model.Message{
 		"en": "Invalid request data",
 		"id": "Data permintaan tidak valid",
@@ -43,4 +47,20 @@ var (
 		"en": "Too many requests, please try again later",
 		"id": "Terlalu banyak permintaan, silakan coba lagi nanti",
 	}
+	InvalidIdempotencyKey = model.Message{
+		"en": "Invalid Idempotency-Key header",
+		"id": "Header Idempotency-Key tidak valid",
+	}
+	IdempotencyKeyReused = model.Message{
+		"en": "Idempotency-Key was already used with a different request",
+		"id": "Idempotency-Key sudah digunakan untuk permintaan yang berbeda",
+	}
+	IdempotencyRequestInProgress = model.Message{
+		"en": "A request with this Idempotency-Key is still being processed",
+		"id": "Permintaan dengan Idempotency-Key ini masih diproses",
+	}
+	WebhookAlreadyProcessed = model.Message{
+		"en": "Webhook event already processed",
+		"id": "Event webhook sudah diproses",
+	}
 )
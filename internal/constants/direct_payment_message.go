@@ -0,0 +1,57 @@
+package constants
+
+import "golectro-payment/internal/model"
+
+var (
+	VirtualAccountCreated = model.Message{
+		"en": "Virtual account created successfully",
+		"id": "Virtual account berhasil dibuat",
+	}
+	VirtualAccountBankNotSupported = model.Message{
+		"en": "Bank does not support virtual account payments",
+		"id": "Bank tidak mendukung pembayaran virtual account",
+	}
+	VirtualAccountCurrencyNotSupported = model.Message{
+		"en": "Virtual accounts can only be used for IDR invoices",
+		"id": "Virtual account hanya dapat digunakan untuk tagihan IDR",
+	}
+	QRCodeCreated = model.Message{
+		"en": "QR code created successfully",
+		"id": "Kode QR berhasil dibuat",
+	}
+	QRCodeCurrencyNotSupported = model.Message{
+		"en": "QRIS can only be used for IDR invoices",
+		"id": "QRIS hanya dapat digunakan untuk tagihan IDR",
+	}
+	InvoiceNotPayable = model.Message{
+		"en": "Invoice can no longer be paid",
+		"id": "Tagihan tidak dapat dibayar lagi",
+	}
+	PaymentAttemptInProgress = model.Message{
+		"en": "A payment instrument is already being created for this invoice, please retry shortly",
+		"id": "Instrumen pembayaran untuk tagihan ini sedang dibuat, silakan coba lagi sebentar lagi",
+	}
+	PaymentAttemptNotFound = model.Message{
+		"en": "Payment attempt not found",
+		"id": "Percobaan pembayaran tidak ditemukan",
+	}
+)
+
+// VirtualAccountInstructions are formatted with the bank, the account number
+// and the amount, in that order.
+var VirtualAccountInstructions = map[string][]string{
+	"en": {
+		"Open the %[1]s mobile app, internet banking or ATM.",
+		"Choose Transfer, then Virtual Account.",
+		"Enter virtual account number %[2]s.",
+		"Check that the amount is exactly %[3]s and confirm the payment.",
+		"The virtual account accepts a single payment and closes when the invoice expires.",
+	},
+	"id": {
+		"Buka mobile banking, internet banking atau ATM %[1]s.",
+		"Pilih Transfer, lalu Virtual Account.",
+		"Masukkan nomor virtual account %[2]s.",
+		"Pastikan jumlahnya tepat %[3]s lalu konfirmasi pembayaran.",
+		"Virtual account hanya menerima satu kali pembayaran dan ditutup saat tagihan kedaluwarsa.",
+	},
+}
@@ -0,0 +1,18 @@
+package constants
+
+import "golectro-payment/internal/model"
+
+var (
+	NotificationInvoiceCreatedSubject = model.Message{
+		"en": "Your invoice is ready",
+		"id": "Tagihan Anda sudah siap",
+	}
+	NotificationInvoicePaidSubject = model.Message{
+		"en": "Payment received",
+		"id": "Pembayaran diterima",
+	}
+	NotificationInvoiceExpiredSubject = model.Message{
+		"en": "Your invoice has expired",
+		"id": "Tagihan Anda telah kedaluwarsa",
+	}
+)
@@ -19,6 +19,18 @@ var (
 		"en": "Order not found",
 		"id": "Pesanan tidak ditemukan",
 	}
+	OrderNotOwned = model.Message{
+		"en": "Order does not belong to the current user",
+		"id": "Pesanan bukan milik pengguna saat ini",
+	}
+	OrderNotPayable = model.Message{
+		"en": "Order cannot be paid in its current status",
+		"id": "Pesanan tidak dapat dibayar pada status saat ini",
+	}
+	OrderTotalMismatch = model.Message{
+		"en": "Order total does not match the sum of its items",
+		"id": "Total pesanan tidak sesuai dengan jumlah item",
+	}
 )
 
 var (
@@ -39,3 +51,153 @@ var (
 		"id": "Akses tidak sah",
 	}
 )
+
+var (
+	RefundCreated = model.Message{
+		"en": "Refund created successfully",
+		"id": "Pengembalian dana berhasil dibuat",
+	}
+	RefundRetrieved = model.Message{
+		"en": "Refunds retrieved successfully",
+		"id": "Pengembalian dana berhasil diambil",
+	}
+	RefundUpdated = model.Message{
+		"en": "Refund updated successfully",
+		"id": "Pengembalian dana berhasil diperbarui",
+	}
+	RefundNotFound = model.Message{
+		"en": "Refund not found",
+		"id": "Pengembalian dana tidak ditemukan",
+	}
+	InvoiceNotRefundable = model.Message{
+		"en": "Invoice cannot be refunded in its current status",
+		"id": "Tagihan tidak dapat dikembalikan dananya pada status saat ini",
+	}
+	RefundAmountExceeded = model.Message{
+		"en": "Refund amount exceeds the refundable amount",
+		"id": "Jumlah pengembalian dana melebihi jumlah yang dapat dikembalikan",
+	}
+	OrderNotRefundable = model.Message{
+		"en": "Order cannot be refunded in its current status",
+		"id": "Pesanan tidak dapat dikembalikan dananya pada status saat ini",
+	}
+	FailedToCreateRefund = model.Message{
+		"en": "Failed to create refund",
+		"id": "Gagal membuat pengembalian dana",
+	}
+)
+
+var (
+	InvalidStatusTransition = model.Message{
+		"en": "Invoice status transition is not allowed",
+		"id": "Perubahan status tagihan tidak diizinkan",
+	}
+	CallbackCurrencyMismatch = model.Message{
+		"en": "Callback currency does not match the invoice currency",
+		"id": "Mata uang callback tidak sesuai dengan mata uang tagihan",
+	}
+)
+
+var (
+	InvalidOrderEvent = model.Message{
+		"en": "Order event payload is invalid",
+		"id": "Data event pesanan tidak valid",
+	}
+)
+
+var (
+	InvoiceSynced = model.Message{
+		"en": "Invoice synchronized with payment gateway",
+		"id": "Tagihan berhasil disinkronkan dengan payment gateway",
+	}
+	GatewayInvoiceNotFound = model.Message{
+		"en": "Invoice not found at payment gateway",
+		"id": "Tagihan tidak ditemukan di payment gateway",
+	}
+	GatewayAmountMismatch = model.Message{
+		"en": "Invoice amount differs from payment gateway",
+		"id": "Jumlah tagihan berbeda dengan payment gateway",
+	}
+)
+
+var (
+	ExchangeRateRetrieved = model.Message{
+		"en": "Exchange rates retrieved successfully",
+		"id": "Kurs berhasil diambil",
+	}
+	ExchangeRateUpdated = model.Message{
+		"en": "Exchange rate saved successfully",
+		"id": "Kurs berhasil disimpan",
+	}
+	ExchangeRateDeleted = model.Message{
+		"en": "Exchange rate deleted successfully",
+		"id": "Kurs berhasil dihapus",
+	}
+	ExchangeRateNotFound = model.Message{
+		"en": "Exchange rate for the requested currency is not available",
+		"id": "Kurs untuk mata uang yang diminta tidak tersedia",
+	}
+	RedirectURLNotAllowed = model.Message{
+		"en": "Redirect URL is not an allowed origin",
+		"id": "URL pengalihan bukan origin yang diizinkan",
+	}
+	InvoiceDurationOutOfRange = model.Message{
+		"en": "Invoice duration is outside the allowed range",
+		"id": "Durasi tagihan di luar rentang yang diizinkan",
+	}
+	PaymentMethodNotAllowed = model.Message{
+		"en": "Requested payment method is not available for this invoice",
+		"id": "Metode pembayaran yang diminta tidak tersedia untuk tagihan ini",
+	}
+	UnsupportedCurrency = model.Message{
+		"en": "Currency is not supported by any payment channel",
+		"id": "Mata uang tidak didukung oleh kanal pembayaran mana pun",
+	}
+)
+
+var (
+	VoucherRetrieved = model.Message{
+		"en": "Vouchers retrieved successfully",
+		"id": "Voucher berhasil diambil",
+	}
+	VoucherSaved = model.Message{
+		"en": "Voucher saved successfully",
+		"id": "Voucher berhasil disimpan",
+	}
+	VoucherDeactivated = model.Message{
+		"en": "Voucher deactivated successfully",
+		"id": "Voucher berhasil dinonaktifkan",
+	}
+	VoucherNotFound = model.Message{
+		"en": "Voucher code is invalid",
+		"id": "Kode voucher tidak valid",
+	}
+	VoucherNotActive = model.Message{
+		"en": "Voucher is not valid at this time",
+		"id": "Voucher tidak berlaku saat ini",
+	}
+	VoucherMinimumSpend = model.Message{
+		"en": "Order total does not meet the voucher's minimum spend",
+		"id": "Total pesanan belum memenuhi minimum belanja voucher",
+	}
+	VoucherUsageLimitReached = model.Message{
+		"en": "Voucher has reached its usage limit",
+		"id": "Voucher telah mencapai batas penggunaan",
+	}
+	VoucherUserLimitReached = model.Message{
+		"en": "You have reached the usage limit for this voucher",
+		"id": "Anda telah mencapai batas penggunaan voucher ini",
+	}
+	VoucherNotApplicable = model.Message{
+		"en": "Voucher cannot be applied to this order",
+		"id": "Voucher tidak dapat digunakan untuk pesanan ini",
+	}
+	VoucherInvalidPercentage = model.Message{
+		"en": "Percentage discount must be between 1 and 100",
+		"id": "Diskon persentase harus antara 1 dan 100",
+	}
+	VoucherInvalidPeriod = model.Message{
+		"en": "Voucher end time must be after its start time",
+		"id": "Waktu berakhir voucher harus setelah waktu mulai",
+	}
+)
@@ -0,0 +1,78 @@
+package constants
+
+import "golectro-payment/internal/model"
+
+var (
+	ReceiptTitle = model.Message{
+		"en": "Payment Receipt",
+		"id": "Bukti Pembayaran",
+	}
+	InvoiceTitle = model.Message{
+		"en": "Invoice",
+		"id": "Tagihan",
+	}
+	ReceiptInvoiceNumber = model.Message{
+		"en": "Invoice Number",
+		"id": "Nomor Tagihan",
+	}
+	ReceiptOrderNumber = model.Message{
+		"en": "Order Number",
+		"id": "Nomor Pesanan",
+	}
+	ReceiptStatus = model.Message{
+		"en": "Status",
+		"id": "Status",
+	}
+	ReceiptIssuedAt = model.Message{
+		"en": "Issued At",
+		"id": "Tanggal Terbit",
+	}
+	ReceiptPaidAt = model.Message{
+		"en": "Paid At",
+		"id": "Tanggal Bayar",
+	}
+	ReceiptPaymentMethod = model.Message{
+		"en": "Payment Method",
+		"id": "Metode Pembayaran",
+	}
+	ReceiptPaymentChannel = model.Message{
+		"en": "Payment Channel",
+		"id": "Kanal Pembayaran",
+	}
+	ReceiptBilledTo = model.Message{
+		"en": "Billed To",
+		"id": "Ditagihkan Kepada",
+	}
+	ReceiptItem = model.Message{
+		"en": "Item",
+		"id": "Barang",
+	}
+	ReceiptQuantity = model.Message{
+		"en": "Qty",
+		"id": "Jml",
+	}
+	ReceiptUnitPrice = model.Message{
+		"en": "Unit Price",
+		"id": "Harga Satuan",
+	}
+	ReceiptSubtotal = model.Message{
+		"en": "Subtotal",
+		"id": "Subtotal",
+	}
+	ReceiptDiscount = model.Message{
+		"en": "Discount",
+		"id": "Diskon",
+	}
+	ReceiptTotal = model.Message{
+		"en": "Total",
+		"id": "Total",
+	}
+	ReceiptAmountPaid = model.Message{
+		"en": "Amount Paid",
+		"id": "Jumlah Dibayar",
+	}
+	ReceiptFooter = model.Message{
+		"en": "This receipt is generated electronically and is valid without a signature.",
+		"id": "Bukti ini dibuat secara elektronik dan sah tanpa tanda tangan.",
+	}
+)
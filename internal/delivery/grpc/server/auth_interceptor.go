@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const serviceTokenHeader = "x-service-token"
+
+// ServiceAuth admits internal callers presenting the shared service token,
+// either as x-service-token or as a bearer authorization header. Health checks
+// stay open for orchestrators. Without a configured token every other call is
+// refused, so a missing secret cannot leave the API open.
+type ServiceAuth struct {
+	Log   *logrus.Logger
+	Token string
+}
+
+func NewServiceAuth(log *logrus.Logger, token string) *ServiceAuth {
+	if token == "" {
+		log.Warn("GRPC_SERVICE_TOKEN is not set, gRPC calls other than health checks will be refused")
+	}
+	return &ServiceAuth{
+		Log:   log,
+		Token: token,
+	}
+}
+
+func (a *ServiceAuth) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := a.authorize(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (a *ServiceAuth) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := a.authorize(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func (a *ServiceAuth) authorize(ctx context.Context, method string) error {
+	if strings.HasPrefix(method, "/"+healthpb.Health_ServiceDesc.ServiceName+"/") {
+		return nil
+	}
+
+	token := incomingServiceToken(ctx)
+	if a.Token == "" || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) != 1 {
+		a.Log.Warnf("Rejected unauthenticated gRPC call to %s", method)
+		return status.Error(codes.Unauthenticated, "invalid service token")
+	}
+	return nil
+}
+
+func incomingServiceToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(serviceTokenHeader); len(values) > 0 {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if token, found := strings.CutPrefix(values[0], "Bearer "); found {
+			return token
+		}
+	}
+	return ""
+}
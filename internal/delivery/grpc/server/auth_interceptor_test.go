@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const paymentMethod = "/payment.PaymentService/GetInvoiceByOrderID"
+
+func callUnary(auth *ServiceAuth, ctx context.Context, method string) error {
+	_, err := auth.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	return err
+}
+
+func withMetadata(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestServiceAuthAcceptsServiceToken(t *testing.T) {
+	auth := NewServiceAuth(logrus.New(), "secret")
+
+	assert.NoError(t, callUnary(auth, withMetadata(serviceTokenHeader, "secret"), paymentMethod))
+	assert.NoError(t, callUnary(auth, withMetadata("authorization", "Bearer secret"), paymentMethod))
+}
+
+func TestServiceAuthRejectsMissingOrWrongToken(t *testing.T) {
+	auth := NewServiceAuth(logrus.New(), "secret")
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		withMetadata(serviceTokenHeader, "wrong"),
+		withMetadata("authorization", "secret"),
+		withMetadata("authorization", "Basic secret"),
+	} {
+		assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(auth, ctx, paymentMethod)))
+	}
+}
+
+func TestServiceAuthWithoutTokenRefusesEverythingButHealthChecks(t *testing.T) {
+	auth := NewServiceAuth(logrus.New(), "")
+
+	assert.Equal(t, codes.Unauthenticated, status.Code(callUnary(auth, withMetadata(serviceTokenHeader, ""), paymentMethod)))
+	assert.NoError(t, callUnary(auth, context.Background(), "/grpc.health.v1.Health/Check"))
+}
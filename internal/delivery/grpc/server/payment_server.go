@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/utils"
+	"slices"
+	"time"
+
+	pb "golectro-payment/internal/delivery/grpc/proto/payment"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type PaymentServer struct {
+	pb.UnimplementedPaymentServiceServer
+	Log                  *logrus.Logger
+	PaymentUseCase       *usecase.PaymentUseCase
+	InvoiceStatusUseCase *usecase.InvoiceStatusUseCase
+}
+
+func NewPaymentServer(log *logrus.Logger, paymentUseCase *usecase.PaymentUseCase, invoiceStatusUseCase *usecase.InvoiceStatusUseCase) *PaymentServer {
+	return &PaymentServer{
+		Log:                  log,
+		PaymentUseCase:       paymentUseCase,
+		InvoiceStatusUseCase: invoiceStatusUseCase,
+	}
+}
+
+func (s *PaymentServer) GetInvoiceByOrderID(ctx context.Context, req *pb.GetInvoiceByOrderIdRequest) (*pb.Invoice, error) {
+	orderID, err := uuid.Parse(req.GetOrderId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid order_id")
+	}
+
+	invoice, err := s.PaymentUseCase.GetInvoiceByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toProtoInvoice(invoice), nil
+}
+
+func (s *PaymentServer) ListInvoicesByUser(ctx context.Context, req *pb.ListInvoicesByUserRequest) (*pb.ListInvoicesByUserResponse, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+
+	invoices, err := s.PaymentUseCase.GetInvoiceByUserID(ctx, userID)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	response := &pb.ListInvoicesByUserResponse{}
+	for _, invoice := range invoices {
+		response.Invoices = append(response.Invoices, toProtoInvoice(invoice))
+	}
+
+	return response, nil
+}
+
+func (s *PaymentServer) CreateInvoice(ctx context.Context, req *pb.CreateInvoiceRequest) (*pb.Invoice, error) {
+	userID, err := uuid.Parse(req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid user_id")
+	}
+
+	result, err := s.PaymentUseCase.CreateInvoice(ctx, userID, req.GetEmail(), &model.CreateInvoiceRequest{
+		OrderID:         req.GetOrderId(),
+		Description:     req.GetDescription(),
+		Currency:        req.GetCurrency(),
+		VoucherCode:     req.GetVoucherCode(),
+		SuccessURL:      req.GetSuccessRedirectUrl(),
+		FailureURL:      req.GetFailureRedirectUrl(),
+		InvoiceDuration: req.GetInvoiceDuration(),
+		PaymentMethods:  req.GetPaymentMethods(),
+		CustomerName:    req.GetCustomerName(),
+		CustomerPhone:   req.GetCustomerPhone(),
+		Locale:          req.GetLocale(),
+	})
+	if err != nil {
+		s.Log.WithError(err).Error("Failed to create invoice via gRPC")
+		return nil, toStatusError(err)
+	}
+
+	return &pb.Invoice{
+		Id:                 result.ID,
+		OrderId:            result.OrderID,
+		XenditId:           result.XenditID,
+		InvoiceUrl:         result.InvoiceURL,
+		Amount:             result.Amount.Value,
+		Currency:           result.Amount.Currency,
+		Status:             result.Status,
+		PayerEmail:         req.GetEmail(),
+		Description:        req.GetDescription(),
+		Items:              toProtoInvoiceItems(result.Items),
+		VoucherCode:        result.VoucherCode,
+		Discount:           result.Discount.Value,
+		DiscountCurrency:   result.Discount.Currency,
+		SuccessRedirectUrl: result.SuccessURL,
+		FailureRedirectUrl: result.FailureURL,
+		ExpiresAt:          formatTime(result.ExpiresAt),
+		PaymentMethods:     result.PaymentMethods,
+		CustomerName:       result.CustomerName,
+		CustomerPhone:      result.CustomerPhone,
+		Locale:             result.Locale,
+	}, nil
+}
+
+func (s *PaymentServer) WatchInvoice(req *pb.WatchInvoiceRequest, stream pb.PaymentService_WatchInvoiceServer) error {
+	orderID, err := uuid.Parse(req.GetOrderId())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid order_id")
+	}
+
+	err = s.InvoiceStatusUseCase.Watch(stream.Context(), orderID, func(event *model.InvoiceStatusEvent) error {
+		return stream.Send(&pb.InvoiceStatusEvent{
+			InvoiceId:      event.InvoiceID,
+			OrderId:        event.OrderID,
+			Status:         event.Status,
+			PreviousStatus: event.PreviousStatus,
+			Source:         event.Source,
+			ChangedAt:      event.ChangedAt,
+		})
+	})
+	if err != nil {
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		return toStatusError(err)
+	}
+
+	return nil
+}
+
+func toProtoInvoice(invoice *model.InvoiceResponse) *pb.Invoice {
+	return &pb.Invoice{
+		Id:                 invoice.ID,
+		OrderId:            invoice.OrderID,
+		XenditId:           invoice.XenditID,
+		InvoiceUrl:         invoice.InvoiceURL,
+		Amount:             invoice.Amount.Value,
+		Currency:           invoice.Amount.Currency,
+		Status:             invoice.Status,
+		PayerEmail:         invoice.PayerEmail,
+		Description:        invoice.Description,
+		Items:              toProtoInvoiceItems(invoice.Items),
+		VoucherCode:        invoice.VoucherCode,
+		Discount:           invoice.Discount.Value,
+		DiscountCurrency:   invoice.Discount.Currency,
+		SuccessRedirectUrl: invoice.SuccessURL,
+		FailureRedirectUrl: invoice.FailureURL,
+		ExpiresAt:          formatTime(invoice.ExpiresAt),
+		PaymentMethods:     invoice.PaymentMethods,
+		CustomerName:       invoice.CustomerName,
+		CustomerPhone:      invoice.CustomerPhone,
+		Locale:             invoice.Locale,
+		VirtualAccount:     toProtoVirtualAccount(invoice.VirtualAccount),
+		QrCode:             toProtoQRCode(invoice.QRCode),
+	}
+}
+
+func toProtoQRCode(qr *model.QRCodeResponse) *pb.QRCode {
+	if qr == nil {
+		return nil
+	}
+	return &pb.QRCode{
+		Id:        qr.ID,
+		QrString:  qr.QRString,
+		Amount:    qr.Amount.Value,
+		Currency:  qr.Amount.Currency,
+		Status:    qr.Status,
+		ExpiresAt: formatTime(qr.ExpiresAt),
+		PaidAt:    formatTime(qr.PaidAt),
+	}
+}
+
+func toProtoVirtualAccount(va *model.VirtualAccountResponse) *pb.VirtualAccount {
+	if va == nil {
+		return nil
+	}
+
+	languages := make([]string, 0, len(va.Instructions))
+	for language := range va.Instructions {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+
+	instructions := make([]*pb.PaymentInstruction, 0, len(languages))
+	for _, language := range languages {
+		instructions = append(instructions, &pb.PaymentInstruction{Language: language, Steps: va.Instructions[language]})
+	}
+
+	return &pb.VirtualAccount{
+		Id:            va.ID,
+		Bank:          va.Bank,
+		AccountNumber: va.AccountNumber,
+		Amount:        va.Amount.Value,
+		Currency:      va.Amount.Currency,
+		Status:        va.Status,
+		ExpiresAt:     formatTime(va.ExpiresAt),
+		PaidAt:        formatTime(va.PaidAt),
+		Instructions:  instructions,
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func toProtoInvoiceItems(items []*model.InvoiceItemResponse) []*pb.InvoiceItem {
+	result := make([]*pb.InvoiceItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, &pb.InvoiceItem{
+			ProductId: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price.Value,
+			Subtotal:  item.Subtotal.Value,
+			Currency:  item.Price.Currency,
+		})
+	}
+	return result
+}
+
+func toStatusError(err error) error {
+	message := utils.ParseMultilangError(err)["en"]
+	if message == "" {
+		message = "internal server error"
+	}
+
+	var ve validator.ValidationErrors
+	switch {
+	case errors.Is(err, usecase.ErrInvoiceNotFound), errors.Is(err, usecase.ErrOrderNotFound), errors.Is(err, usecase.ErrVoucherNotFound):
+		return status.Error(codes.NotFound, message)
+	case errors.Is(err, usecase.ErrInvoiceAlreadyExists):
+		return status.Error(codes.AlreadyExists, message)
+	case errors.Is(err, usecase.ErrOrderNotOwned):
+		return status.Error(codes.PermissionDenied, message)
+	case errors.Is(err, usecase.ErrOrderNotPayable), errors.Is(err, usecase.ErrOrderTotalMismatch):
+		return status.Error(codes.FailedPrecondition, message)
+	case errors.Is(err, usecase.ErrUnsupportedCurrency), errors.Is(err, usecase.ErrRedirectNotAllowed),
+		errors.Is(err, usecase.ErrInvoiceDurationOutOfRange), errors.Is(err, usecase.ErrPaymentMethodNotAllowed):
+		return status.Error(codes.InvalidArgument, message)
+	case errors.Is(err, usecase.ErrExchangeRateNotFound):
+		return status.Error(codes.FailedPrecondition, message)
+	case errors.Is(err, usecase.ErrVoucherNotActive), errors.Is(err, usecase.ErrVoucherMinimumSpend), errors.Is(err, usecase.ErrVoucherNotApplicable):
+		return status.Error(codes.FailedPrecondition, message)
+	case errors.Is(err, usecase.ErrVoucherUsageLimitReached), errors.Is(err, usecase.ErrVoucherUserLimitReached):
+		return status.Error(codes.ResourceExhausted, message)
+	case errors.As(err, &ve):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, message)
+	}
+}
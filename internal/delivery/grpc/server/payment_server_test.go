@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	pb "golectro-payment/internal/delivery/grpc/proto/payment"
+	"golectro-payment/internal/usecase"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPaymentServerRejectsInvalidIDs(t *testing.T) {
+	server := NewPaymentServer(logrus.New(), nil, nil)
+
+	_, err := server.GetInvoiceByOrderID(context.Background(), &pb.GetInvoiceByOrderIdRequest{OrderId: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.ListInvoicesByUser(context.Background(), &pb.ListInvoicesByUserRequest{UserId: ""})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.CreateInvoice(context.Background(), &pb.CreateInvoiceRequest{UserId: "not-a-uuid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestToStatusError(t *testing.T) {
+	for err, code := range map[error]codes.Code{
+		usecase.ErrInvoiceNotFound:          codes.NotFound,
+		usecase.ErrOrderNotFound:            codes.NotFound,
+		usecase.ErrInvoiceAlreadyExists:     codes.AlreadyExists,
+		usecase.ErrOrderNotOwned:            codes.PermissionDenied,
+		usecase.ErrOrderNotPayable:          codes.FailedPrecondition,
+		usecase.ErrUnsupportedCurrency:      codes.InvalidArgument,
+		usecase.ErrVoucherUsageLimitReached: codes.ResourceExhausted,
+		errors.New("boom"):                  codes.Internal,
+	} {
+		assert.Equal(t, code, status.Code(toStatusError(fmt.Errorf("wrapped: %w", err))), err.Error())
+	}
+
+	assert.Equal(t, "internal server error", status.Convert(toStatusError(errors.New("boom"))).Message())
+}
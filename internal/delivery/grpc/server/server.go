@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	pb "golectro-payment/internal/delivery/grpc/proto/payment"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+type Server struct {
+	Log          *logrus.Logger
+	GRPC         *grpc.Server
+	Health       *health.Server
+	Port         int
+	StopTimeout  time.Duration
+	ServiceNames []string
+}
+
+func NewServer(log *logrus.Logger, viper *viper.Viper, paymentServer *PaymentServer) *Server {
+	port := viper.GetInt("GRPC_PORT")
+	if port == 0 {
+		port = 50053
+	}
+
+	auth := NewServiceAuth(log, viper.GetString("GRPC_SERVICE_TOKEN"))
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(auth.Unary()),
+		grpc.ChainStreamInterceptor(auth.Stream()),
+	)
+	healthServer := health.NewServer()
+
+	pb.RegisterPaymentServiceServer(grpcServer, paymentServer)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	if viper.GetBool("GRPC_REFLECTION") {
+		reflection.Register(grpcServer)
+	}
+
+	return &Server{
+		Log:          log,
+		GRPC:         grpcServer,
+		Health:       healthServer,
+		Port:         port,
+		StopTimeout:  10 * time.Second,
+		ServiceNames: []string{"", pb.PaymentService_ServiceDesc.ServiceName},
+	}
+}
+
+func (s *Server) Name() string {
+	return "grpc-server"
+}
+
+func (s *Server) Run(ctx context.Context) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.Port))
+	if err != nil {
+		s.Log.Fatalf("Failed to listen on gRPC port %d: %v", s.Port, err)
+	}
+
+	for _, name := range s.ServiceNames {
+		s.Health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.Health.Shutdown()
+
+		stopped := make(chan struct{})
+		go func() {
+			s.GRPC.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-time.After(s.StopTimeout):
+			s.Log.Warn("gRPC graceful stop timed out, forcing shutdown")
+			s.GRPC.Stop()
+		}
+	}()
+
+	s.Log.Infof("gRPC server listening on port %d", s.Port)
+	if err := s.GRPC.Serve(listener); err != nil {
+		s.Log.WithError(err).Error("gRPC server stopped with error")
+	}
+}
@@ -0,0 +1,267 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/delivery/http/middleware"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type AdminController struct {
+	Log                 *logrus.Logger
+	PaymentUseCase      *usecase.PaymentUseCase
+	AdminUseCase        *usecase.AdminUseCase
+	RefundUseCase       *usecase.RefundUseCase
+	ExchangeRateUseCase *usecase.ExchangeRateUseCase
+	VoucherUseCase      *usecase.VoucherUseCase
+	LogUseCase          *usecase.LogUseCase
+}
+
+func NewAdminController(log *logrus.Logger, paymentUseCase *usecase.PaymentUseCase, adminUseCase *usecase.AdminUseCase, refundUseCase *usecase.RefundUseCase, exchangeRateUseCase *usecase.ExchangeRateUseCase, voucherUseCase *usecase.VoucherUseCase, logUseCase *usecase.LogUseCase) *AdminController {
+	return &AdminController{
+		Log:                 log,
+		PaymentUseCase:      paymentUseCase,
+		AdminUseCase:        adminUseCase,
+		RefundUseCase:       refundUseCase,
+		ExchangeRateUseCase: exchangeRateUseCase,
+		VoucherUseCase:      voucherUseCase,
+		LogUseCase:          logUseCase,
+	}
+}
+
+func (ac *AdminController) ListInvoices(ctx *gin.Context) {
+	request := new(model.SearchInvoiceRequest)
+
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		ac.Log.WithError(err).Error("Invalid query parameters")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	invoices, paging, err := ac.PaymentUseCase.SearchInvoices(ctx, request)
+	ac.audit(ctx, "admin.invoice.list", ctx.Request.URL.RawQuery, err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessWithPaginationResponse(ctx, http.StatusOK, constants.InvoiceRetrieved, invoices, *paging)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) GetInvoice(ctx *gin.Context) {
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	invoice, err := ac.AdminUseCase.GetInvoiceDetail(ctx, invoiceID)
+	ac.audit(ctx, "admin.invoice.view", invoiceID.String(), err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.InvoiceRetrieved, invoice)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) SyncInvoice(ctx *gin.Context) {
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	invoice, err := ac.AdminUseCase.SyncInvoice(ctx, invoiceID)
+	ac.audit(ctx, "admin.invoice.sync", invoiceID.String(), err)
+	if err != nil {
+		ac.Log.WithError(err).Errorf("Failed to sync invoice %s", invoiceID)
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.InvoiceSynced, invoice)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) CreateRefund(ctx *gin.Context) {
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	request := new(model.CreateRefundRequest)
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		ac.Log.WithError(err).Error("Invalid request data")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	refund, err := ac.RefundUseCase.CreateRefundByAdmin(ctx, invoiceID, request)
+	ac.audit(ctx, "admin.invoice.refund", fmt.Sprintf("%s amount=%d reason=%s", invoiceID, request.Amount, request.Reason), err)
+	if err != nil {
+		ac.Log.WithError(err).Errorf("Failed to refund invoice %s", invoiceID)
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.FailedToCreateRefund, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusCreated, constants.RefundCreated, refund)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) ListExchangeRates(ctx *gin.Context) {
+	rates, err := ac.ExchangeRateUseCase.ListRates(ctx)
+	ac.audit(ctx, "admin.fx_rate.list", "all", err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.ExchangeRateRetrieved, rates)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) UpsertExchangeRate(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+	request := new(model.UpsertExchangeRateRequest)
+
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		ac.Log.WithError(err).Error("Invalid request data")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	rate, err := ac.ExchangeRateUseCase.UpsertRate(ctx, auth.Username, request)
+	ac.audit(ctx, "admin.fx_rate.upsert", fmt.Sprintf("%s/%s=%v", request.BaseCurrency, request.QuoteCurrency, request.Rate), err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.ExchangeRateUpdated, rate)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) DeleteExchangeRate(ctx *gin.Context) {
+	base, quote := ctx.Param("base"), ctx.Param("quote")
+
+	err := ac.ExchangeRateUseCase.DeleteRate(ctx, base, quote)
+	ac.audit(ctx, "admin.fx_rate.delete", base+"/"+quote, err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.ExchangeRateDeleted, true)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) ListVouchers(ctx *gin.Context) {
+	vouchers, err := ac.VoucherUseCase.ListVouchers(ctx)
+	ac.audit(ctx, "admin.voucher.list", "all", err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.VoucherRetrieved, vouchers)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) UpsertVoucher(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+	request := new(model.UpsertVoucherRequest)
+
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		ac.Log.WithError(err).Error("Invalid request data")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	voucher, err := ac.VoucherUseCase.UpsertVoucher(ctx, auth.Username, request)
+	ac.audit(ctx, "admin.voucher.upsert", request.Code, err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.VoucherSaved, voucher)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) DeactivateVoucher(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+	code := ctx.Param("code")
+
+	err := ac.VoucherUseCase.DeactivateVoucher(ctx, auth.Username, code)
+	ac.audit(ctx, "admin.voucher.deactivate", code, err)
+	if err != nil {
+		res := utils.FailedResponse(ctx, adminErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.VoucherDeactivated, true)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (ac *AdminController) audit(ctx *gin.Context, action, target string, err error) {
+	auth := middleware.GetUser(ctx)
+	requestID, _ := ctx.Get("requestId")
+	requestIDStr, _ := requestID.(string)
+
+	level, status, errMsg := "INFO", http.StatusOK, ""
+	if err != nil {
+		level, status, errMsg = "WARN", adminErrorStatusCode(err), err.Error()
+	}
+
+	message := fmt.Sprintf("%s by %s on %s", action, auth.Username, target)
+	if err := ac.LogUseCase.LogActivity(context.WithoutCancel(ctx), level, requestIDStr, message, auth.ID.String(), ctx.FullPath(), status, errMsg); err != nil {
+		ac.Log.WithError(err).Warn("Failed to record admin audit log")
+	}
+}
+
+func adminErrorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvoiceNotFound), errors.Is(err, usecase.ErrGatewayInvoiceNotFound), errors.Is(err, usecase.ErrExchangeRateNotFound), errors.Is(err, usecase.ErrVoucherNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrGatewayAmountMismatch), errors.Is(err, usecase.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrInvoiceNotRefundable), errors.Is(err, usecase.ErrRefundAmountExceeded):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrVoucherInvalidPercentage), errors.Is(err, usecase.ErrVoucherInvalidPeriod):
+		return http.StatusBadRequest
+	default:
+		if code := utils.GetHTTPStatusCode(err); code != http.StatusOK {
+			return code
+		}
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminErrorStatusCode(t *testing.T) {
+	validate := validator.New()
+	utils.RegisterValidations(validate)
+
+	tests := []struct {
+		name    string
+		request any
+		err     error
+		code    int
+	}{
+		{name: "invalid user filter", request: &model.SearchInvoiceRequest{UserID: "not-a-uuid"}, code: http.StatusBadRequest},
+		{name: "invoice not found", err: usecase.ErrInvoiceNotFound, code: http.StatusNotFound},
+		{name: "gateway amount mismatch", err: usecase.ErrGatewayAmountMismatch, code: http.StatusConflict},
+		{name: "unexpected error", err: errors.New("connection refused"), code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err
+			if tt.request != nil {
+				err = validate.Struct(tt.request)
+			}
+			assert.Equal(t, tt.code, adminErrorStatusCode(err))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/utils"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyKeyMaxLength   = 255
+	idempotencyDefaultTTL     = 24 * time.Hour
+	idempotencyProcessingTTL  = 2 * time.Minute
+)
+
+const (
+	idempotencyStateProcessing = "processing"
+	idempotencyStateCompleted  = "completed"
+)
+
+// Only outcomes that a retry would reproduce are replayed. A 404 is left out
+// because it may come from a dependency being briefly unreachable.
+var idempotencyCachedClientErrors = []int{
+	http.StatusBadRequest,
+	http.StatusForbidden,
+	http.StatusConflict,
+	http.StatusUnprocessableEntity,
+}
+
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+func NewIdempotency(viper *viper.Viper, redis *redis.Client) gin.HandlerFunc {
+	ttl := viper.GetDuration("IDEMPOTENCY_TTL")
+	if ttl <= 0 {
+		ttl = idempotencyDefaultTTL
+	}
+	processingTTL := viper.GetDuration("IDEMPOTENCY_PROCESSING_TTL")
+	if processingTTL <= 0 {
+		processingTTL = idempotencyProcessingTTL
+	}
+
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > idempotencyKeyMaxLength {
+			res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidIdempotencyKey, nil)
+			ctx.AbortWithStatusJSON(res.StatusCode, res)
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.FailedDataFromBody, nil)
+			ctx.AbortWithStatusJSON(res.StatusCode, res)
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := "anonymous"
+		if auth := GetUser(ctx); auth != nil {
+			scope = auth.ID.String()
+		}
+		redisKey := "idempotency:" + scope + ":" + key
+		fingerprint := idempotencyFingerprint(ctx.Request.Method, ctx.FullPath(), body)
+
+		pending, _ := json.Marshal(&idempotencyRecord{State: idempotencyStateProcessing, Fingerprint: fingerprint})
+		acquired, err := redis.SetNX(ctx, redisKey, pending, processingTTL).Result()
+		if err != nil {
+			res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, nil)
+			ctx.AbortWithStatusJSON(res.StatusCode, res)
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(ctx, redis, redisKey, fingerprint)
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = writer
+
+		// The key is released unless a replayable outcome gets stored, which
+		// also covers a handler that panics. Redis is called without the
+		// request's cancellation so a disconnecting client cannot skip it.
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		stored := false
+		defer func() {
+			if !stored {
+				redis.Del(storeCtx, redisKey)
+			}
+		}()
+
+		ctx.Next()
+
+		if !idempotencyReplayable(writer.Status()) {
+			return
+		}
+
+		completed, _ := json.Marshal(&idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			Fingerprint: fingerprint,
+			StatusCode:  writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		stored = redis.Set(storeCtx, redisKey, completed, ttl).Err() == nil
+	}
+}
+
+func idempotencyReplayable(statusCode int) bool {
+	return statusCode < http.StatusBadRequest || slices.Contains(idempotencyCachedClientErrors, statusCode)
+}
+
+func replayIdempotentResponse(ctx *gin.Context, redis *redis.Client, redisKey, fingerprint string) {
+	raw, err := redis.Get(ctx, redisKey).Bytes()
+	if err != nil {
+		res := utils.FailedResponse(ctx, http.StatusConflict, constants.IdempotencyRequestInProgress, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		res := utils.FailedResponse(ctx, http.StatusConflict, constants.IdempotencyKeyReused, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	if record.State != idempotencyStateCompleted {
+		res := utils.FailedResponse(ctx, http.StatusConflict, constants.IdempotencyRequestInProgress, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	ctx.Header(idempotencyReplayedHeader, "true")
+	ctx.Data(record.StatusCode, record.ContentType, record.Body)
+	ctx.Abort()
+}
+
+func idempotencyFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
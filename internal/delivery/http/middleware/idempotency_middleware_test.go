@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type idempotencyTest struct {
+	router *gin.Engine
+	redis  *miniredis.Miniredis
+	calls  int
+	status int
+}
+
+func newIdempotencyTest(t *testing.T) *idempotencyTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	test := &idempotencyTest{redis: server, status: http.StatusCreated}
+	test.router = gin.New()
+	test.router.POST("/invoices", NewIdempotency(viper.New(), rdb), func(ctx *gin.Context) {
+		test.calls++
+		ctx.JSON(test.status, gin.H{"call": test.calls})
+	})
+	return test
+}
+
+func (test *idempotencyTest) post(key, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/invoices", strings.NewReader(body))
+	if key != "" {
+		request.Header.Set(idempotencyKeyHeader, key)
+	}
+	recorder := httptest.NewRecorder()
+	test.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyReplaysCompletedRequests(t *testing.T) {
+	test := newIdempotencyTest(t)
+
+	first := test.post("key-1", `{"order_id":"1"}`)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(idempotencyReplayedHeader))
+
+	replayed := test.post("key-1", `{"order_id":"1"}`)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "true", replayed.Header().Get(idempotencyReplayedHeader))
+	assert.Equal(t, first.Body.String(), replayed.Body.String())
+	assert.Equal(t, 1, test.calls)
+
+	reused := test.post("key-1", `{"order_id":"2"}`)
+	assert.Equal(t, http.StatusConflict, reused.Code)
+	assert.Equal(t, 1, test.calls)
+
+	test.post("", `{"order_id":"1"}`)
+	test.post("", `{"order_id":"1"}`)
+	assert.Equal(t, 3, test.calls)
+}
+
+func TestIdempotencyReleasesKeysOfRetryableOutcomes(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			test := newIdempotencyTest(t)
+			test.status = status
+
+			assert.Equal(t, status, test.post("key-1", `{}`).Code)
+			assert.False(t, test.redis.Exists("idempotency:anonymous:key-1"))
+
+			test.status = http.StatusCreated
+			assert.Equal(t, http.StatusCreated, test.post("key-1", `{}`).Code)
+			assert.Equal(t, 2, test.calls)
+		})
+	}
+}
+
+func TestIdempotencyStoresDefinitiveRejections(t *testing.T) {
+	test := newIdempotencyTest(t)
+	test.status = http.StatusUnprocessableEntity
+
+	test.post("key-1", `{}`)
+	test.status = http.StatusCreated
+	replayed := test.post("key-1", `{}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, replayed.Code)
+	assert.Equal(t, 1, test.calls)
+
+	ttl := test.redis.TTL("idempotency:anonymous:key-1")
+	assert.Equal(t, idempotencyDefaultTTL, ttl)
+}
+
+func TestIdempotencyRejectsConcurrentRequests(t *testing.T) {
+	test := newIdempotencyTest(t)
+	rdb := redis.NewClient(&redis.Options{Addr: test.redis.Addr()})
+	defer rdb.Close()
+
+	// Another replica is still handling the first request.
+	fingerprint := idempotencyFingerprint(http.MethodPost, "/invoices", []byte(`{}`))
+	record := `{"state":"processing","fingerprint":"` + fingerprint + `"}`
+	require.NoError(t, rdb.Set(context.Background(), "idempotency:anonymous:key-1", record, time.Minute).Err())
+
+	assert.Equal(t, http.StatusConflict, test.post("key-1", `{}`).Code)
+	assert.Zero(t, test.calls)
+}
+
+func TestIdempotencyRejectsOverlongKeys(t *testing.T) {
+	test := newIdempotencyTest(t)
+
+	assert.Equal(t, http.StatusBadRequest, test.post(strings.Repeat("k", idempotencyKeyMaxLength+1), `{}`).Code)
+	assert.Zero(t, test.calls)
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"encoding/json"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/utils"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewRole must run after NewAuth; it admits users holding any of the given
+// Keycloak realm roles.
+func NewRole(allowed ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		auth := GetUser(ctx)
+		if auth == nil {
+			res := utils.FailedResponse(ctx, http.StatusUnauthorized, constants.InvalidToken, nil)
+			ctx.AbortWithStatusJSON(res.StatusCode, res)
+			return
+		}
+
+		var roles []string
+		if err := json.Unmarshal(auth.Roles, &roles); err != nil {
+			res := utils.FailedResponse(ctx, http.StatusForbidden, constants.ForbiddenAccess, nil)
+			ctx.AbortWithStatusJSON(res.StatusCode, res)
+			return
+		}
+
+		for _, role := range roles {
+			if slices.Contains(allowed, role) {
+				ctx.Next()
+				return
+			}
+		}
+
+		res := utils.FailedResponse(ctx, http.StatusForbidden, constants.ForbiddenAccess, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+	}
+}
@@ -2,38 +2,39 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"golectro-payment/internal/constants"
-	"golectro-payment/internal/delivery/grpc/client"
 	"golectro-payment/internal/delivery/http/middleware"
+	"golectro-payment/internal/entity"
 	"golectro-payment/internal/model"
 	"golectro-payment/internal/usecase"
 	"golectro-payment/internal/utils"
 	"net/http"
-	"time"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 type PaymentController struct {
-	Log            *logrus.Logger
-	PaymentUseCase *usecase.PaymentUseCase
-	OrderClient    *client.OrderClient
-	Viper          *viper.Viper
-	KafkaWriter    *kafka.Writer
+	Log                  *logrus.Logger
+	PaymentUseCase       *usecase.PaymentUseCase
+	DirectPaymentUseCase *usecase.DirectPaymentUseCase
+	WebhookUseCase       *usecase.WebhookUseCase
+	ReceiptUseCase       *usecase.ReceiptUseCase
+	Viper                *viper.Viper
 }
 
-func NewPaymentController(log *logrus.Logger, viper *viper.Viper, useCase *usecase.PaymentUseCase, kafkaWriter *kafka.Writer, orderClient *client.OrderClient) *PaymentController {
+func NewPaymentController(log *logrus.Logger, viper *viper.Viper, useCase *usecase.PaymentUseCase, directPaymentUseCase *usecase.DirectPaymentUseCase, webhookUseCase *usecase.WebhookUseCase, receiptUseCase *usecase.ReceiptUseCase) *PaymentController {
 	return &PaymentController{
-		Log:            log,
-		PaymentUseCase: useCase,
-		OrderClient:    orderClient,
-		Viper:          viper,
-		KafkaWriter:    kafkaWriter,
+		Log:                  log,
+		PaymentUseCase:       useCase,
+		DirectPaymentUseCase: directPaymentUseCase,
+		WebhookUseCase:       webhookUseCase,
+		ReceiptUseCase:       receiptUseCase,
+		Viper:                viper,
 	}
 }
 
@@ -48,118 +49,167 @@ func (pc *PaymentController) CreateInvoice(ctx *gin.Context) {
 		return
 	}
 
-	order, err := pc.OrderClient.GetOrderByID(ctx, request.OrderID)
+	result, err := pc.PaymentUseCase.CreateInvoice(ctx, auth.ID, auth.Email, request)
 	if err != nil {
-		pc.Log.WithError(err).Error("Failed to retrieve order")
-		res := utils.FailedResponse(ctx, http.StatusNotFound, constants.OrderNotFound, nil)
+		pc.Log.WithError(err).Error("Failed to create invoice")
+		res := utils.FailedResponse(ctx, invoiceErrorStatusCode(err), constants.InternalServerError, err)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	if order == nil {
-		pc.Log.Warn("Order not found")
-		res := utils.FailedResponse(ctx, http.StatusNotFound, constants.OrderNotFound, nil)
-		ctx.AbortWithStatusJSON(res.StatusCode, res)
-		return
-	}
+	res := utils.SuccessResponse(ctx, http.StatusCreated, constants.InvoiceCreated, result)
+	ctx.JSON(res.StatusCode, res)
+}
 
-	orderUUID, err := uuid.Parse(request.OrderID)
-	if err != nil {
-		pc.Log.WithError(err).Error("Invalid OrderID format")
-		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
+func (pc *PaymentController) GetInvoice(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+	request := new(model.SearchInvoiceRequest)
+
+	if err := ctx.ShouldBindQuery(request); err != nil {
+		pc.Log.WithError(err).Error("Invalid query parameters")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
+	request.UserID = auth.ID.String()
 
-	invoiceExists, err := pc.PaymentUseCase.GetInvoiceByID(ctx, orderUUID)
+	invoices, paging, err := pc.PaymentUseCase.SearchInvoices(ctx, request)
 	if err != nil {
-		pc.Log.WithError(err).Error("Failed to check if invoice exists")
-		res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, err)
+		pc.Log.WithError(err).Error("Failed to retrieve invoice")
+		res := utils.FailedResponse(ctx, invoiceErrorStatusCode(err), constants.InternalServerError, err)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	if invoiceExists != nil {
-		pc.Log.Warn("Invoice already exists for this order")
-		res := utils.FailedResponse(ctx, http.StatusConflict, constants.InvoiceAlreadyExists, nil)
+	res := utils.SuccessWithPaginationResponse(ctx, http.StatusOK, constants.InvoiceRetrieved, invoices, *paging)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (pc *PaymentController) GetReceipt(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		pc.Log.WithError(err).Error("Invalid invoice ID format")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	result, err := pc.PaymentUseCase.CreateInvoice(ctx, auth.ID, auth.Email, request, order.TotalAmount)
+	document, err := pc.ReceiptUseCase.GetReceipt(ctx, auth.ID, invoiceID)
 	if err != nil {
-		pc.Log.WithError(err).Error("Failed to create invoice")
-		res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, err)
+		pc.Log.WithError(err).Error("Failed to generate receipt")
+		res := utils.FailedResponse(ctx, invoiceErrorStatusCode(err), constants.InternalServerError, err)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	res := utils.SuccessResponse(ctx, http.StatusCreated, constants.InvoiceCreated, result)
-	ctx.JSON(res.StatusCode, res)
+	ctx.Header("Content-Disposition", fmt.Sprintf(`inline; filename="receipt-%s.pdf"`, invoiceID))
+	ctx.Header("Cache-Control", "private, max-age=300")
+	ctx.Data(http.StatusOK, "application/pdf", document)
 }
 
-func (pc *PaymentController) GetInvoice(ctx *gin.Context) {
+func (pc *PaymentController) XenditCallback(ctx *gin.Context) {
+	pc.handleXenditCallback(ctx, usecase.WebhookEventTypeInvoice, pc.WebhookUseCase.HandleInvoiceEvent)
+}
+
+func (pc *PaymentController) handleXenditCallback(ctx *gin.Context, eventType string, handle func(context.Context, *entity.WebhookEvent) (*model.InvoiceResponse, error)) {
+	handleXenditCallback(ctx, pc.Log, pc.Viper, pc.WebhookUseCase, eventType, handle, callbackErrorStatusCode, constants.InvoiceRetrieved)
+}
+
+func (pc *PaymentController) CreateVirtualAccount(ctx *gin.Context) {
 	auth := middleware.GetUser(ctx)
 
-	invoice, err := pc.PaymentUseCase.GetInvoiceByUserID(ctx, auth.ID)
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
 	if err != nil {
-		pc.Log.WithError(err).Error("Failed to retrieve invoice")
-		res := utils.FailedResponse(ctx, http.StatusNotFound, constants.InternalServerError, err)
+		pc.Log.WithError(err).Error("Invalid invoice ID format")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	if invoice == nil {
-		res := utils.FailedResponse(ctx, http.StatusNotFound, constants.InvoiceNotFound, nil)
+	request := new(model.CreateVirtualAccountRequest)
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		pc.Log.WithError(err).Error("Invalid request data")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	res := utils.SuccessResponse(ctx, http.StatusOK, constants.InvoiceRetrieved, invoice)
+	result, err := pc.DirectPaymentUseCase.CreateVirtualAccount(ctx, auth.ID, invoiceID, request)
+	if err != nil {
+		pc.Log.WithError(err).Error("Failed to create virtual account")
+		res := utils.FailedResponse(ctx, invoiceErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusCreated, constants.VirtualAccountCreated, result)
 	ctx.JSON(res.StatusCode, res)
 }
 
-func (pc *PaymentController) XenditCallback(ctx *gin.Context) {
-	token := ctx.GetHeader("x-callback-token")
+func (pc *PaymentController) XenditVirtualAccountCallback(ctx *gin.Context) {
+	pc.handleXenditCallback(ctx, usecase.WebhookEventTypeVirtualAccount, pc.WebhookUseCase.HandleVirtualAccountEvent)
+}
+
+func (pc *PaymentController) CreateQRCode(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
 
-	if token != pc.Viper.GetString("XENDIT_TOKEN") {
-		pc.Log.Error("Invalid Xendit callback token")
-		res := utils.FailedResponse(ctx, http.StatusUnauthorized, constants.UnauthorizedAccess, nil)
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		pc.Log.WithError(err).Error("Invalid invoice ID format")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	request := new(model.XenditCallbackData)
-	if err := ctx.ShouldBindJSON(request); err != nil {
-		pc.Log.WithError(err).Error("Failed to bind Xendit callback data")
-		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
+	result, err := pc.DirectPaymentUseCase.CreateQRCode(ctx, auth.ID, invoiceID)
+	if err != nil {
+		pc.Log.WithError(err).Error("Failed to create QR code")
+		res := utils.FailedResponse(ctx, invoiceErrorStatusCode(err), constants.InternalServerError, err)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	invoice, err := pc.PaymentUseCase.HandleXenditCallback(ctx, request)
+	res := utils.SuccessResponse(ctx, http.StatusCreated, constants.QRCodeCreated, result)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (pc *PaymentController) GetQRCodeImage(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
 	if err != nil {
-		pc.Log.WithError(err).Error("Failed to handle Xendit callback")
-		res := utils.FailedResponse(ctx, http.StatusInternalServerError, constants.InternalServerError, err)
+		pc.Log.WithError(err).Error("Invalid invoice ID format")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
 		ctx.AbortWithStatusJSON(res.StatusCode, res)
 		return
 	}
 
-	value, _ := json.Marshal(invoice)
-
-	message := kafka.Message{
-		Value: value,
+	size := 0
+	if value := ctx.Query("size"); value != "" {
+		if size, err = strconv.Atoi(value); err != nil {
+			pc.Log.WithError(err).Error("Invalid QR code size")
+			res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+			ctx.AbortWithStatusJSON(res.StatusCode, res)
+			return
+		}
 	}
 
-	ctxKafka, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := pc.KafkaWriter.WriteMessages(ctxKafka, message); err != nil {
-		pc.Log.WithError(err).Error("Failed to publish Kafka message")
+	image, err := pc.DirectPaymentUseCase.RenderQRCode(ctx, auth.ID, invoiceID, size)
+	if err != nil {
+		pc.Log.WithError(err).Error("Failed to render QR code")
+		res := utils.FailedResponse(ctx, invoiceErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
 	}
 
-	res := utils.SuccessResponse(ctx, http.StatusOK, constants.InvoiceRetrieved, invoice)
-	ctx.JSON(res.StatusCode, res)
+	ctx.Header("Cache-Control", "private, no-cache")
+	ctx.Data(http.StatusOK, "image/png", image)
+}
+
+func (pc *PaymentController) XenditQRCodeCallback(ctx *gin.Context) {
+	pc.handleXenditCallback(ctx, usecase.WebhookEventTypeQRCode, pc.WebhookUseCase.HandleQRCodeEvent)
 }
 
 func (pc *PaymentController) DeleteInvoice(ctx *gin.Context) {
@@ -197,3 +247,53 @@ func (pc *PaymentController) DeleteInvoice(ctx *gin.Context) {
 	res := utils.SuccessResponse(ctx, http.StatusOK, constants.InvoiceDeleted, true)
 	ctx.JSON(res.StatusCode, res)
 }
+
+func callbackErrorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvoiceNotFound), errors.Is(err, usecase.ErrPaymentAttemptNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidWebhookPayload):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrCallbackCurrencyMismatch):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func invoiceErrorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrOrderNotFound), errors.Is(err, usecase.ErrInvoiceNotFound), errors.Is(err, usecase.ErrVoucherNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrPaymentAttemptNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrOrderNotOwned):
+		return http.StatusForbidden
+	case errors.Is(err, usecase.ErrInvoiceAlreadyExists), errors.Is(err, usecase.ErrOrderNotPayable), errors.Is(err, usecase.ErrInvoiceNotPayable):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrPaymentAttemptInProgress):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrOrderTotalMismatch):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrUnsupportedCurrency), errors.Is(err, usecase.ErrRedirectNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrInvoiceDurationOutOfRange), errors.Is(err, usecase.ErrPaymentMethodNotAllowed):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrVirtualAccountBankNotSupported), errors.Is(err, usecase.ErrVirtualAccountCurrencyNotSupported):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrQRCodeCurrencyNotSupported):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrExchangeRateNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrVoucherNotActive), errors.Is(err, usecase.ErrVoucherMinimumSpend), errors.Is(err, usecase.ErrVoucherNotApplicable):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrVoucherUsageLimitReached), errors.Is(err, usecase.ErrVoucherUserLimitReached):
+		return http.StatusConflict
+	case utils.GetHTTPStatusCode(err) == http.StatusBadRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceErrorStatusCode(t *testing.T) {
+	validate := validator.New()
+	utils.RegisterValidations(validate)
+
+	tests := []struct {
+		name    string
+		request any
+		err     error
+		code    int
+	}{
+		{name: "invalid search status", request: &model.SearchInvoiceRequest{Status: "UNKNOWN"}, code: http.StatusBadRequest},
+		{name: "invalid page size", request: &model.SearchInvoiceRequest{PageSize: 1000}, code: http.StatusBadRequest},
+		{name: "invalid customer phone", request: newCreateInvoiceRequest(func(r *model.CreateInvoiceRequest) { r.CustomerPhone = "0812345678" }), code: http.StatusBadRequest},
+		{name: "unsupported locale", request: newCreateInvoiceRequest(func(r *model.CreateInvoiceRequest) { r.Locale = "fr" }), code: http.StatusBadRequest},
+		{name: "empty payment method", request: newCreateInvoiceRequest(func(r *model.CreateInvoiceRequest) { r.PaymentMethods = []string{""} }), code: http.StatusBadRequest},
+		{name: "malformed redirect URL", request: newCreateInvoiceRequest(func(r *model.CreateInvoiceRequest) { r.SuccessURL = "shop/paid" }), code: http.StatusBadRequest},
+		{name: "unknown currency", request: newCreateInvoiceRequest(func(r *model.CreateInvoiceRequest) { r.Currency = "XYZ" }), code: http.StatusBadRequest},
+		{name: "duration out of range", err: usecase.ErrInvoiceDurationOutOfRange, code: http.StatusBadRequest},
+		{name: "invoice not found", err: usecase.ErrInvoiceNotFound, code: http.StatusNotFound},
+		{name: "unexpected error", err: errors.New("connection refused"), code: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err
+			if tt.request != nil {
+				err = validate.Struct(tt.request)
+			}
+			assert.Equal(t, tt.code, invoiceErrorStatusCode(err))
+		})
+	}
+}
+
+func newCreateInvoiceRequest(modify func(*model.CreateInvoiceRequest)) *model.CreateInvoiceRequest {
+	request := &model.CreateInvoiceRequest{
+		OrderID:     "0b6f1d2c-5a4e-4f1b-9c7d-2e8a3f6b1c90",
+		Description: "Golectro order",
+	}
+	modify(request)
+	return request
+}
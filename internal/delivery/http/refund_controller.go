@@ -0,0 +1,104 @@
+package http
+
+import (
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/delivery/http/middleware"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type RefundController struct {
+	Log            *logrus.Logger
+	RefundUseCase  *usecase.RefundUseCase
+	WebhookUseCase *usecase.WebhookUseCase
+	Viper          *viper.Viper
+}
+
+func NewRefundController(log *logrus.Logger, viper *viper.Viper, useCase *usecase.RefundUseCase, webhookUseCase *usecase.WebhookUseCase) *RefundController {
+	return &RefundController{
+		Log:            log,
+		RefundUseCase:  useCase,
+		WebhookUseCase: webhookUseCase,
+		Viper:          viper,
+	}
+}
+
+func (rc *RefundController) CreateRefund(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		rc.Log.WithError(err).Error("Invalid invoice ID format")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	request := new(model.CreateRefundRequest)
+	if err := ctx.ShouldBindJSON(request); err != nil {
+		rc.Log.WithError(err).Error("Invalid request data")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	result, err := rc.RefundUseCase.CreateRefund(ctx, auth.ID, invoiceID, request)
+	if err != nil {
+		rc.Log.WithError(err).Error("Failed to create refund")
+		res := utils.FailedResponse(ctx, refundErrorStatusCode(err), constants.FailedToCreateRefund, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusCreated, constants.RefundCreated, result)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (rc *RefundController) GetRefunds(ctx *gin.Context) {
+	auth := middleware.GetUser(ctx)
+
+	invoiceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		rc.Log.WithError(err).Error("Invalid invoice ID format")
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.InvalidRequestData, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	refunds, err := rc.RefundUseCase.GetRefundsByInvoiceID(ctx, auth.ID, invoiceID)
+	if err != nil {
+		rc.Log.WithError(err).Error("Failed to retrieve refunds")
+		res := utils.FailedResponse(ctx, refundErrorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, constants.RefundRetrieved, refunds)
+	ctx.JSON(res.StatusCode, res)
+}
+
+func (rc *RefundController) XenditRefundCallback(ctx *gin.Context) {
+	handleXenditCallback(ctx, rc.Log, rc.Viper, rc.WebhookUseCase, usecase.WebhookEventTypeRefund, rc.WebhookUseCase.HandleRefundEvent, refundErrorStatusCode, constants.RefundUpdated)
+}
+
+func refundErrorStatusCode(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvoiceNotFound), errors.Is(err, usecase.ErrRefundNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvoiceNotRefundable), errors.Is(err, usecase.ErrOrderNotRefundable), errors.Is(err, usecase.ErrRefundAmountExceeded):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrInvalidStatusTransition):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrInvalidWebhookPayload):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,22 @@
+package route
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func (c *RouteConfig) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	admin := rg.Group("admin/payment", c.AuthMiddleware, c.AdminMiddleware)
+
+	admin.GET("/invoice", c.AdminController.ListInvoices)
+	admin.GET("/invoice/:id", c.AdminController.GetInvoice)
+	admin.POST("/invoice/:id/sync", c.AdminController.SyncInvoice)
+	admin.POST("/invoice/:id/refund", c.AdminController.CreateRefund)
+
+	admin.GET("/fx-rate", c.AdminController.ListExchangeRates)
+	admin.PUT("/fx-rate", c.AdminController.UpsertExchangeRate)
+	admin.DELETE("/fx-rate/:base/:quote", c.AdminController.DeleteExchangeRate)
+
+	admin.GET("/voucher", c.AdminController.ListVouchers)
+	admin.PUT("/voucher", c.AdminController.UpsertVoucher)
+	admin.DELETE("/voucher/:code", c.AdminController.DeactivateVoucher)
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func (c *RouteConfig) RegisterCommonRoutes(app *gin.Engine) {
@@ -16,6 +17,7 @@ func (c *RouteConfig) RegisterCommonRoutes(app *gin.Engine) {
 
 	app.GET("/", welcomeHandler)
 	app.GET("/api", welcomeHandler)
+	app.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	app.NoRoute(func(ctx *gin.Context) {
 		res := utils.FailedResponse(ctx, http.StatusNotFound, constants.NotFound, nil)
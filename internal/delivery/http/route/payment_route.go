@@ -7,8 +7,17 @@ import (
 func (c *RouteConfig) RegisterPaymentRoutes(rg *gin.RouterGroup) {
 	payment := rg.Group("payment")
 
-	payment.POST("/invoice", c.AuthMiddleware, c.PaymentController.CreateInvoice)
+	payment.POST("/invoice", c.AuthMiddleware, c.IdempotencyMiddleware, c.PaymentController.CreateInvoice)
 	payment.GET("/invoice", c.AuthMiddleware, c.PaymentController.GetInvoice)
 	payment.POST("/xendit/callback", c.PaymentController.XenditCallback)
 	payment.DELETE("/invoice/:id", c.AuthMiddleware, c.PaymentController.DeleteInvoice)
+	payment.GET("/invoice/:id/receipt.pdf", c.AuthMiddleware, c.PaymentController.GetReceipt)
+	payment.POST("/invoice/:id/refund", c.AuthMiddleware, c.RefundController.CreateRefund)
+	payment.GET("/invoice/:id/refunds", c.AuthMiddleware, c.RefundController.GetRefunds)
+	payment.POST("/xendit/refund/callback", c.RefundController.XenditRefundCallback)
+	payment.POST("/invoice/:id/virtual-account", c.AuthMiddleware, c.PaymentController.CreateVirtualAccount)
+	payment.POST("/xendit/virtual-account/callback", c.PaymentController.XenditVirtualAccountCallback)
+	payment.POST("/invoice/:id/qris", c.AuthMiddleware, c.PaymentController.CreateQRCode)
+	payment.GET("/invoice/:id/qris.png", c.AuthMiddleware, c.PaymentController.GetQRCodeImage)
+	payment.POST("/xendit/qr-code/callback", c.PaymentController.XenditQRCodeCallback)
 }
@@ -8,17 +8,22 @@ import (
 )
 
 type RouteConfig struct {
-	App               *gin.Engine
-	AuthMiddleware    gin.HandlerFunc
-	Viper             *viper.Viper
-	SwaggerController *http.SwaggerController
-	PaymentController *http.PaymentController
+	App                   *gin.Engine
+	AuthMiddleware        gin.HandlerFunc
+	IdempotencyMiddleware gin.HandlerFunc
+	AdminMiddleware       gin.HandlerFunc
+	Viper                 *viper.Viper
+	SwaggerController     *http.SwaggerController
+	PaymentController     *http.PaymentController
+	RefundController      *http.RefundController
+	AdminController       *http.AdminController
 }
 
 func (c *RouteConfig) Setup() {
 	api := c.App.Group("/api/v1")
 
 	c.RegisterPaymentRoutes(api)
+	c.RegisterAdminRoutes(api)
 	c.RegisterSwaggerRoutes(c.App)
 	c.RegisterCommonRoutes(c.App)
 }
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/usecase"
+	"golectro-payment/internal/utils"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// handleXenditCallback authenticates and stores a Xendit callback before
+// handing it to the use case, acknowledging redeliveries of events that were
+// already processed without handling them again. Failures are answered with
+// the status errorStatusCode picks, and the result with message.
+func handleXenditCallback[T any](ctx *gin.Context, log *logrus.Logger, viper *viper.Viper, webhookUseCase *usecase.WebhookUseCase, eventType string, handle func(context.Context, *entity.WebhookEvent) (T, error), errorStatusCode func(error) int, message model.Message) {
+	name := fmt.Sprintf("Xendit %s callback", strings.ReplaceAll(eventType, "_", " "))
+	token := ctx.GetHeader("x-callback-token")
+
+	if token != viper.GetString("XENDIT_TOKEN") {
+		log.Error("Invalid Xendit callback token")
+		res := utils.FailedResponse(ctx, http.StatusUnauthorized, constants.UnauthorizedAccess, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	body, err := ctx.GetRawData()
+	if err != nil {
+		log.WithError(err).Errorf("Failed to read %s body", name)
+		res := utils.FailedResponse(ctx, http.StatusBadRequest, constants.FailedDataFromBody, nil)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	event, duplicate, err := webhookUseCase.Receive(ctx, eventType, ctx.Request.Header, body)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to store %s", name)
+		res := utils.FailedResponse(ctx, errorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	if duplicate {
+		log.Infof("Skipping already processed %s %s", name, event.EventID)
+		res := utils.SuccessResponse(ctx, http.StatusOK, constants.WebhookAlreadyProcessed, event.EventID)
+		ctx.JSON(res.StatusCode, res)
+		return
+	}
+
+	result, err := handle(ctx, event)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to handle %s", name)
+		res := utils.FailedResponse(ctx, errorStatusCode(err), constants.InternalServerError, err)
+		ctx.AbortWithStatusJSON(res.StatusCode, res)
+		return
+	}
+
+	res := utils.SuccessResponse(ctx, http.StatusOK, message, result)
+	ctx.JSON(res.StatusCode, res)
+}
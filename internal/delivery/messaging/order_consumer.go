@@ -0,0 +1,144 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/usecase"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type OrderConsumer struct {
+	Log               *logrus.Logger
+	Reader            *kafka.Reader
+	DLQWriter         *kafka.Writer
+	OrderEventUseCase *usecase.OrderEventUseCase
+	MaxRetries        int
+	RetryBackoff      time.Duration
+}
+
+func NewOrderConsumer(log *logrus.Logger, viper *viper.Viper, reader *kafka.Reader, dlqWriter *kafka.Writer, orderEventUseCase *usecase.OrderEventUseCase) *OrderConsumer {
+	consumer := &OrderConsumer{
+		Log:               log,
+		Reader:            reader,
+		DLQWriter:         dlqWriter,
+		OrderEventUseCase: orderEventUseCase,
+		MaxRetries:        viper.GetInt("KAFKA_CONSUMER_MAX_RETRIES"),
+		RetryBackoff:      viper.GetDuration("KAFKA_CONSUMER_RETRY_BACKOFF"),
+	}
+
+	if consumer.MaxRetries <= 0 {
+		consumer.MaxRetries = 3
+	}
+	if consumer.RetryBackoff <= 0 {
+		consumer.RetryBackoff = time.Second
+	}
+
+	return consumer
+}
+
+func (c *OrderConsumer) Name() string {
+	return "order-consumer"
+}
+
+func (c *OrderConsumer) Run(ctx context.Context) {
+	c.Log.Infof("Order consumer started on topic %s", c.Reader.Config().Topic)
+
+	for {
+		message, err := c.Reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.Log.Info("Order consumer stopped")
+				return
+			}
+			c.Log.WithError(err).Error("Failed to fetch order event")
+			if !c.wait(ctx, c.RetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		// Offsets are only committed once the message is handled or parked
+		// in the dead-letter topic; on shutdown it is redelivered instead.
+		if !c.process(ctx, message) {
+			c.Log.Info("Order consumer stopped")
+			return
+		}
+
+		if err := c.Reader.CommitMessages(ctx, message); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.Log.WithError(err).Errorf("Failed to commit order event offset %d", message.Offset)
+		}
+	}
+}
+
+func (c *OrderConsumer) process(ctx context.Context, message kafka.Message) bool {
+	var err error
+	for attempt := 1; attempt <= c.MaxRetries; attempt++ {
+		err = c.OrderEventUseCase.HandleOrderEvent(ctx, message.Value)
+		if err == nil {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		if errors.Is(err, usecase.ErrInvalidOrderEvent) {
+			break
+		}
+
+		c.Log.WithError(err).Warnf("Failed to handle order event at offset %d (attempt %d/%d)", message.Offset, attempt, c.MaxRetries)
+		if attempt < c.MaxRetries && !c.wait(ctx, c.RetryBackoff*time.Duration(attempt)) {
+			return false
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		dlqErr := c.sendToDLQ(ctx, message, err)
+		if dlqErr == nil {
+			c.Log.WithError(err).Errorf("Moved order event at offset %d to dead-letter topic", message.Offset)
+			return true
+		}
+
+		c.Log.WithError(dlqErr).Error("Failed to publish order event to dead-letter topic")
+		if !c.wait(ctx, min(c.RetryBackoff*time.Duration(attempt), time.Minute)) {
+			return false
+		}
+	}
+}
+
+func (c *OrderConsumer) sendToDLQ(ctx context.Context, message kafka.Message, cause error) error {
+	headers := append([]kafka.Header{}, message.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: "dlq-error", Value: []byte(cause.Error())},
+		kafka.Header{Key: "dlq-original-topic", Value: []byte(message.Topic)},
+		kafka.Header{Key: "dlq-original-partition", Value: []byte(strconv.Itoa(message.Partition))},
+		kafka.Header{Key: "dlq-original-offset", Value: []byte(strconv.FormatInt(message.Offset, 10))},
+	)
+
+	ctxKafka, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return c.DLQWriter.WriteMessages(ctxKafka, kafka.Message{
+		Key:     message.Key,
+		Value:   message.Value,
+		Headers: headers,
+	})
+}
+
+func (c *OrderConsumer) wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ExchangeRate struct {
+	ID            uuid.UUID `gorm:"type:char(36);primaryKey" json:"id"`
+	BaseCurrency  string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair" json:"base_currency"`
+	QuoteCurrency string    `gorm:"size:3;not null;uniqueIndex:idx_exchange_rates_pair" json:"quote_currency"`
+	Rate          float64   `gorm:"type:decimal(24,10);not null" json:"rate"`
+	UpdatedBy     string    `gorm:"size:255" json:"updated_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
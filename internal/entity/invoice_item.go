@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceItem struct {
+	ID          uuid.UUID   `gorm:"type:char(36);primaryKey" json:"id"`
+	InvoiceID   uuid.UUID   `gorm:"type:char(36);index;not null" json:"invoice_id"`
+	OrderItemID string      `gorm:"size:36" json:"order_item_id"`
+	ProductID   string      `gorm:"size:36;not null" json:"product_id"`
+	Quantity    int32       `gorm:"not null" json:"quantity"`
+	Price       money.Money `gorm:"embedded;embeddedPrefix:price_" json:"price"`
+	Position    int         `gorm:"not null" json:"position"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+func (InvoiceItem) TableName() string {
+	return "invoice_items"
+}
+
+func (i *InvoiceItem) Subtotal() money.Money {
+	return money.New(i.Price.Value*int64(i.Quantity), i.Price.Currency)
+}
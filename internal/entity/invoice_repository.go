@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"golectro-payment/internal/money"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,22 +11,49 @@ import (
 type InvoiceStatus string
 
 type Invoice struct {
-	ID             uuid.UUID      `gorm:"type:char(36);primaryKey" json:"id"`
-	OrderID        uuid.UUID      `gorm:"type:char(36);index" json:"order_id"`
-	UserID         uuid.UUID      `gorm:"type:char(36);index" json:"user_id"`
-	XenditID       string         `gorm:"index" json:"xendit_id"`
-	Amount         float64        `gorm:"not null" json:"amount"`
-	PaymentMethod  string         `gorm:"size:255" json:"payment_method"`
-	PaymentChannel string         `gorm:"size:255" json:"payment_channel"`
-	PayerEmail     string         `gorm:"size:255" json:"payer_email"`
-	Description    string         `gorm:"size:500" json:"description"`
-	InvoiceURL     string         `gorm:"size:1000" json:"invoice_url"`
-	Status         string         `gorm:"size:50;index" json:"status"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uuid.UUID        `gorm:"type:char(36);primaryKey" json:"id"`
+	OrderID        uuid.UUID        `gorm:"type:char(36);index" json:"order_id"`
+	OpenOrderID    *uuid.UUID       `gorm:"type:char(36);uniqueIndex" json:"-"`
+	UserID         uuid.UUID        `gorm:"type:char(36);index" json:"user_id"`
+	XenditID       string           `gorm:"index" json:"xendit_id"`
+	Amount         money.Money      `gorm:"embedded" json:"amount"`
+	OrderAmount    money.Money      `gorm:"embedded;embeddedPrefix:order_" json:"order_amount"`
+	PaidAmount     money.Money      `gorm:"embedded;embeddedPrefix:paid_" json:"paid_amount"`
+	ExchangeRate   float64          `gorm:"type:decimal(24,10);not null;default:1" json:"exchange_rate"`
+	ExchangeRateAt *time.Time       `json:"exchange_rate_at"`
+	VoucherCode    string           `gorm:"size:50" json:"voucher_code"`
+	Discount       money.Money      `gorm:"embedded;embeddedPrefix:discount_" json:"discount"`
+	PaymentMethod  string           `gorm:"size:255" json:"payment_method"`
+	PaymentChannel string           `gorm:"size:255" json:"payment_channel"`
+	PayerEmail     string           `gorm:"size:255" json:"payer_email"`
+	CustomerName   string           `gorm:"size:255" json:"customer_name"`
+	CustomerPhone  string           `gorm:"size:20" json:"customer_phone"`
+	Locale         string           `gorm:"size:5" json:"locale"`
+	AllowedMethods []string         `gorm:"column:allowed_payment_methods;type:text;serializer:json" json:"allowed_payment_methods"`
+	Duration       int64            `gorm:"column:invoice_duration;not null;default:0" json:"invoice_duration"`
+	ExpiresAt      *time.Time       `json:"expires_at"`
+	Description    string           `gorm:"size:500" json:"description"`
+	InvoiceURL     string           `gorm:"size:1000" json:"invoice_url"`
+	SuccessURL     string           `gorm:"column:success_redirect_url;size:1000" json:"success_redirect_url"`
+	FailureURL     string           `gorm:"column:failure_redirect_url;size:1000" json:"failure_redirect_url"`
+	Status         InvoiceStatus    `gorm:"size:50;index" json:"status"`
+	Items          []InvoiceItem    `gorm:"foreignKey:InvoiceID" json:"items,omitempty"`
+	Attempts       []PaymentAttempt `gorm:"foreignKey:InvoiceID" json:"attempts,omitempty"`
+	PaidAt         *time.Time       `json:"paid_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt   `gorm:"index" json:"-"`
 }
 
 func (Invoice) TableName() string {
 	return "invoices"
 }
+
+// CollectedAmount is what the payer actually paid, falling back to the billed
+// amount for invoices paid before paid amounts were recorded.
+func (i *Invoice) CollectedAmount() money.Money {
+	if i.PaidAmount.IsZero() {
+		return i.Amount
+	}
+	return i.PaidAmount
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+const (
+	InvoiceStatusPending           InvoiceStatus = "PENDING"
+	InvoiceStatusPaid              InvoiceStatus = "PAID"
+	InvoiceStatusUnderpaid         InvoiceStatus = "UNDERPAID"
+	InvoiceStatusSettled           InvoiceStatus = "SETTLED"
+	InvoiceStatusExpired           InvoiceStatus = "EXPIRED"
+	InvoiceStatusFailed            InvoiceStatus = "FAILED"
+	InvoiceStatusPartiallyRefunded InvoiceStatus = "PARTIALLY_REFUNDED"
+	InvoiceStatusRefunded          InvoiceStatus = "REFUNDED"
+)
+
+var ErrInvalidStatusTransition = errors.New("invalid invoice status transition")
+
+var invoiceStatusTransitions = map[InvoiceStatus][]InvoiceStatus{
+	InvoiceStatusPending:           {InvoiceStatusPaid, InvoiceStatusUnderpaid, InvoiceStatusSettled, InvoiceStatusExpired, InvoiceStatusFailed},
+	InvoiceStatusUnderpaid:         {InvoiceStatusPaid, InvoiceStatusSettled, InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+	InvoiceStatusPaid:              {InvoiceStatusSettled, InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+	InvoiceStatusSettled:           {InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+	InvoiceStatusPartiallyRefunded: {InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+}
+
+func (s InvoiceStatus) CanTransitionTo(next InvoiceStatus) bool {
+	return slices.Contains(invoiceStatusTransitions[s], next)
+}
+
+func (s InvoiceStatus) IsTerminal() bool {
+	return len(invoiceStatusTransitions[s]) == 0
+}
+
+func (s InvoiceStatus) IsPaid() bool {
+	return s == InvoiceStatusPaid || s == InvoiceStatusSettled
+}
+
+// IsVoid reports whether the invoice ended unpaid, which frees its order to be
+// invoiced again.
+func (s InvoiceStatus) IsVoid() bool {
+	return s == InvoiceStatusExpired || s == InvoiceStatusFailed
+}
+
+func (i *Invoice) TransitionTo(next InvoiceStatus) error {
+	if !i.Status.CanTransitionTo(next) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, i.Status, next)
+	}
+	i.Status = next
+	return nil
+}
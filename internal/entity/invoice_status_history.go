@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceStatusHistory struct {
+	ID         uuid.UUID     `gorm:"type:char(36);primaryKey" json:"id"`
+	InvoiceID  uuid.UUID     `gorm:"type:char(36);index;not null" json:"invoice_id"`
+	FromStatus InvoiceStatus `gorm:"size:50" json:"from_status"`
+	ToStatus   InvoiceStatus `gorm:"size:50;not null" json:"to_status"`
+	Source     string        `gorm:"size:100" json:"source"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+func (InvoiceStatusHistory) TableName() string {
+	return "invoice_status_histories"
+}
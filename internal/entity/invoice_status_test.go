@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvoiceStatusTransitions(t *testing.T) {
+	statuses := []InvoiceStatus{
+		InvoiceStatusPending,
+		InvoiceStatusUnderpaid,
+		InvoiceStatusPaid,
+		InvoiceStatusSettled,
+		InvoiceStatusExpired,
+		InvoiceStatusFailed,
+		InvoiceStatusPartiallyRefunded,
+		InvoiceStatusRefunded,
+	}
+
+	allowed := map[InvoiceStatus][]InvoiceStatus{
+		InvoiceStatusPending:           {InvoiceStatusPaid, InvoiceStatusUnderpaid, InvoiceStatusSettled, InvoiceStatusExpired, InvoiceStatusFailed},
+		InvoiceStatusUnderpaid:         {InvoiceStatusPaid, InvoiceStatusSettled, InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+		InvoiceStatusPaid:              {InvoiceStatusSettled, InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+		InvoiceStatusSettled:           {InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+		InvoiceStatusPartiallyRefunded: {InvoiceStatusPartiallyRefunded, InvoiceStatusRefunded},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			expected := slices.Contains(allowed[from], to)
+
+			invoice := &Invoice{Status: from}
+			err := invoice.TransitionTo(to)
+			if expected {
+				assert.NoError(t, err, "%s -> %s", from, to)
+				assert.Equal(t, to, invoice.Status)
+			} else {
+				assert.ErrorIs(t, err, ErrInvalidStatusTransition, "%s -> %s", from, to)
+				assert.Equal(t, from, invoice.Status)
+			}
+		}
+	}
+}
+
+func TestInvoiceStatusTerminal(t *testing.T) {
+	assert.True(t, InvoiceStatusExpired.IsTerminal())
+	assert.True(t, InvoiceStatusFailed.IsTerminal())
+	assert.True(t, InvoiceStatusRefunded.IsTerminal())
+	assert.False(t, InvoiceStatusPending.IsTerminal())
+	assert.False(t, InvoiceStatusPartiallyRefunded.IsTerminal())
+}
+
+func TestInvoiceStatusIsPaid(t *testing.T) {
+	assert.True(t, InvoiceStatusPaid.IsPaid())
+	assert.True(t, InvoiceStatusSettled.IsPaid())
+	assert.False(t, InvoiceStatusPending.IsPaid())
+	assert.False(t, InvoiceStatusUnderpaid.IsPaid())
+}
+
+func TestInvoiceStatusIsVoid(t *testing.T) {
+	assert.True(t, InvoiceStatusExpired.IsVoid())
+	assert.True(t, InvoiceStatusFailed.IsVoid())
+	assert.False(t, InvoiceStatusRefunded.IsVoid())
+	assert.False(t, InvoiceStatusPending.IsVoid())
+}
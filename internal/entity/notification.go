@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationStatus string
+
+const (
+	NotificationStatusPending NotificationStatus = "PENDING"
+	NotificationStatusSending NotificationStatus = "SENDING"
+	NotificationStatusSent    NotificationStatus = "SENT"
+	NotificationStatusFailed  NotificationStatus = "FAILED"
+	NotificationStatusSkipped NotificationStatus = "SKIPPED"
+)
+
+type Notification struct {
+	ID            uuid.UUID          `gorm:"type:char(36);primaryKey" json:"id"`
+	InvoiceID     uuid.UUID          `gorm:"type:char(36);not null;uniqueIndex:idx_notifications_invoice_template" json:"invoice_id"`
+	Template      string             `gorm:"size:50;not null;uniqueIndex:idx_notifications_invoice_template" json:"template"`
+	Recipient     string             `gorm:"size:255;not null" json:"recipient"`
+	Status        NotificationStatus `gorm:"size:20;not null;index:idx_notifications_status_next_attempt,priority:1" json:"status"`
+	Attempts      int                `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time          `gorm:"index:idx_notifications_status_next_attempt,priority:2" json:"next_attempt_at"`
+	LastError     string             `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time         `json:"sent_at"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
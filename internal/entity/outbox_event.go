@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+type OutboxEventStatus string
+
+const (
+	OutboxEventStatusPending    OutboxEventStatus = "PENDING"
+	OutboxEventStatusPublishing OutboxEventStatus = "PUBLISHING"
+	OutboxEventStatusPublished  OutboxEventStatus = "PUBLISHED"
+	OutboxEventStatusFailed     OutboxEventStatus = "FAILED"
+)
+
+type OutboxEvent struct {
+	ID            uint64            `gorm:"primaryKey;autoIncrement;index:idx_outbox_events_status_id,priority:2" json:"id"`
+	AggregateType string            `gorm:"size:50;not null" json:"aggregate_type"`
+	AggregateID   string            `gorm:"size:64;not null;index" json:"aggregate_id"`
+	EventType     string            `gorm:"size:100;not null" json:"event_type"`
+	Payload       datatypes.JSON    `gorm:"not null" json:"payload"`
+	Status        OutboxEventStatus `gorm:"size:20;not null;index:idx_outbox_events_status_id,priority:1" json:"status"`
+	Attempts      int               `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	LastError     string            `gorm:"type:text" json:"last_error"`
+	PublishedAt   *time.Time        `json:"published_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox_events"
+}
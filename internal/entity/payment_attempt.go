@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentAttemptMethod string
+
+const (
+	PaymentAttemptMethodVirtualAccount PaymentAttemptMethod = "VIRTUAL_ACCOUNT"
+	PaymentAttemptMethodQRIS           PaymentAttemptMethod = "QRIS"
+)
+
+type PaymentAttemptStatus string
+
+const (
+	PaymentAttemptStatusCreating PaymentAttemptStatus = "CREATING"
+	PaymentAttemptStatusPending  PaymentAttemptStatus = "PENDING"
+	PaymentAttemptStatusPaid     PaymentAttemptStatus = "PAID"
+	PaymentAttemptStatusFailed   PaymentAttemptStatus = "FAILED"
+)
+
+// PaymentAttempt is a direct payment instrument issued for an invoice, such
+// as a fixed virtual account or a dynamic QRIS code, that bypasses the hosted
+// invoice page.
+type PaymentAttempt struct {
+	ID               uuid.UUID            `gorm:"type:char(36);primaryKey" json:"id"`
+	InvoiceID        uuid.UUID            `gorm:"type:char(36);index;not null" json:"invoice_id"`
+	Method           PaymentAttemptMethod `gorm:"size:30;not null" json:"method"`
+	Channel          string               `gorm:"size:30;not null" json:"channel"`
+	GatewayID        string               `gorm:"size:255;uniqueIndex" json:"gateway_id"`
+	AccountNumber    string               `gorm:"size:50" json:"account_number"`
+	QRString         string               `gorm:"type:text" json:"qr_string"`
+	Amount           money.Money          `gorm:"embedded" json:"amount"`
+	Status           PaymentAttemptStatus `gorm:"size:20;index" json:"status"`
+	GatewayPaymentID string               `gorm:"size:255" json:"gateway_payment_id"`
+	ExpiresAt        *time.Time           `json:"expires_at"`
+	PaidAt           *time.Time           `json:"paid_at"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+func (PaymentAttempt) TableName() string {
+	return "payment_attempts"
+}
+
+func (a *PaymentAttempt) IsOpenAt(now time.Time) bool {
+	return a.Status == PaymentAttemptStatusPending && (a.ExpiresAt == nil || a.ExpiresAt.After(now))
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RaisedAnomaly marks a payment anomaly as alerted on, so a callback that is
+// retried or replayed does not alert finance again. The anomaly details are
+// kept in MongoDB.
+type RaisedAnomaly struct {
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey" json:"id"`
+	InvoiceID uuid.UUID `gorm:"type:char(36);not null;index" json:"invoice_id"`
+	Reference string    `gorm:"size:255;not null;uniqueIndex:idx_raised_anomalies_reference_type" json:"reference"`
+	Type      string    `gorm:"size:50;not null;uniqueIndex:idx_raised_anomalies_reference_type" json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (RaisedAnomaly) TableName() string {
+	return "raised_anomalies"
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefundStatus string
+
+const (
+	RefundStatusPending   RefundStatus = "PENDING"
+	RefundStatusSucceeded RefundStatus = "SUCCEEDED"
+	RefundStatusFailed    RefundStatus = "FAILED"
+)
+
+type Refund struct {
+	ID             uuid.UUID    `gorm:"type:char(36);primaryKey" json:"id"`
+	InvoiceID      uuid.UUID    `gorm:"type:char(36);index;not null" json:"invoice_id"`
+	Invoice        Invoice      `gorm:"foreignKey:InvoiceID" json:"-"`
+	UserID         uuid.UUID    `gorm:"type:char(36);index" json:"user_id"`
+	XenditRefundID string       `gorm:"size:255;index" json:"xendit_refund_id"`
+	Amount         money.Money  `gorm:"embedded" json:"amount"`
+	Reason         string       `gorm:"size:50" json:"reason"`
+	Status         RefundStatus `gorm:"size:50;index" json:"status"`
+	FailureCode    string       `gorm:"size:255" json:"failure_code"`
+	CompletedAt    *time.Time   `json:"completed_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type VoucherType string
+
+const (
+	VoucherTypePercentage VoucherType = "PERCENTAGE"
+	VoucherTypeFixed      VoucherType = "FIXED"
+)
+
+// Voucher amounts (fixed value, discount cap and minimum spend) are minor
+// units of Currency. Percentage vouchers store the percentage in Value.
+type Voucher struct {
+	ID           uuid.UUID   `gorm:"type:char(36);primaryKey" json:"id"`
+	Code         string      `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Description  string      `gorm:"size:500" json:"description"`
+	Type         VoucherType `gorm:"size:20;not null" json:"type"`
+	Value        int64       `gorm:"not null" json:"value"`
+	MaxDiscount  int64       `gorm:"not null;default:0" json:"max_discount"`
+	MinSpend     int64       `gorm:"not null;default:0" json:"min_spend"`
+	Currency     string      `gorm:"size:3;not null;default:IDR" json:"currency"`
+	UsageLimit   int         `gorm:"not null;default:0" json:"usage_limit"`
+	PerUserLimit int         `gorm:"not null;default:0" json:"per_user_limit"`
+	UsedCount    int         `gorm:"not null;default:0" json:"used_count"`
+	StartsAt     *time.Time  `json:"starts_at"`
+	EndsAt       *time.Time  `json:"ends_at"`
+	Active       bool        `gorm:"not null;default:true" json:"active"`
+	UpdatedBy    string      `gorm:"size:255" json:"updated_by"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+func (Voucher) TableName() string {
+	return "vouchers"
+}
+
+func (v *Voucher) IsValidAt(at time.Time) bool {
+	if v.StartsAt != nil && at.Before(*v.StartsAt) {
+		return false
+	}
+	if v.EndsAt != nil && !at.Before(*v.EndsAt) {
+		return false
+	}
+	return true
+}
+
+// Discount is the amount taken off the given total, capped at MaxDiscount
+// when one is set and never more than the total itself.
+func (v *Voucher) Discount(total money.Money) money.Money {
+	var value int64
+	switch v.Type {
+	case VoucherTypePercentage:
+		value = total.Value * v.Value / 100
+	case VoucherTypeFixed:
+		value = v.Value
+	}
+	if v.MaxDiscount > 0 && value > v.MaxDiscount {
+		value = v.MaxDiscount
+	}
+	return money.New(min(value, total.Value), total.Currency)
+}
+
+type VoucherRedemptionStatus string
+
+const (
+	VoucherRedemptionReserved VoucherRedemptionStatus = "RESERVED"
+	VoucherRedemptionRedeemed VoucherRedemptionStatus = "REDEEMED"
+	VoucherRedemptionReleased VoucherRedemptionStatus = "RELEASED"
+)
+
+type VoucherRedemption struct {
+	ID            uuid.UUID               `gorm:"type:char(36);primaryKey" json:"id"`
+	VoucherID     uuid.UUID               `gorm:"type:char(36);not null;index:idx_voucher_redemptions_voucher_user,priority:1" json:"voucher_id"`
+	UserID        uuid.UUID               `gorm:"type:char(36);not null;index:idx_voucher_redemptions_voucher_user,priority:2" json:"user_id"`
+	InvoiceID     uuid.UUID               `gorm:"type:char(36);not null;uniqueIndex" json:"invoice_id"`
+	OrderID       uuid.UUID               `gorm:"type:char(36);not null;index" json:"order_id"`
+	Discount      money.Money             `gorm:"embedded;embeddedPrefix:discount_" json:"discount"`
+	Status        VoucherRedemptionStatus `gorm:"size:20;not null;index:idx_voucher_redemptions_status_reserved_until,priority:1" json:"status"`
+	ReservedUntil *time.Time              `gorm:"index:idx_voucher_redemptions_status_reserved_until,priority:2" json:"reserved_until"`
+	ReleasedAt    *time.Time              `json:"released_at"`
+	CreatedAt     time.Time               `json:"created_at"`
+	UpdatedAt     time.Time               `json:"updated_at"`
+}
+
+func (VoucherRedemption) TableName() string {
+	return "voucher_redemptions"
+}
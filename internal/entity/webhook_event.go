@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+type WebhookEventStatus string
+
+const (
+	WebhookEventStatusReceived  WebhookEventStatus = "RECEIVED"
+	WebhookEventStatusProcessed WebhookEventStatus = "PROCESSED"
+	WebhookEventStatusFailed    WebhookEventStatus = "FAILED"
+)
+
+type WebhookEvent struct {
+	ID          uuid.UUID          `gorm:"type:char(36);primaryKey" json:"id"`
+	Provider    string             `gorm:"size:50;not null;uniqueIndex:idx_webhook_events_provider_event" json:"provider"`
+	EventID     string             `gorm:"size:255;not null;uniqueIndex:idx_webhook_events_provider_event" json:"event_id"`
+	EventType   string             `gorm:"size:100;index" json:"event_type"`
+	Headers     datatypes.JSON     `json:"headers"`
+	Body        string             `gorm:"type:longtext" json:"body"`
+	Status      WebhookEventStatus `gorm:"size:50;index" json:"status"`
+	Attempts    int                `gorm:"not null;default:0" json:"attempts"`
+	Deliveries  int                `gorm:"not null;default:1" json:"deliveries"`
+	LastError   string             `gorm:"type:text" json:"last_error"`
+	ReceivedAt  time.Time          `gorm:"index" json:"received_at"`
+	ProcessedAt *time.Time         `json:"processed_at"`
+	CreatedAt   time.Time          `json:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+func (WebhookEvent) TableName() string {
+	return "webhook_events"
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"slices"
+	"strings"
+)
+
+// channelCurrencies lists the presentment currencies each invoice payment
+// channel can settle, following Xendit's per-country channel coverage.
+var channelCurrencies = map[string][]string{
+	"BCA":         {"IDR"},
+	"BNI":         {"IDR"},
+	"BRI":         {"IDR"},
+	"BSI":         {"IDR"},
+	"BJB":         {"IDR"},
+	"MANDIRI":     {"IDR"},
+	"PERMATA":     {"IDR"},
+	"ALFAMART":    {"IDR"},
+	"INDOMARET":   {"IDR"},
+	"QRIS":        {"IDR"},
+	"OVO":         {"IDR"},
+	"DANA":        {"IDR"},
+	"LINKAJA":     {"IDR"},
+	"SHOPEEPAY":   {"IDR", "PHP", "MYR", "THB", "VND"},
+	"GCASH":       {"PHP"},
+	"PAYMAYA":     {"PHP"},
+	"GRABPAY":     {"PHP", "MYR"},
+	"PROMPTPAY":   {"THB"},
+	"TOUCHNGO":    {"MYR"},
+	"CREDIT_CARD": {"IDR", "PHP", "THB", "VND", "MYR", "USD"},
+}
+
+// virtualAccountBanks are the channels that can issue a fixed virtual
+// account. They only settle IDR.
+var virtualAccountBanks = []string{"BCA", "BJB", "BNI", "BRI", "BSI", "MANDIRI", "PERMATA"}
+
+func IsVirtualAccountBank(bank string) bool {
+	return slices.Contains(virtualAccountBanks, strings.ToUpper(bank))
+}
+
+func SupportsCurrency(channel, currency string) bool {
+	return slices.Contains(channelCurrencies[strings.ToUpper(channel)], strings.ToUpper(currency))
+}
+
+// ChannelsForCurrency returns the channels that accept the currency, sorted so
+// the list sent to the gateway is stable.
+func ChannelsForCurrency(currency string) []string {
+	var channels []string
+	for channel := range channelCurrencies {
+		if SupportsCurrency(channel, currency) {
+			channels = append(channels, channel)
+		}
+	}
+	slices.Sort(channels)
+	return channels
+}
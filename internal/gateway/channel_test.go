@@ -0,0 +1,28 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportsCurrency(t *testing.T) {
+	assert.True(t, SupportsCurrency("bca", "idr"))
+	assert.True(t, SupportsCurrency("SHOPEEPAY", "PHP"))
+	assert.False(t, SupportsCurrency("BCA", "USD"))
+	assert.False(t, SupportsCurrency("UNKNOWN", "IDR"))
+}
+
+func TestChannelsForCurrency(t *testing.T) {
+	assert.Equal(t, []string{"CREDIT_CARD", "GCASH", "GRABPAY", "PAYMAYA", "SHOPEEPAY"}, ChannelsForCurrency("php"))
+	assert.Equal(t, []string{"CREDIT_CARD"}, ChannelsForCurrency("USD"))
+	assert.Empty(t, ChannelsForCurrency("EUR"))
+	assert.Contains(t, ChannelsForCurrency("IDR"), "BCA")
+}
+
+func TestIsVirtualAccountBank(t *testing.T) {
+	assert.True(t, IsVirtualAccountBank("bni"))
+	assert.True(t, IsVirtualAccountBank("MANDIRI"))
+	assert.False(t, IsVirtualAccountBank("OVO"))
+	assert.False(t, IsVirtualAccountBank("CIMB"))
+}
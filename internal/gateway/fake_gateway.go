@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golectro-payment/internal/money"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type FakeGateway struct {
+	Log             *logrus.Logger
+	mu              sync.RWMutex
+	invoices        map[string]*Invoice
+	refunds         map[string]*Refund
+	virtualAccounts map[string]*VirtualAccount
+	qrCodes         map[string]*QRCode
+}
+
+func NewFakeGateway(log *logrus.Logger) *FakeGateway {
+	return &FakeGateway{
+		Log:             log,
+		invoices:        make(map[string]*Invoice),
+		refunds:         make(map[string]*Refund),
+		virtualAccounts: make(map[string]*VirtualAccount),
+		qrCodes:         make(map[string]*QRCode),
+	}
+}
+
+func (g *FakeGateway) CreateInvoice(ctx context.Context, params *CreateInvoiceParams) (*Invoice, error) {
+	if params.ExternalID == "" || params.Amount.Value <= 0 {
+		return nil, errors.New("fake gateway: external ID and a positive amount are required")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	id := uuid.NewString()
+	duration := params.Duration
+	if duration <= 0 {
+		duration = 24 * time.Hour
+	}
+	expiry := time.Now().Add(duration)
+	inv := &Invoice{
+		ID:          id,
+		ExternalID:  params.ExternalID,
+		Amount:      params.Amount,
+		PayerEmail:  params.PayerEmail,
+		Description: params.Description,
+		Status:      "PENDING",
+		InvoiceURL:  fmt.Sprintf("https://checkout.fake.local/web/%s", id),
+		ExpiryDate:  &expiry,
+	}
+	g.invoices[id] = inv
+
+	g.Log.WithField("xendit_id", id).Debug("Fake gateway created invoice")
+	return copyInvoice(inv), nil
+}
+
+func (g *FakeGateway) GetInvoice(ctx context.Context, id string) (*Invoice, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	inv, ok := g.invoices[id]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	return copyInvoice(inv), nil
+}
+
+func (g *FakeGateway) ExpireInvoice(ctx context.Context, id string) (*Invoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inv, ok := g.invoices[id]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	if inv.Status != "PENDING" {
+		return nil, fmt.Errorf("fake gateway: cannot expire invoice in status %s", inv.Status)
+	}
+
+	inv.Status = "EXPIRED"
+	return copyInvoice(inv), nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, params *RefundParams) (*Refund, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inv, ok := g.invoices[params.InvoiceID]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	if inv.Status != "PAID" && inv.Status != "SETTLED" {
+		return nil, fmt.Errorf("%w: fake gateway: cannot refund invoice in status %s", ErrRequestRejected, inv.Status)
+	}
+
+	refunded := money.New(0, inv.Amount.Currency)
+	for _, r := range g.refunds {
+		if r.ReferenceID == params.ReferenceID {
+			result := *r
+			return &result, nil
+		}
+		if r.InvoiceID == params.InvoiceID && r.Status != "FAILED" {
+			refunded = refunded.Add(r.Amount)
+		}
+	}
+	if params.Amount.Value <= 0 || refunded.Add(params.Amount).Value > inv.PaidAmount.Value {
+		return nil, fmt.Errorf("%w: fake gateway: refund amount exceeds refundable amount", ErrRequestRejected)
+	}
+
+	refund := &Refund{
+		ID:          uuid.NewString(),
+		InvoiceID:   params.InvoiceID,
+		ReferenceID: params.ReferenceID,
+		Amount:      params.Amount,
+		Reason:      params.Reason,
+		Status:      "SUCCEEDED",
+	}
+	g.refunds[refund.ID] = refund
+
+	result := *refund
+	return &result, nil
+}
+
+func (g *FakeGateway) CreateVirtualAccount(ctx context.Context, params *CreateVirtualAccountParams) (*VirtualAccount, error) {
+	if params.ExternalID == "" || params.BankCode == "" || params.Amount.Value <= 0 {
+		return nil, errors.New("fake gateway: external ID, bank code and a positive amount are required")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	va := &VirtualAccount{
+		ID:            uuid.NewString(),
+		ExternalID:    params.ExternalID,
+		BankCode:      params.BankCode,
+		AccountNumber: fmt.Sprintf("8808%010d", rand.Int64N(1e10)),
+		Amount:        params.Amount,
+		Status:        "ACTIVE",
+		ExpiresAt:     params.ExpiresAt,
+	}
+	g.virtualAccounts[va.ID] = va
+
+	result := *va
+	return &result, nil
+}
+
+func (g *FakeGateway) CreateQRCode(ctx context.Context, params *CreateQRCodeParams) (*QRCode, error) {
+	if params.ReferenceID == "" || params.Amount.Value <= 0 {
+		return nil, errors.New("fake gateway: reference ID and a positive amount are required")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	expiry := params.ExpiresAt
+	qr := &QRCode{
+		ID:          "qr_" + uuid.NewString(),
+		ReferenceID: params.ReferenceID,
+		QRString:    fmt.Sprintf("00020101021226590014ID.FAKE.QRIS%s5303360540%d5802ID6304", params.ReferenceID, params.Amount.Value),
+		Amount:      params.Amount,
+		Status:      "ACTIVE",
+		ExpiresAt:   &expiry,
+	}
+	g.qrCodes[qr.ID] = qr
+
+	result := *qr
+	return &result, nil
+}
+
+func (g *FakeGateway) MarkInvoicePaid(id, paymentMethod, paymentChannel string) (*Invoice, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	inv, ok := g.invoices[id]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+
+	now := time.Now()
+	inv.Status = "PAID"
+	inv.PaidAmount = inv.Amount
+	inv.PaymentMethod = paymentMethod
+	inv.PaymentChannel = paymentChannel
+	inv.PaidAt = &now
+	return copyInvoice(inv), nil
+}
+
+func copyInvoice(inv *Invoice) *Invoice {
+	result := *inv
+	return &result
+}
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/money"
+	"time"
+)
+
+var (
+	ErrInvoiceNotFound = errors.New("invoice not found in payment gateway")
+	ErrRequestRejected = errors.New("request rejected by payment gateway")
+)
+
+// IsRejected reports whether the gateway definitely turned a request down.
+// Any other error, such as a timeout, may have reached the gateway and been
+// carried out.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrRequestRejected) || errors.Is(err, ErrInvoiceNotFound)
+}
+
+type PaymentGateway interface {
+	CreateInvoice(ctx context.Context, params *CreateInvoiceParams) (*Invoice, error)
+	GetInvoice(ctx context.Context, id string) (*Invoice, error)
+	ExpireInvoice(ctx context.Context, id string) (*Invoice, error)
+	Refund(ctx context.Context, params *RefundParams) (*Refund, error)
+	CreateVirtualAccount(ctx context.Context, params *CreateVirtualAccountParams) (*VirtualAccount, error)
+	CreateQRCode(ctx context.Context, params *CreateQRCodeParams) (*QRCode, error)
+}
+
+type CreateInvoiceParams struct {
+	ExternalID         string
+	Amount             money.Money
+	PayerEmail         string
+	Description        string
+	Customer           Customer
+	Locale             string
+	Duration           time.Duration
+	PaymentMethods     []string
+	Items              []InvoiceItem
+	Fees               []InvoiceFee
+	SuccessRedirectURL string
+	FailureRedirectURL string
+}
+
+type InvoiceItem struct {
+	Name     string
+	Quantity int
+	Price    money.Money
+}
+
+type Customer struct {
+	Name  string
+	Phone string
+}
+
+// InvoiceFee adjusts the invoice total on top of its items. Discounts are
+// negative fees.
+type InvoiceFee struct {
+	Type  string
+	Value money.Money
+}
+
+type Invoice struct {
+	ID             string
+	ExternalID     string
+	Amount         money.Money
+	PaidAmount     money.Money
+	PayerEmail     string
+	Description    string
+	Status         string
+	InvoiceURL     string
+	PaymentMethod  string
+	PaymentChannel string
+	PaidAt         *time.Time
+	ExpiryDate     *time.Time
+}
+
+// CreateVirtualAccountParams describes a closed, single-use virtual account
+// that only accepts the exact amount.
+type CreateVirtualAccountParams struct {
+	ExternalID  string
+	BankCode    string
+	Name        string
+	Amount      money.Money
+	Description string
+	ExpiresAt   *time.Time
+}
+
+type VirtualAccount struct {
+	ID            string
+	ExternalID    string
+	BankCode      string
+	AccountNumber string
+	Amount        money.Money
+	Status        string
+	ExpiresAt     *time.Time
+}
+
+// CreateQRCodeParams describes a dynamic QRIS code for a fixed amount.
+type CreateQRCodeParams struct {
+	ReferenceID string
+	Amount      money.Money
+	ExpiresAt   time.Time
+}
+
+type QRCode struct {
+	ID          string
+	ReferenceID string
+	QRString    string
+	Amount      money.Money
+	Status      string
+	ExpiresAt   *time.Time
+}
+
+type RefundParams struct {
+	InvoiceID   string
+	ReferenceID string
+	Amount      money.Money
+	Reason      string
+}
+
+type Refund struct {
+	ID          string
+	InvoiceID   string
+	ReferenceID string
+	Amount      money.Money
+	Reason      string
+	Status      string
+}
@@ -0,0 +1,37 @@
+package gateway
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xendit/xendit-go"
+)
+
+func TestToGatewayError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		notFound bool
+		rejected bool
+	}{
+		{"not found", http.StatusNotFound, true, false},
+		{"invalid request", http.StatusBadRequest, false, true},
+		{"invalid key", http.StatusUnauthorized, false, true},
+		{"duplicate request", http.StatusConflict, false, false},
+		{"timed out", http.StatusRequestTimeout, false, false},
+		{"transport failure", http.StatusTeapot, false, false},
+		{"rate limited", http.StatusTooManyRequests, false, false},
+		{"server error", http.StatusBadGateway, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := toGatewayError(&xendit.Error{Status: tt.status, ErrorCode: "CODE", Message: "message"})
+			assert.Equal(t, tt.notFound, errors.Is(err, ErrInvoiceNotFound))
+			assert.Equal(t, tt.rejected, errors.Is(err, ErrRequestRejected))
+			assert.Equal(t, tt.notFound || tt.rejected, IsRejected(err))
+		})
+	}
+}
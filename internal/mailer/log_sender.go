@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogSender stands in for SMTP in environments without a mail server and
+// only records what would have been sent.
+type LogSender struct {
+	Log *logrus.Logger
+}
+
+func NewLogSender(log *logrus.Logger) *LogSender {
+	return &LogSender{Log: log}
+}
+
+func (s *LogSender) Send(ctx context.Context, message *Message) error {
+	s.Log.WithField("to", message.To).Infof("Email not sent, no SMTP server configured: %s", message.Subject)
+	return nil
+}
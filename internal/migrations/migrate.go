@@ -2,10 +2,88 @@ package migrations
 
 import (
 	"golectro-payment/internal/entity"
+	"golectro-payment/internal/money"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 func Migrate(db *gorm.DB) error {
-	return db.AutoMigrate(entity.Invoice{})
+	if err := migrateMoneyColumns(db); err != nil {
+		return err
+	}
+
+	backfillOrderAmount := db.Migrator().HasTable(&entity.Invoice{}) && !db.Migrator().HasColumn(&entity.Invoice{}, "order_amount")
+	backfillOpenOrders := db.Migrator().HasTable(&entity.Invoice{}) && !db.Migrator().HasColumn(&entity.Invoice{}, "open_order_id")
+
+	if err := db.AutoMigrate(entity.Invoice{}, entity.Refund{}, entity.WebhookEvent{}, entity.OutboxEvent{}, entity.InvoiceStatusHistory{}, entity.ExchangeRate{}, entity.InvoiceItem{}, entity.Notification{}, entity.Voucher{}, entity.VoucherRedemption{}, entity.PaymentAttempt{}, entity.RaisedAnomaly{}); err != nil {
+		return err
+	}
+
+	// Invoices created before multi-currency support were billed in the
+	// order's own currency, so the order amount equals the invoice amount.
+	if backfillOrderAmount {
+		err := db.Unscoped().Model(&entity.Invoice{}).
+			Where("1 = 1").
+			UpdateColumns(map[string]any{
+				"order_amount":   gorm.Expr("amount"),
+				"order_currency": gorm.Expr("currency"),
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if backfillOpenOrders {
+		return claimOpenOrders(db)
+	}
+	return nil
+}
+
+// claimOpenOrders gives each order's latest invoice that did not end unpaid
+// the claim on its order, so older duplicates cannot block the unique index.
+func claimOpenOrders(db *gorm.DB) error {
+	var invoices []entity.Invoice
+	err := db.Unscoped().
+		Select("id", "order_id").
+		Where("status NOT IN ?", []entity.InvoiceStatus{entity.InvoiceStatusExpired, entity.InvoiceStatusFailed}).
+		Order("created_at DESC").
+		Find(&invoices).Error
+	if err != nil {
+		return err
+	}
+
+	claimed := make(map[uuid.UUID]bool, len(invoices))
+	for _, invoice := range invoices {
+		if claimed[invoice.OrderID] {
+			continue
+		}
+		claimed[invoice.OrderID] = true
+
+		if err := db.Unscoped().Model(&entity.Invoice{}).Where("id = ?", invoice.ID).UpdateColumn("open_order_id", invoice.OrderID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateMoneyColumns rewrites legacy float amounts into minor units before
+// AutoMigrate turns the column into a bigint and adds the currency column.
+// Rows written before currencies existed were always settled in the default
+// currency, and the missing currency column marks a table as not yet migrated.
+func migrateMoneyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, model := range []any{&entity.Invoice{}, &entity.Refund{}} {
+		if !migrator.HasTable(model) || migrator.HasColumn(model, "currency") {
+			continue
+		}
+
+		err := db.Unscoped().Model(model).
+			Where("1 = 1").
+			UpdateColumn("amount", gorm.Expr("ROUND(amount * POW(10, ?), 0)", money.Exponent(money.DefaultCurrency))).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
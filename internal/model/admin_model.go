@@ -0,0 +1,25 @@
+package model
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+)
+
+type AdminInvoiceResponse struct {
+	InvoiceResponse
+	UserID         string                          `json:"user_id"`
+	PaymentMethod  string                          `json:"payment_method"`
+	PaymentChannel string                          `json:"payment_channel"`
+	RefundedAmount money.Money                     `json:"refunded_amount"`
+	CreatedAt      time.Time                       `json:"created_at"`
+	UpdatedAt      time.Time                       `json:"updated_at"`
+	History        []*InvoiceStatusHistoryResponse `json:"history"`
+	Refunds        []*RefundResponse               `json:"refunds"`
+}
+
+type InvoiceStatusHistoryResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Source     string    `json:"source"`
+	CreatedAt  time.Time `json:"created_at"`
+}
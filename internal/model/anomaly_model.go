@@ -0,0 +1,24 @@
+package model
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PaymentAnomaly struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	Event         string             `bson:"event" json:"event"`
+	Type          string             `bson:"type" json:"type"`
+	InvoiceID     string             `bson:"invoice_id" json:"invoice_id"`
+	OrderID       string             `bson:"order_id" json:"order_id"`
+	XenditID      string             `bson:"xendit_id" json:"xendit_id"`
+	InvoiceStatus string             `bson:"invoice_status" json:"invoice_status"`
+	Expected      money.Money        `bson:"expected" json:"expected"`
+	Received      money.Money        `bson:"received" json:"received"`
+	Difference    money.Money        `bson:"difference" json:"difference"`
+	Source        string             `bson:"source" json:"source"`
+	Reviewed      bool               `bson:"reviewed" json:"reviewed"`
+	DetectedAt    time.Time          `bson:"detected_at" json:"detected_at"`
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Branding is how payer facing documents present the merchant: receipts,
+// emails and the virtual account holder name.
+type Branding struct {
+	Name     string
+	Location *time.Location
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+)
+
+type CreateVirtualAccountRequest struct {
+	BankCode string `json:"bank_code" validate:"required,max=30"`
+}
+
+type VirtualAccountResponse struct {
+	ID            string              `json:"id"`
+	Bank          string              `json:"bank"`
+	AccountNumber string              `json:"account_number"`
+	Amount        money.Money         `json:"amount"`
+	Status        string              `json:"status"`
+	ExpiresAt     *time.Time          `json:"expires_at,omitempty"`
+	PaidAt        *time.Time          `json:"paid_at,omitempty"`
+	Instructions  map[string][]string `json:"instructions,omitempty"`
+}
+
+type QRCodeResponse struct {
+	ID        string      `json:"id"`
+	QRString  string      `json:"qr_string"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+	ExpiresAt *time.Time  `json:"expires_at,omitempty"`
+	PaidAt    *time.Time  `json:"paid_at,omitempty"`
+}
+
+// XenditVirtualAccountCallback is the payload Xendit sends when a fixed
+// virtual account receives a payment.
+type XenditVirtualAccountCallback struct {
+	ID                       string     `json:"id" validate:"required"`
+	PaymentID                string     `json:"payment_id"`
+	CallbackVirtualAccountID string     `json:"callback_virtual_account_id" validate:"required"`
+	ExternalID               string     `json:"external_id" validate:"required"`
+	BankCode                 string     `json:"bank_code" validate:"required"`
+	AccountNumber            string     `json:"account_number"`
+	Amount                   float64    `json:"amount" validate:"required,gt=0"`
+	Currency                 string     `json:"currency"`
+	TransactionTimestamp     *time.Time `json:"transaction_timestamp"`
+}
+
+// XenditQRCodeCallback is the payload Xendit sends when a dynamic QR code is
+// paid.
+type XenditQRCodeCallback struct {
+	Event string                   `json:"event" validate:"required"`
+	Data  XenditQRCodeCallbackData `json:"data" validate:"required"`
+}
+
+type XenditQRCodeCallbackData struct {
+	ID          string     `json:"id" validate:"required"`
+	QRID        string     `json:"qr_id" validate:"required"`
+	ReferenceID string     `json:"reference_id" validate:"required"`
+	Amount      float64    `json:"amount" validate:"required,gt=0"`
+	Currency    string     `json:"currency"`
+	Status      string     `json:"status" validate:"required"`
+	ChannelCode string     `json:"channel_code"`
+	Created     *time.Time `json:"created"`
+}
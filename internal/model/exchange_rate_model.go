@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type UpsertExchangeRateRequest struct {
+	BaseCurrency  string  `json:"base_currency" validate:"required,currency"`
+	QuoteCurrency string  `json:"quote_currency" validate:"required,currency,nefield=BaseCurrency"`
+	Rate          float64 `json:"rate" validate:"required,gt=0"`
+}
+
+type ExchangeRateResponse struct {
+	BaseCurrency  string    `json:"base_currency"`
+	QuoteCurrency string    `json:"quote_currency"`
+	Rate          float64   `json:"rate"`
+	UpdatedBy     string    `json:"updated_by"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
@@ -1,37 +1,121 @@
 package model
 
+import (
+	"golectro-payment/internal/money"
+	"time"
+)
+
 type CreateInvoiceRequest struct {
-	OrderID     string `json:"order_id" validate:"required"`
+	OrderID     string `json:"order_id" validate:"required,uuid"`
 	Description string `json:"description" validate:"required"`
+	Currency    string `json:"currency" validate:"omitempty,currency"`
+	VoucherCode string `json:"voucher_code" validate:"omitempty,max=50"`
+	SuccessURL  string `json:"success_redirect_url" validate:"omitempty,http_url,max=1000"`
+	FailureURL  string `json:"failure_redirect_url" validate:"omitempty,http_url,max=1000"`
+
+	InvoiceDuration int64    `json:"invoice_duration" validate:"omitempty,gt=0"`
+	PaymentMethods  []string `json:"payment_methods" validate:"omitempty,max=25,dive,required,max=30"`
+	CustomerName    string   `json:"customer_name" validate:"omitempty,max=255"`
+	CustomerPhone   string   `json:"customer_phone" validate:"omitempty,e164"`
+	Locale          string   `json:"locale" validate:"omitempty,oneof=en id th vi"`
 }
 
 type CreateInvoiceResponse struct {
-	ID         string  `json:"id"`
-	OrderID    string  `json:"order_id"`
-	XenditID   string  `json:"xendit_id"`
-	InvoiceURL string  `json:"invoice_url"`
-	Amount     float64 `json:"amount"`
-	Status     string  `json:"status"`
+	ID             string                 `json:"id"`
+	OrderID        string                 `json:"order_id"`
+	XenditID       string                 `json:"xendit_id"`
+	InvoiceURL     string                 `json:"invoice_url"`
+	SuccessURL     string                 `json:"success_redirect_url,omitempty"`
+	FailureURL     string                 `json:"failure_redirect_url,omitempty"`
+	Amount         money.Money            `json:"amount"`
+	OrderAmount    money.Money            `json:"order_amount"`
+	VoucherCode    string                 `json:"voucher_code,omitempty"`
+	Discount       money.Money            `json:"discount"`
+	ExchangeRate   float64                `json:"exchange_rate"`
+	ExchangeRateAt *time.Time             `json:"exchange_rate_at,omitempty"`
+	Status         string                 `json:"status"`
+	ExpiresAt      *time.Time             `json:"expires_at,omitempty"`
+	PaymentMethods []string               `json:"payment_methods,omitempty"`
+	CustomerName   string                 `json:"customer_name,omitempty"`
+	CustomerPhone  string                 `json:"customer_phone,omitempty"`
+	Locale         string                 `json:"locale,omitempty"`
+	Items          []*InvoiceItemResponse `json:"items"`
 }
 
 type InvoiceResponse struct {
-	ID          string  `json:"id"`
-	OrderID     string  `json:"order_id"`
-	XenditID    string  `json:"xendit_id"`
-	InvoiceURL  string  `json:"invoice_url"`
-	Amount      float64 `json:"amount"`
-	Status      string  `json:"status"`
-	PayerEmail  string  `json:"payer_email"`
-	Description string  `json:"description"`
+	ID             string                  `json:"id"`
+	OrderID        string                  `json:"order_id"`
+	XenditID       string                  `json:"xendit_id"`
+	InvoiceURL     string                  `json:"invoice_url"`
+	SuccessURL     string                  `json:"success_redirect_url,omitempty"`
+	FailureURL     string                  `json:"failure_redirect_url,omitempty"`
+	Amount         money.Money             `json:"amount"`
+	OrderAmount    money.Money             `json:"order_amount"`
+	PaidAmount     money.Money             `json:"paid_amount"`
+	VoucherCode    string                  `json:"voucher_code,omitempty"`
+	Discount       money.Money             `json:"discount"`
+	ExchangeRate   float64                 `json:"exchange_rate"`
+	ExchangeRateAt *time.Time              `json:"exchange_rate_at,omitempty"`
+	Status         string                  `json:"status"`
+	PaidAt         *time.Time              `json:"paid_at,omitempty"`
+	ExpiresAt      *time.Time              `json:"expires_at,omitempty"`
+	PayerEmail     string                  `json:"payer_email"`
+	PaymentMethods []string                `json:"payment_methods,omitempty"`
+	CustomerName   string                  `json:"customer_name,omitempty"`
+	CustomerPhone  string                  `json:"customer_phone,omitempty"`
+	Locale         string                  `json:"locale,omitempty"`
+	Description    string                  `json:"description"`
+	VirtualAccount *VirtualAccountResponse `json:"virtual_account,omitempty"`
+	QRCode         *QRCodeResponse         `json:"qr_code,omitempty"`
+	Items          []*InvoiceItemResponse  `json:"items"`
+}
+
+type InvoiceItemResponse struct {
+	ProductID string      `json:"product_id"`
+	Quantity  int32       `json:"quantity"`
+	Price     money.Money `json:"price"`
+	Subtotal  money.Money `json:"subtotal"`
 }
 
 type XenditCallbackData struct {
-	ID             string  `json:"id" validate:"required"`
-	ExternalID     string  `json:"external_id" validate:"required"`
-	Amount         float64 `json:"amount" validate:"required"`
-	Status         string  `json:"status" validate:"required"`
-	PayerEmail     string  `json:"payer_email" validate:"required,email"`
-	Description    string  `json:"description" validate:"required"`
-	PaymentMethod  string  `json:"payment_method" validate:"required"`
-	PaymentChannel string  `json:"payment_channel" validate:"required"`
+	ID             string     `json:"id" validate:"required"`
+	ExternalID     string     `json:"external_id" validate:"required"`
+	Amount         float64    `json:"amount" validate:"required"`
+	PaidAmount     float64    `json:"paid_amount"`
+	Currency       string     `json:"currency"`
+	Status         string     `json:"status" validate:"required"`
+	PayerEmail     string     `json:"payer_email" validate:"required,email"`
+	Description    string     `json:"description" validate:"required"`
+	PaymentMethod  string     `json:"payment_method" validate:"required_if=Status PAID"`
+	PaymentChannel string     `json:"payment_channel" validate:"required_if=Status PAID"`
+	PaidAt         *time.Time `json:"paid_at"`
+}
+
+type OrderEvent struct {
+	Event       string `json:"event" validate:"required"`
+	OrderID     string `json:"order_id" validate:"required,uuid"`
+	TotalAmount int64  `json:"total_amount" validate:"required_if=Event order.amount_changed,gte=0"`
+}
+
+type InvoiceStatusEvent struct {
+	InvoiceID      string `json:"invoice_id"`
+	OrderID        string `json:"order_id"`
+	Status         string `json:"status"`
+	PreviousStatus string `json:"previous_status"`
+	Source         string `json:"source"`
+	ChangedAt      string `json:"changed_at"`
+}
+
+type SearchInvoiceRequest struct {
+	UserID      string `form:"user_id" validate:"omitempty,uuid"`
+	Search      string `form:"search" validate:"omitempty,max=255"`
+	Page        int    `form:"page" validate:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+	Status      string `form:"status" validate:"omitempty,oneof=PENDING PAID UNDERPAID SETTLED EXPIRED FAILED PARTIALLY_REFUNDED REFUNDED"`
+	CreatedFrom string `form:"created_from" validate:"omitempty,datetime=2006-01-02"`
+	CreatedTo   string `form:"created_to" validate:"omitempty,datetime=2006-01-02"`
+	MinAmount   *int64 `form:"min_amount" validate:"omitempty,gte=0"`
+	MaxAmount   *int64 `form:"max_amount" validate:"omitempty,gte=0"`
+	Currency    string `form:"currency" validate:"required_with=MinAmount MaxAmount,omitempty,currency"`
+	Sort        string `form:"sort" validate:"omitempty,oneof=created_at -created_at amount -amount status -status"`
 }
@@ -0,0 +1,13 @@
+package model
+
+type InvoiceNotificationData struct {
+	Brand          string
+	InvoiceID      string
+	OrderID        string
+	Amount         string
+	InvoiceURL     string
+	ExpiresAt      string
+	PaidAt         string
+	PaymentMethod  string
+	PaymentChannel string
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"golectro-payment/internal/money"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReconciliationReport struct {
+	ID            primitive.ObjectID          `bson:"_id,omitempty" json:"id"`
+	Trigger       string                      `bson:"trigger" json:"trigger"`
+	OlderThan     string                      `bson:"older_than" json:"older_than"`
+	Checked       int                         `bson:"checked" json:"checked"`
+	Updated       int                         `bson:"updated" json:"updated"`
+	Discrepancies []ReconciliationDiscrepancy `bson:"discrepancies" json:"discrepancies"`
+	StartedAt     time.Time                   `bson:"started_at" json:"started_at"`
+	FinishedAt    time.Time                   `bson:"finished_at" json:"finished_at"`
+}
+
+type ReconciliationDiscrepancy struct {
+	Type           string       `bson:"type" json:"type"`
+	InvoiceID      string       `bson:"invoice_id" json:"invoice_id"`
+	OrderID        string       `bson:"order_id" json:"order_id"`
+	XenditID       string       `bson:"xendit_id" json:"xendit_id"`
+	LocalStatus    string       `bson:"local_status" json:"local_status"`
+	ProviderStatus string       `bson:"provider_status,omitempty" json:"provider_status,omitempty"`
+	LocalAmount    money.Money  `bson:"local_amount" json:"local_amount"`
+	ProviderAmount *money.Money `bson:"provider_amount,omitempty" json:"provider_amount,omitempty"`
+	Detail         string       `bson:"detail,omitempty" json:"detail,omitempty"`
+}
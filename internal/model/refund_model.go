@@ -0,0 +1,46 @@
+package model
+
+import "golectro-payment/internal/money"
+
+type CreateRefundRequest struct {
+	Amount int64  `json:"amount" validate:"omitempty,gt=0"`
+	Reason string `json:"reason" validate:"required,oneof=REQUESTED_BY_CUSTOMER CANCELLATION DUPLICATE FRAUDULENT OTHERS"`
+}
+
+type RefundResponse struct {
+	ID             string      `json:"id"`
+	InvoiceID      string      `json:"invoice_id"`
+	OrderID        string      `json:"order_id"`
+	XenditRefundID string      `json:"xendit_refund_id"`
+	Amount         money.Money `json:"amount"`
+	Reason         string      `json:"reason"`
+	Status         string      `json:"status"`
+	FailureCode    string      `json:"failure_code,omitempty"`
+	InvoiceStatus  string      `json:"invoice_status"`
+	RefundedAmount money.Money `json:"refunded_amount"`
+}
+
+type XenditRefundCallback struct {
+	Event string                   `json:"event" validate:"required"`
+	Data  XenditRefundCallbackData `json:"data" validate:"required"`
+}
+
+type XenditRefundCallbackData struct {
+	ID          string  `json:"id" validate:"required"`
+	InvoiceID   string  `json:"invoice_id"`
+	ReferenceID string  `json:"reference_id" validate:"required"`
+	Amount      float64 `json:"amount" validate:"required"`
+	Currency    string  `json:"currency"`
+	Status      string  `json:"status" validate:"required,oneof=PENDING SUCCEEDED FAILED"`
+	FailureCode string  `json:"failure_code"`
+}
+
+type RefundEvent struct {
+	Event          string      `json:"event"`
+	RefundID       string      `json:"refund_id"`
+	InvoiceID      string      `json:"invoice_id"`
+	OrderID        string      `json:"order_id"`
+	Amount         money.Money `json:"amount"`
+	RefundedAmount money.Money `json:"refunded_amount"`
+	InvoiceStatus  string      `json:"invoice_status"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+type UpsertVoucherRequest struct {
+	Code         string     `json:"code" validate:"required,alphanum,max=50"`
+	Description  string     `json:"description" validate:"max=500"`
+	Type         string     `json:"type" validate:"required,oneof=PERCENTAGE FIXED"`
+	Value        int64      `json:"value" validate:"required,gt=0"`
+	MaxDiscount  int64      `json:"max_discount" validate:"gte=0"`
+	MinSpend     int64      `json:"min_spend" validate:"gte=0"`
+	Currency     string     `json:"currency" validate:"omitempty,currency"`
+	UsageLimit   int        `json:"usage_limit" validate:"gte=0"`
+	PerUserLimit int        `json:"per_user_limit" validate:"gte=0"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	Active       *bool      `json:"active"`
+}
+
+type VoucherResponse struct {
+	Code         string     `json:"code"`
+	Description  string     `json:"description"`
+	Type         string     `json:"type"`
+	Value        int64      `json:"value"`
+	MaxDiscount  int64      `json:"max_discount"`
+	MinSpend     int64      `json:"min_spend"`
+	Currency     string     `json:"currency"`
+	UsageLimit   int        `json:"usage_limit"`
+	PerUserLimit int        `json:"per_user_limit"`
+	UsedCount    int        `json:"used_count"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"`
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	Active       bool       `json:"active"`
+	UpdatedBy    string     `json:"updated_by"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
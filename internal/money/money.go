@@ -0,0 +1,126 @@
+package money
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "IDR"
+
+// exponents holds the ISO 4217 minor unit count for currencies we settle in;
+// anything missing is treated as having two decimals.
+var exponents = map[string]int{
+	"IDR": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"USD": 2,
+	"EUR": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"PHP": 2,
+	"THB": 2,
+}
+
+type Money struct {
+	Value    int64  `gorm:"column:amount;not null" json:"value" bson:"value"`
+	Currency string `gorm:"column:currency;size:3;not null;default:IDR" json:"currency" bson:"currency"`
+}
+
+func New(value int64, currency string) Money {
+	return Money{Value: value, Currency: normalize(currency)}
+}
+
+// FromMajor converts a provider amount such as 12.345 USD into minor units,
+// rounding half away from zero at the currency's precision.
+func FromMajor(amount float64, currency string) Money {
+	currency = normalize(currency)
+	return Money{
+		Value:    int64(math.Round(amount * math.Pow10(Exponent(currency)))),
+		Currency: currency,
+	}
+}
+
+// Convert prices the amount in another currency at the rate, working in minor
+// units so only the result is rounded.
+func Convert(amount Money, rate float64, currency string) Money {
+	currency = normalize(currency)
+	shift := Exponent(currency) - Exponent(amount.Currency)
+	return Money{
+		Value:    int64(math.Round(float64(amount.Value) * rate * math.Pow10(shift))),
+		Currency: currency,
+	}
+}
+
+func Exponent(currency string) int {
+	if exponent, ok := exponents[normalize(currency)]; ok {
+		return exponent
+	}
+	return 2
+}
+
+func IsKnown(currency string) bool {
+	_, ok := exponents[normalize(currency)]
+	return ok
+}
+
+func (m Money) Major() float64 {
+	return float64(m.Value) / math.Pow10(Exponent(m.Currency))
+}
+
+// Add and Sub panic when the currencies differ, since no result would be
+// meaningful; callers convert first.
+func (m Money) Add(other Money) Money {
+	m.mustMatch(other)
+	return Money{Value: m.Value + other.Value, Currency: m.Currency}
+}
+
+func (m Money) Sub(other Money) Money {
+	m.mustMatch(other)
+	return Money{Value: m.Value - other.Value, Currency: m.Currency}
+}
+
+func (m Money) mustMatch(other Money) {
+	if normalize(m.Currency) != normalize(other.Currency) {
+		panic(fmt.Sprintf("money: currency mismatch: %s and %s", normalize(m.Currency), normalize(other.Currency)))
+	}
+}
+
+func (m Money) IsZero() bool {
+	return m.Value == 0
+}
+
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", normalize(m.Currency), strconv.FormatFloat(m.Major(), 'f', Exponent(m.Currency), 64))
+}
+
+// Format groups thousands for display, e.g. "IDR 1,250,000" or "USD 1,234.50".
+func (m Money) Format() string {
+	formatted := strconv.FormatFloat(m.Major(), 'f', Exponent(m.Currency), 64)
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(formatted, "-"), ".")
+
+	var grouped strings.Builder
+	if m.Value < 0 {
+		grouped.WriteByte('-')
+	}
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		grouped.WriteString("." + fraction)
+	}
+
+	return normalize(m.Currency) + " " + grouped.String()
+}
+
+func normalize(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
@@ -0,0 +1,79 @@
+package money
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromMajor(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		expected Money
+	}{
+		{12.345, "USD", New(1235, "USD")},
+		{-12.345, "usd", New(-1235, "USD")},
+		{150000.5, "IDR", New(150001, "IDR")},
+		{1.5, "", New(2, "IDR")},
+		{1.005, "XAU", New(100, "XAU")},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, FromMajor(tt.amount, tt.currency), "%v %s", tt.amount, tt.currency)
+	}
+}
+
+func TestAddAndSub(t *testing.T) {
+	assert.Equal(t, New(175000, "IDR"), New(150000, "IDR").Add(New(25000, "IDR")))
+	assert.Equal(t, New(-2500, "USD"), New(1000, "USD").Sub(New(3500, "usd")))
+	assert.Equal(t, Money{Value: 10, Currency: ""}, Money{Value: 5}.Add(New(5, "IDR")))
+}
+
+func TestAddAndSubRejectCurrencyMismatch(t *testing.T) {
+	assert.PanicsWithValue(t, "money: currency mismatch: IDR and USD", func() {
+		New(150000, "IDR").Add(New(1000, "USD"))
+	})
+	assert.Panics(t, func() {
+		New(1000, "USD").Sub(New(1000, "PHP"))
+	})
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		money    Money
+		expected string
+	}{
+		{New(1250000, "IDR"), "IDR 1,250,000"},
+		{New(123450, "USD"), "USD 1,234.50"},
+		{New(-99, "USD"), "USD -0.99"},
+		{New(-1000000, "IDR"), "IDR -1,000,000"},
+		{New(5, ""), "IDR 5"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, tt.money.Format())
+	}
+	assert.Equal(t, "USD 1234.50", New(123450, "USD").String())
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   Money
+		rate     float64
+		currency string
+		expected Money
+	}{
+		{"to more decimals", New(150000, "IDR"), 0.0000613, "USD", New(920, "USD")},
+		{"to fewer decimals", New(1999, "USD"), 16312.5, "IDR", New(326087, "IDR")},
+		{"same currency", New(150000, "IDR"), 1, "IDR", New(150000, "IDR")},
+		{"negative amount", New(-5000, "IDR"), 0.0000613, "usd", New(-31, "USD")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Convert(tt.amount, tt.rate, tt.currency))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package receipt
+
+import (
+	"bytes"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"strconv"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	pageMargin   = 15.0
+	contentWidth = 180.0
+	lineHeight   = 7.0
+	timeLayout   = "02 Jan 2006 15:04 MST"
+)
+
+var (
+	brandColor = [3]int{20, 62, 120}
+	mutedColor = [3]int{110, 110, 110}
+	ruleColor  = [3]int{220, 224, 230}
+)
+
+type Options struct {
+	Brand    string
+	Location *time.Location
+}
+
+// Render draws the invoice as an A4 PDF. Paid invoices render as a receipt,
+// anything else as an invoice, with every label in English and Indonesian.
+func Render(invoice *entity.Invoice, opts Options) ([]byte, error) {
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+
+	title := constants.InvoiceTitle
+	if invoice.PaidAt != nil {
+		title = constants.ReceiptTitle
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pageMargin, pageMargin, pageMargin)
+	pdf.SetAutoPageBreak(true, pageMargin)
+	pdf.SetTitle(fmt.Sprintf("%s %s", title["en"], invoice.ID), true)
+	pdf.SetAuthor(opts.Brand, true)
+	pdf.SetCreationDate(invoice.CreatedAt)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.AddPage()
+	drawHeader(pdf, tr, opts.Brand, title)
+
+	pdf.SetY(38)
+	details := [][2]string{
+		{label(constants.ReceiptInvoiceNumber), invoice.ID.String()},
+		{label(constants.ReceiptOrderNumber), invoice.OrderID.String()},
+		{label(constants.ReceiptStatus), string(invoice.Status)},
+		{label(constants.ReceiptIssuedAt), invoice.CreatedAt.In(opts.Location).Format(timeLayout)},
+		{label(constants.ReceiptBilledTo), invoice.PayerEmail},
+	}
+	if invoice.PaidAt != nil {
+		details = append(details,
+			[2]string{label(constants.ReceiptPaidAt), invoice.PaidAt.In(opts.Location).Format(timeLayout)},
+			[2]string{label(constants.ReceiptPaymentMethod), orDash(invoice.PaymentMethod)},
+			[2]string{label(constants.ReceiptPaymentChannel), orDash(invoice.PaymentChannel)},
+		)
+	}
+	for _, row := range details {
+		setTextColor(pdf, mutedColor)
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(70, lineHeight, tr(row[0]), "", 0, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(contentWidth-70, lineHeight, tr(row[1]), "", 1, "L", false, 0, "")
+	}
+
+	pdf.Ln(6)
+	drawItems(pdf, tr, invoice)
+
+	pdf.Ln(4)
+	if !invoice.Discount.IsZero() {
+		drawTotal(pdf, tr, fmt.Sprintf("%s (%s)", label(constants.ReceiptDiscount), invoice.VoucherCode), money.New(-invoice.Discount.Value, invoice.Discount.Currency))
+	}
+	drawTotal(pdf, tr, label(constants.ReceiptTotal), invoice.Amount)
+	if invoice.PaidAt != nil {
+		drawTotal(pdf, tr, label(constants.ReceiptAmountPaid), invoice.CollectedAmount())
+	}
+
+	pdf.Ln(12)
+	setTextColor(pdf, mutedColor)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.MultiCell(contentWidth, 4.5, tr(constants.ReceiptFooter["en"]+"\n"+constants.ReceiptFooter["id"]), "", "C", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func drawHeader(pdf *gofpdf.Fpdf, tr func(string) string, brand string, title model.Message) {
+	setFillColor(pdf, brandColor)
+	pdf.Rect(0, 0, 210, 28, "F")
+
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetXY(pageMargin, 9)
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(90, 10, tr(brand), "", 0, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(contentWidth-90, 6, tr(title["en"]), "", 2, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(contentWidth-90, 5, tr(title["id"]), "", 0, "R", false, 0, "")
+}
+
+func drawItems(pdf *gofpdf.Fpdf, tr func(string) string, invoice *entity.Invoice) {
+	widths := []float64{80, 20, 40, 40}
+	headers := []string{
+		label(constants.ReceiptItem),
+		label(constants.ReceiptQuantity),
+		label(constants.ReceiptUnitPrice),
+		label(constants.ReceiptSubtotal),
+	}
+	aligns := []string{"L", "C", "R", "R"}
+
+	setFillColor(pdf, brandColor)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 9)
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 8, tr(header), "", 0, aligns[i], true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "", 9)
+	setDrawColor(pdf, ruleColor)
+	for _, item := range invoice.Items {
+		cells := []string{
+			item.ProductID,
+			strconv.Itoa(int(item.Quantity)),
+			item.Price.Format(),
+			item.Subtotal().Format(),
+		}
+		for i, cell := range cells {
+			pdf.CellFormat(widths[i], lineHeight, tr(cell), "B", 0, aligns[i], false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if len(invoice.Items) == 0 {
+		pdf.CellFormat(widths[0]+widths[1]+widths[2], lineHeight, tr(invoice.Description), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], lineHeight, tr(invoice.OrderAmount.Format()), "B", 1, "R", false, 0, "")
+	}
+}
+
+func drawTotal(pdf *gofpdf.Fpdf, tr func(string) string, caption string, amount money.Money) {
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.CellFormat(contentWidth-60, lineHeight, tr(caption), "", 0, "R", false, 0, "")
+	pdf.CellFormat(60, lineHeight, tr(amount.Format()), "", 1, "R", false, 0, "")
+}
+
+func label(message model.Message) string {
+	if message["en"] == message["id"] {
+		return message["en"]
+	}
+	return message["en"] + " / " + message["id"]
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func setFillColor(pdf *gofpdf.Fpdf, rgb [3]int) {
+	pdf.SetFillColor(rgb[0], rgb[1], rgb[2])
+}
+
+func setTextColor(pdf *gofpdf.Fpdf, rgb [3]int) {
+	pdf.SetTextColor(rgb[0], rgb[1], rgb[2])
+}
+
+func setDrawColor(pdf *gofpdf.Fpdf, rgb [3]int) {
+	pdf.SetDrawColor(rgb[0], rgb[1], rgb[2])
+}
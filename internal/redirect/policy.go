@@ -0,0 +1,94 @@
+package redirect
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Policy decides where the hosted invoice page sends the payer afterwards.
+// Origins follow CORS_ALLOW_ORIGINS semantics: exact scheme://host[:port]
+// entries, or "*" to allow any origin.
+type Policy struct {
+	AllowOrigins []string
+	SuccessURL   string
+	FailureURL   string
+}
+
+// NewPolicy reads REDIRECT_ALLOW_ORIGINS, falling back to CORS_ALLOW_ORIGINS,
+// and the default URLs for the current ENV, e.g. REDIRECT_SUCCESS_URL_PRODUCTION
+// before REDIRECT_SUCCESS_URL. Unlike CORS, an empty allowlist allows nothing,
+// so only the defaults are used.
+func NewPolicy(viper *viper.Viper) *Policy {
+	origins := viper.GetStringSlice("REDIRECT_ALLOW_ORIGINS")
+	if len(origins) == 0 {
+		origins = viper.GetStringSlice("CORS_ALLOW_ORIGINS")
+	}
+
+	policy := &Policy{
+		SuccessURL: envSetting(viper, "REDIRECT_SUCCESS_URL"),
+		FailureURL: envSetting(viper, "REDIRECT_FAILURE_URL"),
+	}
+	for _, origin := range origins {
+		if origin = normalizeOrigin(origin); origin != "" {
+			policy.AllowOrigins = append(policy.AllowOrigins, origin)
+		}
+	}
+	return policy
+}
+
+func (p *Policy) Allowed(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	if parsed.User != nil {
+		return false
+	}
+	if slices.Contains(p.AllowOrigins, "*") {
+		return true
+	}
+	return slices.Contains(p.AllowOrigins, normalizeOrigin(parsed.Scheme+"://"+parsed.Host))
+}
+
+// Resolve fills in the defaults for URLs the caller left empty and reports
+// false when a requested URL is outside the allowlist.
+func (p *Policy) Resolve(successURL, failureURL string) (string, string, bool) {
+	if successURL == "" {
+		successURL = p.SuccessURL
+	} else if !p.Allowed(successURL) {
+		return "", "", false
+	}
+
+	if failureURL == "" {
+		failureURL = p.FailureURL
+	} else if !p.Allowed(failureURL) {
+		return "", "", false
+	}
+
+	return successURL, failureURL, true
+}
+
+func envSetting(viper *viper.Viper, key string) string {
+	if env := viper.GetString("ENV"); env != "" {
+		if value := viper.GetString(key + "_" + strings.ToUpper(env)); value != "" {
+			return value
+		}
+	}
+	return viper.GetString(key)
+}
+
+func normalizeOrigin(origin string) string {
+	origin = strings.TrimSpace(origin)
+	if origin == "*" {
+		return origin
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return ""
+	}
+	return strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+}
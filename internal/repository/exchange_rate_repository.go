@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ExchangeRateRepository struct {
+	Repository[entity.ExchangeRate]
+	Log *logrus.Logger
+}
+
+func NewExchangeRateRepository(log *logrus.Logger) *ExchangeRateRepository {
+	return &ExchangeRateRepository{
+		Log: log,
+	}
+}
+
+func (r *ExchangeRateRepository) FindByPair(tx *gorm.DB, base, quote string, rate *entity.ExchangeRate) error {
+	if err := tx.Where("base_currency = ? AND quote_currency = ?", base, quote).Take(rate).Error; err != nil {
+		r.Log.WithError(err).Warnf("Failed to find exchange rate %s/%s", base, quote)
+		return err
+	}
+	return nil
+}
+
+func (r *ExchangeRateRepository) FindAllOrdered(tx *gorm.DB, rates *[]entity.ExchangeRate) error {
+	if err := tx.Order("base_currency ASC, quote_currency ASC").Find(rates).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find exchange rates")
+		return err
+	}
+	return nil
+}
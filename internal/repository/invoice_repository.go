@@ -2,10 +2,12 @@ package repository
 
 import (
 	"golectro-payment/internal/entity"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type InvoiceRepository struct {
@@ -19,6 +21,14 @@ func NewInvoiceRepository(log *logrus.Logger) *InvoiceRepository {
 	}
 }
 
+func (r *InvoiceRepository) WithItems(tx *gorm.DB) *gorm.DB {
+	return tx.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Attempts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	})
+}
+
 func (r *InvoiceRepository) FindByUserID(tx *gorm.DB, userID uuid.UUID, invoice *entity.Invoice) error {
 	if err := tx.Where("user_id = ?", userID).First(invoice).Error; err != nil {
 		r.Log.WithError(err).Error("Failed to find invoice by user ID")
@@ -27,9 +37,67 @@ func (r *InvoiceRepository) FindByUserID(tx *gorm.DB, userID uuid.UUID, invoice
 	return nil
 }
 
-func (r *InvoiceRepository) FindByOrderID(tx *gorm.DB, orderID uuid.UUID, invoice *entity.Invoice) error {
-	if err := tx.Where("order_id = ?", orderID).Where("status = ?", "PENDING").First(invoice).Error; err != nil {
-		r.Log.WithError(err).Error("Failed to find invoice by order ID")
+func (r *InvoiceRepository) FindByIDAndUserIDForUpdate(tx *gorm.DB, id, userID uuid.UUID, invoice *entity.Invoice) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ? AND user_id = ?", id, userID).First(invoice).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find invoice by ID and user ID")
+		return err
+	}
+	return nil
+}
+
+func (r *InvoiceRepository) FindByIDForUpdate(tx *gorm.DB, id uuid.UUID, invoice *entity.Invoice) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(invoice).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find invoice by ID")
+		return err
+	}
+	return nil
+}
+
+func (r *InvoiceRepository) FindByXenditIDForUpdate(tx *gorm.DB, xenditID string, invoice *entity.Invoice) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("xendit_id = ?", xenditID).First(invoice).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find invoice by Xendit ID")
+		return err
+	}
+	return nil
+}
+
+func (r *InvoiceRepository) FindPendingByOrderIDForUpdate(tx *gorm.DB, orderID uuid.UUID, invoice *entity.Invoice) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_id = ?", orderID).Where("status = ?", entity.InvoiceStatusPending).First(invoice).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find pending invoice by order ID")
+		return err
+	}
+	return nil
+}
+
+func (r *InvoiceRepository) FindLatestByOrderID(tx *gorm.DB, orderID uuid.UUID, invoice *entity.Invoice) error {
+	if err := tx.Where("order_id = ?", orderID).Order("created_at DESC").First(invoice).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find latest invoice by order ID")
+		return err
+	}
+	return nil
+}
+
+// FindAllForReconciliation pages through invoices created before the cutoff
+// that are still pending, or are in one of the paid statuses and were created
+// after paidSince.
+func (r *InvoiceRepository) FindAllForReconciliation(tx *gorm.DB, paidStatuses []entity.InvoiceStatus, before, paidSince, afterCreatedAt time.Time, afterID uuid.UUID, limit int, invoices *[]entity.Invoice) error {
+	if err := tx.Where("(status = ? OR (status IN ? AND created_at > ?))", entity.InvoiceStatusPending, paidStatuses, paidSince).
+		Where("created_at < ?", before).
+		Where("(created_at > ? OR (created_at = ? AND id > ?))", afterCreatedAt, afterCreatedAt, afterID).
+		Order("created_at ASC, id ASC").
+		Limit(limit).
+		Find(invoices).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find invoices to reconcile")
+		return err
+	}
+	return nil
+}
+
+// FindOpenByOrderID also sees deleted invoices, since deleting one does not
+// stop it from being paid at the gateway.
+func (r *InvoiceRepository) FindOpenByOrderID(tx *gorm.DB, orderID uuid.UUID, invoice *entity.Invoice) error {
+	if err := tx.Unscoped().Where("order_id = ?", orderID).Where("status NOT IN ?", []entity.InvoiceStatus{entity.InvoiceStatusExpired, entity.InvoiceStatusFailed}).First(invoice).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find open invoice by order ID")
 		return err
 	}
 	return nil
@@ -42,7 +110,7 @@ func (r *InvoiceRepository) UpdateInvoice(
 
 	result := tx.Model(&entity.Invoice{}).
 		Where("order_id = ? AND xendit_id = ?", orderID, xenditID).
-		Omit("id", "created_at").
+		Omit("id", "created_at", "Items", "Attempts").
 		Updates(invoice)
 
 	if result.Error != nil {
@@ -55,6 +123,21 @@ func (r *InvoiceRepository) UpdateInvoice(
 	return nil
 }
 
+func (r *InvoiceRepository) UpdateStatus(tx *gorm.DB, id uuid.UUID, status entity.InvoiceStatus) error {
+	r.Log.Infof("Updating status of invoice %s to %s", id, status)
+
+	columns := map[string]any{"status": status}
+	if status.IsVoid() {
+		columns["open_order_id"] = nil
+	}
+
+	if err := tx.Model(&entity.Invoice{}).Where("id = ?", id).Updates(columns).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to update invoice status")
+		return err
+	}
+	return nil
+}
+
 func (r *InvoiceRepository) UpdateDeleteColumn(tx *gorm.DB, userID uuid.UUID, xenditID string) error {
 	r.Log.Infof("Updating delete column for userID: %s and xenditID: %s", userID, xenditID)
 
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type InvoiceStatusHistoryRepository struct {
+	Repository[entity.InvoiceStatusHistory]
+	Log *logrus.Logger
+}
+
+func NewInvoiceStatusHistoryRepository(log *logrus.Logger) *InvoiceStatusHistoryRepository {
+	return &InvoiceStatusHistoryRepository{
+		Log: log,
+	}
+}
+
+func (r *InvoiceStatusHistoryRepository) FindAllByInvoiceID(tx *gorm.DB, invoiceID uuid.UUID, histories *[]entity.InvoiceStatusHistory) error {
+	if err := tx.Where("invoice_id = ?", invoiceID).Order("created_at ASC").Find(histories).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find invoice status histories")
+		return err
+	}
+	return nil
+}
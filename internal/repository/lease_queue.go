@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaseQueue keeps the delivery state of tables that workers drain, such as
+// the outbox and payer notifications. A due row is claimed into the leased
+// status until its lease runs out, after which it is due again, so rows
+// claimed by a crashed worker get picked up by another one. Every attempt
+// ends with the row done, retried later or closed.
+type LeaseQueue[T any] struct {
+	Log *logrus.Logger
+	// Name describes a row in logs, e.g. "outbox event".
+	Name string
+
+	Pending any
+	Leased  any
+	Done    any
+	// DoneAt is the column recording when a row was done.
+	DoneAt string
+	// Order is the order in which due rows are claimed.
+	Order string
+}
+
+func (q *LeaseQueue[T]) FindDueForUpdate(tx *gorm.DB, now time.Time, limit int, rows *[]T) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status IN ? AND next_attempt_at <= ?", []any{q.Pending, q.Leased}, now).
+		Order(q.Order).
+		Limit(limit).
+		Find(rows).Error; err != nil {
+		q.Log.WithError(err).Errorf("Failed to fetch due %ss", q.Name)
+		return err
+	}
+	return nil
+}
+
+func (q *LeaseQueue[T]) Claim(tx *gorm.DB, ids any, leaseUntil time.Time) error {
+	return tx.Model(new(T)).Where("id IN ?", ids).Updates(map[string]any{
+		"status":          q.Leased,
+		"next_attempt_at": leaseUntil,
+	}).Error
+}
+
+// Release hands claimed rows back untouched, for rows a worker skipped.
+func (q *LeaseQueue[T]) Release(tx *gorm.DB, ids any, nextAttemptAt time.Time) error {
+	return tx.Model(new(T)).Where("id IN ? AND status = ?", ids, q.Leased).Updates(map[string]any{
+		"status":          q.Pending,
+		"next_attempt_at": nextAttemptAt,
+	}).Error
+}
+
+func (q *LeaseQueue[T]) MarkDone(tx *gorm.DB, id any, doneAt time.Time) error {
+	return tx.Model(new(T)).Where("id = ?", id).Updates(map[string]any{
+		"status":     q.Done,
+		q.DoneAt:     doneAt,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": "",
+	}).Error
+}
+
+func (q *LeaseQueue[T]) MarkRetry(tx *gorm.DB, id any, nextAttemptAt time.Time, lastError string) error {
+	return tx.Model(new(T)).Where("id = ?", id).Updates(map[string]any{
+		"status":          q.Pending,
+		"next_attempt_at": nextAttemptAt,
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+	}).Error
+}
+
+// MarkClosed ends a row in status without delivering it.
+func (q *LeaseQueue[T]) MarkClosed(tx *gorm.DB, id any, status any, lastError string) error {
+	return tx.Model(new(T)).Where("id = ?", id).Updates(map[string]any{
+		"status":     status,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"last_error": lastError,
+	}).Error
+}
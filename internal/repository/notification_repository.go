@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	Repository[entity.Notification]
+	LeaseQueue[entity.Notification]
+	Log *logrus.Logger
+}
+
+func NewNotificationRepository(log *logrus.Logger) *NotificationRepository {
+	return &NotificationRepository{
+		LeaseQueue: LeaseQueue[entity.Notification]{
+			Log:     log,
+			Name:    "notification",
+			Pending: entity.NotificationStatusPending,
+			Leased:  entity.NotificationStatusSending,
+			Done:    entity.NotificationStatusSent,
+			DoneAt:  "sent_at",
+			Order:   "next_attempt_at ASC",
+		},
+		Log: log,
+	}
+}
+
+// CreateIfAbsent keeps one notification per invoice and template, so a
+// status reached twice (e.g. PAID then SETTLED) does not email twice.
+func (r *NotificationRepository) CreateIfAbsent(tx *gorm.DB, notification *entity.Notification) error {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(notification).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to create notification")
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	Repository[entity.OutboxEvent]
+	LeaseQueue[entity.OutboxEvent]
+	Log *logrus.Logger
+}
+
+// Outbox events are claimed in insertion order, which is the order each
+// order's events are published in.
+func NewOutboxRepository(log *logrus.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		LeaseQueue: LeaseQueue[entity.OutboxEvent]{
+			Log:     log,
+			Name:    "outbox event",
+			Pending: entity.OutboxEventStatusPending,
+			Leased:  entity.OutboxEventStatusPublishing,
+			Done:    entity.OutboxEventStatusPublished,
+			DoneAt:  "published_at",
+			Order:   "id ASC",
+		},
+		Log: log,
+	}
+}
+
+var openOutboxStatuses = []entity.OutboxEventStatus{entity.OutboxEventStatusPending, entity.OutboxEventStatusPublishing}
+
+// FindOpenByAggregates lists the unfinished events of the given aggregates up
+// to maxID, including ones backing off or claimed elsewhere.
+func (r *OutboxRepository) FindOpenByAggregates(tx *gorm.DB, aggregateIDs []string, maxID uint64, events *[]entity.OutboxEvent) error {
+	if err := tx.Select("id", "aggregate_id").
+		Where("status IN ? AND aggregate_id IN ? AND id <= ?", openOutboxStatuses, aggregateIDs, maxID).
+		Order("id ASC").
+		Find(events).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to fetch open outbox events")
+		return err
+	}
+	return nil
+}
+
+func (r *OutboxRepository) PendingStats(tx *gorm.DB) (int64, *time.Time, error) {
+	var stats struct {
+		Total  int64
+		Oldest *time.Time
+	}
+	err := tx.Model(&entity.OutboxEvent{}).
+		Select("COUNT(*) AS total, MIN(created_at) AS oldest").
+		Where("status IN ?", openOutboxStatuses).
+		Scan(&stats).Error
+	if err != nil {
+		r.Log.WithError(err).Error("Failed to compute outbox pending stats")
+		return 0, nil, err
+	}
+	return stats.Total, stats.Oldest, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentAttemptRepository struct {
+	Repository[entity.PaymentAttempt]
+	Log *logrus.Logger
+}
+
+func NewPaymentAttemptRepository(log *logrus.Logger) *PaymentAttemptRepository {
+	return &PaymentAttemptRepository{
+		Log: log,
+	}
+}
+
+func (r *PaymentAttemptRepository) FindByGatewayIDForUpdate(tx *gorm.DB, gatewayID string, attempt *entity.PaymentAttempt) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("gateway_id = ?", gatewayID).First(attempt).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find payment attempt by gateway ID")
+		return err
+	}
+	return nil
+}
+
+func (r *PaymentAttemptRepository) FindOpenByInvoiceID(tx *gorm.DB, invoiceID uuid.UUID, method entity.PaymentAttemptMethod, channel string, now time.Time, attempt *entity.PaymentAttempt) error {
+	if err := tx.Where("invoice_id = ? AND method = ? AND channel = ?", invoiceID, method, channel).
+		Where("status = ?", entity.PaymentAttemptStatusPending).
+		Where("(expires_at IS NULL OR expires_at > ?)", now).
+		Order("created_at DESC").
+		First(attempt).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find open payment attempt by invoice ID")
+		return err
+	}
+	return nil
+}
+
+// CountClaimsByInvoiceID counts the attempts on the channel that are still
+// being created at the gateway, ignoring claims older than since.
+func (r *PaymentAttemptRepository) CountClaimsByInvoiceID(tx *gorm.DB, invoiceID uuid.UUID, method entity.PaymentAttemptMethod, channel string, since time.Time) (int64, error) {
+	var count int64
+	if err := tx.Model(&entity.PaymentAttempt{}).
+		Where("invoice_id = ? AND method = ? AND channel = ?", invoiceID, method, channel).
+		Where("status = ? AND created_at > ?", entity.PaymentAttemptStatusCreating, since).
+		Count(&count).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to count payment attempt claims by invoice ID")
+		return 0, err
+	}
+	return count, nil
+}
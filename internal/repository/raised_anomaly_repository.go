@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RaisedAnomalyRepository struct {
+	Repository[entity.RaisedAnomaly]
+	Log *logrus.Logger
+}
+
+func NewRaisedAnomalyRepository(log *logrus.Logger) *RaisedAnomalyRepository {
+	return &RaisedAnomalyRepository{
+		Log: log,
+	}
+}
+
+// CreateIfAbsent reports whether the anomaly was new for its reference.
+func (r *RaisedAnomalyRepository) CreateIfAbsent(tx *gorm.DB, anomaly *entity.RaisedAnomaly) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(anomaly)
+	if result.Error != nil {
+		r.Log.WithError(result.Error).Error("Failed to record raised anomaly")
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundRepository struct {
+	Repository[entity.Refund]
+	Log *logrus.Logger
+}
+
+func NewRefundRepository(log *logrus.Logger) *RefundRepository {
+	return &RefundRepository{
+		Log: log,
+	}
+}
+
+func (r *RefundRepository) FindByXenditRefundIDForUpdate(tx *gorm.DB, xenditRefundID string, refund *entity.Refund) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("xendit_refund_id = ?", xenditRefundID).First(refund).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find refund by Xendit refund ID")
+		return err
+	}
+	return nil
+}
+
+func (r *RefundRepository) FindByIDForUpdate(tx *gorm.DB, id uuid.UUID, refund *entity.Refund) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(refund).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find refund by ID")
+		return err
+	}
+	return nil
+}
+
+func (r *RefundRepository) FindAllByInvoiceID(tx *gorm.DB, invoiceID uuid.UUID, refunds *[]entity.Refund) error {
+	if err := tx.Where("invoice_id = ?", invoiceID).Order("created_at ASC").Find(refunds).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find refunds by invoice ID")
+		return err
+	}
+	return nil
+}
+
+func (r *RefundRepository) SumAmountByInvoiceID(tx *gorm.DB, invoiceID uuid.UUID, statuses ...entity.RefundStatus) (int64, error) {
+	var total int64
+	err := tx.Model(&entity.Refund{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("invoice_id = ? AND status IN ?", invoiceID, statuses).
+		Scan(&total).Error
+	if err != nil {
+		r.Log.WithError(err).Error("Failed to sum refund amount by invoice ID")
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type VoucherRedemptionRepository struct {
+	Repository[entity.VoucherRedemption]
+	Log *logrus.Logger
+}
+
+func NewVoucherRedemptionRepository(log *logrus.Logger) *VoucherRedemptionRepository {
+	return &VoucherRedemptionRepository{
+		Log: log,
+	}
+}
+
+// CountRedeemedByUser counts reserved uses too, so a user cannot exceed the
+// limit with checkouts running in parallel.
+func (r *VoucherRedemptionRepository) CountRedeemedByUser(tx *gorm.DB, voucherID, userID uuid.UUID) (int64, error) {
+	var total int64
+	if err := tx.Model(&entity.VoucherRedemption{}).
+		Where("voucher_id = ? AND user_id = ? AND status IN ?", voucherID, userID, []entity.VoucherRedemptionStatus{entity.VoucherRedemptionReserved, entity.VoucherRedemptionRedeemed}).
+		Count(&total).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to count voucher redemptions")
+		return 0, err
+	}
+	return total, nil
+}
+
+// UpdateStatus moves a redemption on only if it is still in status from, and
+// reports whether it did.
+func (r *VoucherRedemptionRepository) UpdateStatus(tx *gorm.DB, id uuid.UUID, from, to entity.VoucherRedemptionStatus, values map[string]any) (bool, error) {
+	updates := map[string]any{"status": to}
+	for column, value := range values {
+		updates[column] = value
+	}
+
+	result := tx.Model(&entity.VoucherRedemption{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if result.Error != nil {
+		r.Log.WithError(result.Error).Error("Failed to update voucher redemption status")
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *VoucherRedemptionRepository) FindExpiredReservations(tx *gorm.DB, now time.Time, limit int, redemptions *[]entity.VoucherRedemption) error {
+	if err := tx.Where("status = ? AND reserved_until < ?", entity.VoucherRedemptionReserved, now).
+		Order("reserved_until ASC").
+		Limit(limit).
+		Find(redemptions).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find expired voucher reservations")
+		return err
+	}
+	return nil
+}
+
+func (r *VoucherRedemptionRepository) FindRedeemedByInvoiceID(tx *gorm.DB, invoiceID uuid.UUID, redemption *entity.VoucherRedemption) error {
+	return tx.Where("invoice_id = ? AND status = ?", invoiceID, entity.VoucherRedemptionRedeemed).Take(redemption).Error
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository struct {
+	Repository[entity.Voucher]
+	Log *logrus.Logger
+}
+
+func NewVoucherRepository(log *logrus.Logger) *VoucherRepository {
+	return &VoucherRepository{
+		Log: log,
+	}
+}
+
+func (r *VoucherRepository) FindByCode(tx *gorm.DB, code string, voucher *entity.Voucher) error {
+	if err := tx.Where("code = ?", code).Take(voucher).Error; err != nil {
+		r.Log.WithError(err).Warnf("Failed to find voucher %s", code)
+		return err
+	}
+	return nil
+}
+
+func (r *VoucherRepository) FindByCodeForUpdate(tx *gorm.DB, code string, voucher *entity.Voucher) error {
+	return r.FindByCode(tx.Clauses(clause.Locking{Strength: "UPDATE"}), code, voucher)
+}
+
+func (r *VoucherRepository) FindAllOrdered(tx *gorm.DB, vouchers *[]entity.Voucher) error {
+	if err := tx.Order("created_at DESC").Find(vouchers).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find vouchers")
+		return err
+	}
+	return nil
+}
+
+func (r *VoucherRepository) AddUsage(tx *gorm.DB, id any, delta int) error {
+	return tx.Model(&entity.Voucher{}).Where("id = ?", id).
+		UpdateColumn("used_count", gorm.Expr("GREATEST(used_count + ?, 0)", delta)).Error
+}
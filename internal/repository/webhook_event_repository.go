@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"golectro-payment/internal/entity"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookEventRepository struct {
+	Repository[entity.WebhookEvent]
+	Log *logrus.Logger
+}
+
+func NewWebhookEventRepository(log *logrus.Logger) *WebhookEventRepository {
+	return &WebhookEventRepository{
+		Log: log,
+	}
+}
+
+func (r *WebhookEventRepository) CreateIfNotExists(tx *gorm.DB, event *entity.WebhookEvent) (bool, error) {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if result.Error != nil {
+		r.Log.WithError(result.Error).Error("Failed to store webhook event")
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *WebhookEventRepository) FindByProviderEventID(tx *gorm.DB, provider, eventID string, event *entity.WebhookEvent) error {
+	if err := tx.Where("provider = ? AND event_id = ?", provider, eventID).First(event).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find webhook event by provider event ID")
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookEventRepository) IncrementDeliveries(tx *gorm.DB, event *entity.WebhookEvent) error {
+	if err := tx.Model(event).UpdateColumn("deliveries", gorm.Expr("deliveries + 1")).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to increment webhook event deliveries")
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookEventRepository) FindAllByEventIDs(tx *gorm.DB, provider string, eventIDs []string, events *[]entity.WebhookEvent) error {
+	if err := tx.Where("provider = ? AND event_id IN ?", provider, eventIDs).Order("received_at ASC").Find(events).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find webhook events by event IDs")
+		return err
+	}
+	return nil
+}
+
+func (r *WebhookEventRepository) FindAllByReceivedAtRange(tx *gorm.DB, provider string, from, to time.Time, events *[]entity.WebhookEvent) error {
+	if err := tx.Where("provider = ? AND received_at BETWEEN ? AND ?", provider, from, to).Order("received_at ASC").Find(events).Error; err != nil {
+		r.Log.WithError(err).Error("Failed to find webhook events by received time range")
+		return err
+	}
+	return nil
+}
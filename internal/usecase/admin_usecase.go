@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrGatewayInvoiceNotFound = utils.WrapMessageAsError(constants.GatewayInvoiceNotFound)
+	ErrGatewayAmountMismatch  = utils.WrapMessageAsError(constants.GatewayAmountMismatch)
+)
+
+type AdminUseCase struct {
+	DB                             *gorm.DB
+	Log                            *logrus.Logger
+	InvoiceRepository              *repository.InvoiceRepository
+	InvoiceStatusHistoryRepository *repository.InvoiceStatusHistoryRepository
+	RefundRepository               *repository.RefundRepository
+	PaymentUseCase                 *PaymentUseCase
+	PaymentGateway                 gateway.PaymentGateway
+}
+
+func NewAdminUsecase(db *gorm.DB, log *logrus.Logger, invoiceRepository *repository.InvoiceRepository, invoiceStatusHistoryRepository *repository.InvoiceStatusHistoryRepository, refundRepository *repository.RefundRepository, paymentUseCase *PaymentUseCase, paymentGateway gateway.PaymentGateway) *AdminUseCase {
+	return &AdminUseCase{
+		DB:                             db,
+		Log:                            log,
+		InvoiceRepository:              invoiceRepository,
+		InvoiceStatusHistoryRepository: invoiceStatusHistoryRepository,
+		RefundRepository:               refundRepository,
+		PaymentUseCase:                 paymentUseCase,
+		PaymentGateway:                 paymentGateway,
+	}
+}
+
+func (uc *AdminUseCase) GetInvoiceDetail(ctx context.Context, invoiceID uuid.UUID) (*model.AdminInvoiceResponse, error) {
+	tx := uc.DB.WithContext(ctx)
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindById(uc.InvoiceRepository.WithItems(tx), &invoice, invoiceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		uc.Log.WithError(err).Error("Failed to retrieve invoice")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	var histories []entity.InvoiceStatusHistory
+	if err := uc.InvoiceStatusHistoryRepository.FindAllByInvoiceID(tx, invoice.ID, &histories); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	var refunds []entity.Refund
+	if err := uc.RefundRepository.FindAllByInvoiceID(tx, invoice.ID, &refunds); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	refunded, err := uc.RefundRepository.SumAmountByInvoiceID(tx, invoice.ID, entity.RefundStatusSucceeded)
+	if err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	response := &model.AdminInvoiceResponse{
+		InvoiceResponse: *toInvoiceResponse(&invoice),
+		UserID:          invoice.UserID.String(),
+		PaymentMethod:   invoice.PaymentMethod,
+		PaymentChannel:  invoice.PaymentChannel,
+		RefundedAmount:  money.New(refunded, invoice.Amount.Currency),
+		CreatedAt:       invoice.CreatedAt,
+		UpdatedAt:       invoice.UpdatedAt,
+		History:         make([]*model.InvoiceStatusHistoryResponse, 0, len(histories)),
+		Refunds:         make([]*model.RefundResponse, 0, len(refunds)),
+	}
+
+	for _, history := range histories {
+		response.History = append(response.History, &model.InvoiceStatusHistoryResponse{
+			FromStatus: string(history.FromStatus),
+			ToStatus:   string(history.ToStatus),
+			Source:     history.Source,
+			CreatedAt:  history.CreatedAt,
+		})
+	}
+
+	for _, refund := range refunds {
+		response.Refunds = append(response.Refunds, toRefundResponse(&refund, &invoice, response.RefundedAmount))
+	}
+
+	return response, nil
+}
+
+// SyncInvoice pulls the gateway's view of the invoice and applies it through
+// the callback path, so forced syncs leave the same trail as real callbacks.
+func (uc *AdminUseCase) SyncInvoice(ctx context.Context, invoiceID uuid.UUID) (*model.AdminInvoiceResponse, error) {
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindById(uc.DB.WithContext(ctx), &invoice, invoiceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	provider, err := uc.PaymentGateway.GetInvoice(ctx, invoice.XenditID)
+	if err != nil {
+		if errors.Is(err, gateway.ErrInvoiceNotFound) {
+			return nil, ErrGatewayInvoiceNotFound
+		}
+		uc.Log.WithError(err).Errorf("Failed to fetch invoice %s from payment gateway", invoice.ID)
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if provider.Amount != invoice.Amount {
+		uc.Log.Warnf("Invoice %s amount %s differs from gateway amount %s", invoice.ID, invoice.Amount, provider.Amount)
+		return nil, ErrGatewayAmountMismatch
+	}
+
+	if provider.Status != string(invoice.Status) {
+		if _, err := uc.PaymentUseCase.ApplyGatewayInvoice(ctx, provider); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.GetInvoiceDetail(ctx, invoiceID)
+}
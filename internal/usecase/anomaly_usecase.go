@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+const (
+	AnomalyUnderpayment     = "UNDERPAYMENT"
+	AnomalyOverpayment      = "OVERPAYMENT"
+	AnomalyCurrencyMismatch = "CURRENCY_MISMATCH"
+	AnomalyDuplicatePayment = "DUPLICATE_PAYMENT"
+
+	EventPaymentAnomaly = "payment.anomaly"
+)
+
+type AnomalyUseCase struct {
+	Log                     *logrus.Logger
+	Collection              *mongo.Collection
+	RaisedAnomalyRepository *repository.RaisedAnomalyRepository
+}
+
+func NewAnomalyUsecase(log *logrus.Logger, mongoDB *mongo.Database, raisedAnomalyRepository *repository.RaisedAnomalyRepository) *AnomalyUseCase {
+	return &AnomalyUseCase{
+		Log:                     log,
+		Collection:              mongoDB.Collection("payment_anomalies"),
+		RaisedAnomalyRepository: raisedAnomalyRepository,
+	}
+}
+
+// Claim marks the anomaly as raised for the payment identified by reference
+// and reports whether it is new. Callers only alert on a new anomaly, so a
+// retried or replayed callback does not alert twice.
+func (uc *AnomalyUseCase) Claim(tx *gorm.DB, reference string, anomaly *model.PaymentAnomaly) (bool, error) {
+	invoiceID, err := uuid.Parse(anomaly.InvoiceID)
+	if err != nil {
+		return false, err
+	}
+	return uc.RaisedAnomalyRepository.CreateIfAbsent(tx, &entity.RaisedAnomaly{
+		ID:        uuid.New(),
+		InvoiceID: invoiceID,
+		Reference: reference,
+		Type:      anomaly.Type,
+	})
+}
+
+// Record stores the anomaly for finance review. The Kafka alert travels
+// through the outbox, so a failure here is logged rather than returned.
+func (uc *AnomalyUseCase) Record(ctx context.Context, anomaly *model.PaymentAnomaly) {
+	if _, err := uc.Collection.InsertOne(ctx, anomaly); err != nil {
+		uc.Log.WithError(err).Errorf("Failed to record %s anomaly for invoice %s", anomaly.Type, anomaly.InvoiceID)
+	}
+}
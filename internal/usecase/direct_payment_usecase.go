@@ -0,0 +1,601 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	qrisChannel           = "QRIS"
+	defaultQRCodeDuration = 15 * time.Minute
+
+	// attemptClaimTimeout bounds how long a claim left behind by a request
+	// that died during the gateway call blocks new attempts.
+	attemptClaimTimeout = time.Minute
+)
+
+var attemptSources = map[entity.PaymentAttemptMethod]string{
+	entity.PaymentAttemptMethodVirtualAccount: "xendit_virtual_account",
+	entity.PaymentAttemptMethodQRIS:           "xendit_qr_code",
+}
+
+// attemptPaymentMethods match the payment methods Xendit reports on invoices
+// paid through the hosted page.
+var attemptPaymentMethods = map[entity.PaymentAttemptMethod]string{
+	entity.PaymentAttemptMethodVirtualAccount: "BANK_TRANSFER",
+	entity.PaymentAttemptMethodQRIS:           "QR_CODE",
+}
+
+var (
+	ErrVirtualAccountBankNotSupported     = utils.WrapMessageAsError(constants.VirtualAccountBankNotSupported)
+	ErrVirtualAccountCurrencyNotSupported = utils.WrapMessageAsError(constants.VirtualAccountCurrencyNotSupported)
+	ErrQRCodeCurrencyNotSupported         = utils.WrapMessageAsError(constants.QRCodeCurrencyNotSupported)
+	ErrInvoiceNotPayable                  = utils.WrapMessageAsError(constants.InvoiceNotPayable)
+	ErrPaymentAttemptNotFound             = utils.WrapMessageAsError(constants.PaymentAttemptNotFound)
+	ErrPaymentAttemptInProgress           = utils.WrapMessageAsError(constants.PaymentAttemptInProgress)
+)
+
+// DirectPaymentUseCase issues payment instruments that the payer uses
+// without the hosted invoice page. Each one is recorded as a payment attempt
+// of the invoice it settles.
+type DirectPaymentUseCase struct {
+	DB                       *gorm.DB
+	Log                      *logrus.Logger
+	Validate                 *validator.Validate
+	Viper                    *viper.Viper
+	Branding                 *model.Branding
+	Redis                    *redis.Client
+	InvoiceRepository        *repository.InvoiceRepository
+	PaymentAttemptRepository *repository.PaymentAttemptRepository
+	InvoiceStatusUseCase     *InvoiceStatusUseCase
+	OutboxUseCase            *OutboxUseCase
+	AnomalyUseCase           *AnomalyUseCase
+	PaymentGateway           gateway.PaymentGateway
+}
+
+func NewDirectPaymentUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, viper *viper.Viper, branding *model.Branding, redis *redis.Client, invoiceRepository *repository.InvoiceRepository, paymentAttemptRepository *repository.PaymentAttemptRepository, invoiceStatusUseCase *InvoiceStatusUseCase, outboxUseCase *OutboxUseCase, anomalyUseCase *AnomalyUseCase, paymentGateway gateway.PaymentGateway) *DirectPaymentUseCase {
+	return &DirectPaymentUseCase{
+		DB:                       db,
+		Log:                      log,
+		Validate:                 validate,
+		Viper:                    viper,
+		Branding:                 branding,
+		Redis:                    redis,
+		InvoiceRepository:        invoiceRepository,
+		PaymentAttemptRepository: paymentAttemptRepository,
+		InvoiceStatusUseCase:     invoiceStatusUseCase,
+		OutboxUseCase:            outboxUseCase,
+		AnomalyUseCase:           anomalyUseCase,
+		PaymentGateway:           paymentGateway,
+	}
+}
+
+// CreateVirtualAccount returns the open virtual account of the invoice for the
+// bank if there is one, so the payer keeps the number they were given.
+// Otherwise it opens a closed one for the exact invoice amount that expires
+// together with the invoice.
+func (uc *DirectPaymentUseCase) CreateVirtualAccount(ctx context.Context, userID, invoiceID uuid.UUID, request *model.CreateVirtualAccountRequest) (*model.VirtualAccountResponse, error) {
+	request.BankCode = strings.ToUpper(strings.TrimSpace(request.BankCode))
+	if err := uc.Validate.Struct(request); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+	if !gateway.IsVirtualAccountBank(request.BankCode) {
+		return nil, ErrVirtualAccountBankNotSupported
+	}
+
+	now := time.Now()
+	invoice, attempt, err := uc.claimAttempt(ctx, userID, invoiceID, entity.PaymentAttemptMethodVirtualAccount, request.BankCode, now, func(invoice *entity.Invoice) error {
+		if invoice.Amount.Currency != money.DefaultCurrency {
+			return ErrVirtualAccountCurrencyNotSupported
+		}
+		return nil
+	})
+	if err != nil || attempt.Status != entity.PaymentAttemptStatusCreating {
+		return toVirtualAccountResponse(attempt), err
+	}
+
+	va, err := uc.PaymentGateway.CreateVirtualAccount(ctx, &gateway.CreateVirtualAccountParams{
+		ExternalID:  attempt.ID.String(),
+		BankCode:    request.BankCode,
+		Name:        uc.accountName(invoice),
+		Amount:      invoice.Amount,
+		Description: invoice.Description,
+		ExpiresAt:   invoice.ExpiresAt,
+	})
+	if err != nil {
+		uc.Log.WithError(err).Error("Failed to create virtual account in payment gateway")
+		uc.releaseAttempt(ctx, attempt)
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	attempt.GatewayID = va.ID
+	attempt.AccountNumber = va.AccountNumber
+	if va.ExpiresAt != nil {
+		attempt.ExpiresAt = va.ExpiresAt
+	}
+
+	if err := uc.saveAttempt(ctx, attempt); err != nil {
+		return nil, err
+	}
+	return toVirtualAccountResponse(attempt), nil
+}
+
+// CreateQRCode returns the open QRIS code of the invoice if there is one, or
+// else a new dynamic code for the invoice amount. Codes are short-lived and
+// never outlive the invoice.
+func (uc *DirectPaymentUseCase) CreateQRCode(ctx context.Context, userID, invoiceID uuid.UUID) (*model.QRCodeResponse, error) {
+	now := time.Now()
+	invoice, attempt, err := uc.claimAttempt(ctx, userID, invoiceID, entity.PaymentAttemptMethodQRIS, qrisChannel, now, func(invoice *entity.Invoice) error {
+		if !gateway.SupportsCurrency(qrisChannel, invoice.Amount.Currency) {
+			return ErrQRCodeCurrencyNotSupported
+		}
+		return nil
+	})
+	if err != nil || attempt.Status != entity.PaymentAttemptStatusCreating {
+		return toQRCodeResponse(attempt), err
+	}
+
+	expiresAt := now.Add(uc.qrCodeDuration())
+	if invoice.ExpiresAt != nil && invoice.ExpiresAt.Before(expiresAt) {
+		expiresAt = *invoice.ExpiresAt
+	}
+
+	qr, err := uc.PaymentGateway.CreateQRCode(ctx, &gateway.CreateQRCodeParams{
+		ReferenceID: attempt.ID.String(),
+		Amount:      invoice.Amount,
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		uc.Log.WithError(err).Error("Failed to create QR code in payment gateway")
+		uc.releaseAttempt(ctx, attempt)
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	attempt.GatewayID = qr.ID
+	attempt.QRString = qr.QRString
+	attempt.ExpiresAt = &expiresAt
+	if qr.ExpiresAt != nil {
+		attempt.ExpiresAt = qr.ExpiresAt
+	}
+
+	if err := uc.saveAttempt(ctx, attempt); err != nil {
+		return nil, err
+	}
+	return toQRCodeResponse(attempt), nil
+}
+
+// RenderQRCode draws the invoice's open QRIS code as a PNG. Images are cached
+// until the code expires, since a kiosk polls the same code repeatedly.
+func (uc *DirectPaymentUseCase) RenderQRCode(ctx context.Context, userID, invoiceID uuid.UUID, size int) ([]byte, error) {
+	tx := uc.DB.WithContext(ctx)
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindOne(tx, &invoice, "id = ? AND user_id = ?", invoiceID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	now := time.Now()
+	attempt, err := uc.findOpenAttempt(tx, invoice.ID, entity.PaymentAttemptMethodQRIS, qrisChannel, now)
+	if err != nil {
+		return nil, err
+	}
+	if attempt == nil {
+		return nil, ErrPaymentAttemptNotFound
+	}
+
+	size = uc.qrCodeSize(size)
+	key := fmt.Sprintf("payment:qris:%s:%d", attempt.ID, size)
+	if cached, err := uc.Redis.Get(ctx, key).Bytes(); err == nil {
+		return cached, nil
+	} else if !errors.Is(err, redis.Nil) {
+		uc.Log.WithError(err).Warn("Failed to read cached QR code")
+	}
+
+	image, err := qrcode.Encode(attempt.QRString, qrcode.Medium, size)
+	if err != nil {
+		uc.Log.WithError(err).Errorf("Failed to render QR code for invoice %s", invoice.ID)
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	ttl := defaultQRCodeDuration
+	if attempt.ExpiresAt != nil {
+		ttl = attempt.ExpiresAt.Sub(now)
+	}
+	if err := uc.Redis.Set(ctx, key, image, ttl).Err(); err != nil {
+		uc.Log.WithError(err).Warn("Failed to cache QR code")
+	}
+
+	return image, nil
+}
+
+// HandleVirtualAccountCallback settles the invoice a virtual account belongs
+// to.
+func (uc *DirectPaymentUseCase) HandleVirtualAccountCallback(ctx context.Context, callback *model.XenditVirtualAccountCallback) (*model.InvoiceResponse, error) {
+	if err := uc.Validate.Struct(callback); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+
+	payment := &attemptPayment{
+		GatewayID:  callback.CallbackVirtualAccountID,
+		ExternalID: callback.ExternalID,
+		PaymentID:  callback.PaymentID,
+		Amount:     callback.Amount,
+		PaidAt:     callback.TransactionTimestamp,
+	}
+	return uc.settleAttempt(ctx, payment)
+}
+
+// HandleQRCodeCallback settles the invoice a QRIS code belongs to. Only
+// successful payments are applied; other events are acknowledged unchanged.
+func (uc *DirectPaymentUseCase) HandleQRCodeCallback(ctx context.Context, callback *model.XenditQRCodeCallback) (*model.InvoiceResponse, error) {
+	if err := uc.Validate.Struct(callback); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+
+	if callback.Event != "qr.payment" || callback.Data.Status != "SUCCEEDED" {
+		uc.Log.Infof("Ignoring QR code event %s with status %s for %s", callback.Event, callback.Data.Status, callback.Data.QRID)
+		return nil, nil
+	}
+
+	payment := &attemptPayment{
+		GatewayID:  callback.Data.QRID,
+		ExternalID: callback.Data.ReferenceID,
+		PaymentID:  callback.Data.ID,
+		Amount:     callback.Data.Amount,
+		PaidAt:     callback.Data.Created,
+	}
+	return uc.settleAttempt(ctx, payment)
+}
+
+type attemptPayment struct {
+	GatewayID  string
+	ExternalID string
+	PaymentID  string
+	Amount     float64
+	PaidAt     *time.Time
+}
+
+// settleAttempt applies a payment received on an attempt to its invoice. A
+// payment that reaches an invoice which is no longer pending is still
+// recorded on the attempt and flagged for finance to refund.
+func (uc *DirectPaymentUseCase) settleAttempt(ctx context.Context, payment *attemptPayment) (*model.InvoiceResponse, error) {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	attempt := new(entity.PaymentAttempt)
+	if err := uc.PaymentAttemptRepository.FindByGatewayIDForUpdate(tx, payment.GatewayID, attempt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentAttemptNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if attempt.ID.String() != payment.ExternalID {
+		uc.Log.Errorf("Callback reference %s does not match payment attempt %s", payment.ExternalID, attempt.ID)
+		return nil, utils.WrapMessageAsError(constants.InvalidRequestData)
+	}
+
+	alreadyPaid := attempt.Status == entity.PaymentAttemptStatusPaid
+	if !alreadyPaid {
+		paidAt := payment.PaidAt
+		if paidAt == nil {
+			now := time.Now()
+			paidAt = &now
+		}
+		attempt.Status = entity.PaymentAttemptStatusPaid
+		attempt.PaidAt = paidAt
+		attempt.GatewayPaymentID = payment.PaymentID
+		if err := uc.PaymentAttemptRepository.Update(tx, attempt); err != nil {
+			uc.Log.WithError(err).Error("Failed to update payment attempt")
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindByIDForUpdate(uc.InvoiceRepository.WithItems(tx), attempt.InvoiceID, &invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if alreadyPaid {
+		return toInvoiceResponse(&invoice), nil
+	}
+
+	// Virtual accounts and QRIS only settle IDR, which the attempt was opened in.
+	received := money.FromMajor(payment.Amount, attempt.Amount.Currency)
+	source := attemptSources[attempt.Method]
+
+	previous := invoice.Status
+	changed := false
+	var anomaly *model.PaymentAnomaly
+	if previous == entity.InvoiceStatusPending {
+		next := entity.InvoiceStatusPaid
+		switch {
+		case received.Value < invoice.Amount.Value:
+			next = entity.InvoiceStatusUnderpaid
+			anomaly = newPaymentAnomaly(AnomalyUnderpayment, &invoice, received)
+		case received.Value > invoice.Amount.Value:
+			anomaly = newPaymentAnomaly(AnomalyOverpayment, &invoice, received)
+		}
+
+		invoice.PaidAmount = received
+		invoice.PaidAt = attempt.PaidAt
+		invoice.PaymentMethod = attemptPaymentMethods[attempt.Method]
+		invoice.PaymentChannel = attempt.Channel
+
+		var err error
+		if changed, err = uc.InvoiceStatusUseCase.Transition(tx, &invoice, next, source); err != nil {
+			return nil, err
+		}
+		if changed {
+			if err := uc.InvoiceRepository.UpdateInvoice(tx, invoice.OrderID, invoice.XenditID, &invoice); err != nil {
+				uc.Log.WithError(err).Error("Failed to update invoice")
+				return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+			}
+		}
+	} else {
+		anomaly = newPaymentAnomaly(AnomalyDuplicatePayment, &invoice, received)
+	}
+
+	response := toInvoiceResponse(&invoice)
+
+	if changed {
+		if err := uc.OutboxUseCase.Enqueue(tx, response.OrderID, EventInvoiceUpdated, response); err != nil {
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	if anomaly != nil {
+		anomaly.Source = source
+		anomaly.InvoiceStatus = string(invoice.Status)
+		uc.Log.Warnf("%s on invoice %s: expected %s, received %s", anomaly.Type, invoice.ID, anomaly.Expected, anomaly.Received)
+		if err := uc.OutboxUseCase.Enqueue(tx, response.OrderID, EventPaymentAnomaly, anomaly); err != nil {
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if changed {
+		uc.InvoiceStatusUseCase.Publish(ctx, &invoice, previous, source)
+		uc.closeHostedInvoice(ctx, &invoice)
+	}
+	if anomaly != nil {
+		uc.AnomalyUseCase.Record(ctx, anomaly)
+	}
+
+	return response, nil
+}
+
+// findPayableInvoice locks the user's invoice and checks that it can still
+// be paid through the channel.
+func (uc *DirectPaymentUseCase) findPayableInvoice(tx *gorm.DB, userID, invoiceID uuid.UUID, channel string, now time.Time) (*entity.Invoice, error) {
+	invoice := new(entity.Invoice)
+	if err := uc.InvoiceRepository.FindByIDAndUserIDForUpdate(tx, invoiceID, userID, invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if invoice.Status != entity.InvoiceStatusPending || (invoice.ExpiresAt != nil && !invoice.ExpiresAt.After(now)) {
+		uc.Log.Warnf("%s payment rejected for invoice %s in status %s", channel, invoice.ID, invoice.Status)
+		return nil, ErrInvoiceNotPayable
+	}
+	if len(invoice.AllowedMethods) > 0 && !slices.Contains(invoice.AllowedMethods, channel) {
+		return nil, ErrPaymentMethodNotAllowed
+	}
+	return invoice, nil
+}
+
+// findOpenAttempt returns nil without an error when the invoice has no
+// attempt on the channel that can still be paid.
+func (uc *DirectPaymentUseCase) findOpenAttempt(tx *gorm.DB, invoiceID uuid.UUID, method entity.PaymentAttemptMethod, channel string, now time.Time) (*entity.PaymentAttempt, error) {
+	attempt := new(entity.PaymentAttempt)
+	if err := uc.PaymentAttemptRepository.FindOpenByInvoiceID(tx, invoiceID, method, channel, now, attempt); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	return attempt, nil
+}
+
+// claimAttempt checks in a short transaction that the invoice can be paid
+// through the channel and returns its open attempt there, if any. Otherwise
+// it records a CREATING attempt that keeps concurrent requests from opening a
+// second instrument while the gateway is called outside the transaction.
+func (uc *DirectPaymentUseCase) claimAttempt(ctx context.Context, userID, invoiceID uuid.UUID, method entity.PaymentAttemptMethod, channel string, now time.Time, check func(*entity.Invoice) error) (*entity.Invoice, *entity.PaymentAttempt, error) {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	invoice, err := uc.findPayableInvoice(tx, userID, invoiceID, channel, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := check(invoice); err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := uc.findOpenAttempt(tx, invoice.ID, method, channel, now)
+	if err != nil || existing != nil {
+		return invoice, existing, err
+	}
+
+	claims, err := uc.PaymentAttemptRepository.CountClaimsByInvoiceID(tx, invoice.ID, method, channel, now.Add(-attemptClaimTimeout))
+	if err != nil {
+		return nil, nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if claims > 0 {
+		return nil, nil, ErrPaymentAttemptInProgress
+	}
+
+	attemptID := uuid.New()
+	attempt := &entity.PaymentAttempt{
+		ID:        attemptID,
+		InvoiceID: invoice.ID,
+		Method:    method,
+		Channel:   channel,
+		// The gateway ID is unique, so the claim holds its own ID until
+		// the gateway assigns one.
+		GatewayID: attemptID.String(),
+		Amount:    invoice.Amount,
+		Status:    entity.PaymentAttemptStatusCreating,
+		ExpiresAt: invoice.ExpiresAt,
+	}
+	if err := uc.PaymentAttemptRepository.Create(tx, attempt); err != nil {
+		uc.Log.WithError(err).Error("Failed to create payment attempt")
+		return nil, nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	return invoice, attempt, nil
+}
+
+// saveAttempt opens the claimed attempt with what the gateway returned.
+func (uc *DirectPaymentUseCase) saveAttempt(ctx context.Context, attempt *entity.PaymentAttempt) error {
+	attempt.Status = entity.PaymentAttemptStatusPending
+	if err := uc.PaymentAttemptRepository.Update(uc.DB.WithContext(ctx), attempt); err != nil {
+		uc.Log.WithError(err).Error("Failed to update payment attempt")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.Log.WithFields(logrus.Fields{
+		"invoice_id": attempt.InvoiceID,
+		"method":     attempt.Method,
+		"channel":    attempt.Channel,
+		"gateway_id": attempt.GatewayID,
+	}).Info("Payment attempt created")
+	return nil
+}
+
+// releaseAttempt marks the claim failed so the payer can retry right away.
+func (uc *DirectPaymentUseCase) releaseAttempt(ctx context.Context, attempt *entity.PaymentAttempt) {
+	attempt.Status = entity.PaymentAttemptStatusFailed
+	if err := uc.PaymentAttemptRepository.Update(uc.DB.WithContext(context.WithoutCancel(ctx)), attempt); err != nil {
+		uc.Log.WithError(err).Warnf("Failed to release payment attempt %s", attempt.ID)
+	}
+}
+
+// closeHostedInvoice expires the hosted invoice once a direct payment has
+// paid it, so the payer cannot pay the same order twice.
+func (uc *DirectPaymentUseCase) closeHostedInvoice(ctx context.Context, invoice *entity.Invoice) {
+	if _, err := uc.PaymentGateway.ExpireInvoice(ctx, invoice.XenditID); err != nil {
+		uc.Log.WithError(err).Warnf("Failed to expire hosted invoice %s after direct payment", invoice.XenditID)
+	}
+}
+
+func (uc *DirectPaymentUseCase) qrCodeDuration() time.Duration {
+	if duration := uc.Viper.GetDuration("QRIS_EXPIRY"); duration > 0 {
+		return duration
+	}
+	return defaultQRCodeDuration
+}
+
+func (uc *DirectPaymentUseCase) qrCodeSize(size int) int {
+	if size <= 0 {
+		size = uc.Viper.GetInt("QRIS_PNG_SIZE")
+	}
+	if size <= 0 {
+		return 256
+	}
+	return min(max(size, 128), 1024)
+}
+
+// accountName is what the payer's bank shows as the virtual account holder.
+func (uc *DirectPaymentUseCase) accountName(invoice *entity.Invoice) string {
+	if invoice.CustomerName != "" {
+		return invoice.CustomerName
+	}
+	return uc.Branding.Name
+}
+
+// latestAttempt picks the attempt of the method that paid the invoice, or
+// else the most recently opened one that can still be paid.
+func latestAttempt(attempts []entity.PaymentAttempt, method entity.PaymentAttemptMethod) *entity.PaymentAttempt {
+	now := time.Now()
+	var open *entity.PaymentAttempt
+	for i := range attempts {
+		attempt := &attempts[i]
+		if attempt.Method != method {
+			continue
+		}
+		if attempt.Status == entity.PaymentAttemptStatusPaid {
+			return attempt
+		}
+		if attempt.IsOpenAt(now) {
+			open = attempt
+		}
+	}
+	return open
+}
+
+func toVirtualAccountResponse(attempt *entity.PaymentAttempt) *model.VirtualAccountResponse {
+	if attempt == nil {
+		return nil
+	}
+	response := &model.VirtualAccountResponse{
+		ID:            attempt.ID.String(),
+		Bank:          attempt.Channel,
+		AccountNumber: attempt.AccountNumber,
+		Amount:        attempt.Amount,
+		Status:        string(attempt.Status),
+		ExpiresAt:     attempt.ExpiresAt,
+		PaidAt:        attempt.PaidAt,
+	}
+	if attempt.Status == entity.PaymentAttemptStatusPending {
+		response.Instructions = make(map[string][]string, len(constants.VirtualAccountInstructions))
+		for language, steps := range constants.VirtualAccountInstructions {
+			for _, step := range steps {
+				response.Instructions[language] = append(response.Instructions[language], fmt.Sprintf(step, attempt.Channel, attempt.AccountNumber, attempt.Amount.Format()))
+			}
+		}
+	}
+	return response
+}
+
+func toQRCodeResponse(attempt *entity.PaymentAttempt) *model.QRCodeResponse {
+	if attempt == nil {
+		return nil
+	}
+	return &model.QRCodeResponse{
+		ID:        attempt.ID.String(),
+		QRString:  attempt.QRString,
+		Amount:    attempt.Amount,
+		Status:    string(attempt.Status),
+		ExpiresAt: attempt.ExpiresAt,
+		PaidAt:    attempt.PaidAt,
+	}
+}
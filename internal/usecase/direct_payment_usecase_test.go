@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateVirtualAccountReusesOpenAttempt(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.createInvoice(t, userID, "", 200000)
+	invoiceID := uuid.MustParse(created.ID)
+
+	first, err := env.DirectPaymentUseCase.CreateVirtualAccount(context.Background(), userID, invoiceID, &model.CreateVirtualAccountRequest{BankCode: "bca"})
+	require.NoError(t, err)
+	assert.Equal(t, "BCA", first.Bank)
+	assert.Equal(t, string(entity.PaymentAttemptStatusPending), first.Status)
+	assert.NotEmpty(t, first.AccountNumber)
+
+	second, err := env.DirectPaymentUseCase.CreateVirtualAccount(context.Background(), userID, invoiceID, &model.CreateVirtualAccountRequest{BankCode: "BCA"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, first.AccountNumber, second.AccountNumber)
+
+	var attempt entity.PaymentAttempt
+	require.NoError(t, env.DB.Take(&attempt, "id = ?", first.ID).Error)
+	assert.NotEqual(t, attempt.ID.String(), attempt.GatewayID)
+}
+
+func TestCreateQRCodeWhileClaimed(t *testing.T) {
+	tests := []struct {
+		name    string
+		claimed time.Duration
+		err     error
+	}{
+		{"claim in progress", 0, ErrPaymentAttemptInProgress},
+		{"claim left behind", -2 * attemptClaimTimeout, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			userID := uuid.New()
+			created := env.createInvoice(t, userID, "", 200000)
+			invoiceID := uuid.MustParse(created.ID)
+
+			claimID := uuid.New()
+			require.NoError(t, env.DB.Create(&entity.PaymentAttempt{
+				ID:        claimID,
+				InvoiceID: invoiceID,
+				Method:    entity.PaymentAttemptMethodQRIS,
+				Channel:   qrisChannel,
+				GatewayID: claimID.String(),
+				Status:    entity.PaymentAttemptStatusCreating,
+				CreatedAt: time.Now().Add(tt.claimed),
+			}).Error)
+
+			response, err := env.DirectPaymentUseCase.CreateQRCode(context.Background(), userID, invoiceID)
+			assert.ErrorIs(t, err, tt.err)
+			if tt.err == nil {
+				require.NotNil(t, response)
+				assert.NotEqual(t, claimID.String(), response.ID)
+				assert.NotEmpty(t, response.QRString)
+			}
+		})
+	}
+}
+
+func TestHandleVirtualAccountCallback(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.createInvoice(t, userID, "", 200000)
+
+	va, err := env.DirectPaymentUseCase.CreateVirtualAccount(context.Background(), userID, uuid.MustParse(created.ID), &model.CreateVirtualAccountRequest{BankCode: "BNI"})
+	require.NoError(t, err)
+	var attempt entity.PaymentAttempt
+	require.NoError(t, env.DB.Take(&attempt, "id = ?", va.ID).Error)
+
+	callback := &model.XenditVirtualAccountCallback{
+		ID:                       uuid.NewString(),
+		PaymentID:                "payment-1",
+		CallbackVirtualAccountID: attempt.GatewayID,
+		ExternalID:               va.ID,
+		BankCode:                 "BNI",
+		Amount:                   200000,
+	}
+	for range 2 {
+		response, err := env.DirectPaymentUseCase.HandleVirtualAccountCallback(context.Background(), callback)
+		require.NoError(t, err)
+		assert.Equal(t, string(entity.InvoiceStatusPaid), response.Status)
+	}
+
+	invoice := env.findInvoice(t, created.ID)
+	assert.Equal(t, entity.InvoiceStatusPaid, invoice.Status)
+	assert.Equal(t, "BANK_TRANSFER", invoice.PaymentMethod)
+	assert.Equal(t, "BNI", invoice.PaymentChannel)
+	assert.Equal(t, 1, countOutboxEvents(t, env, EventInvoiceUpdated))
+	assert.Empty(t, outboxAnomalies(t, env))
+
+	_, err = env.DirectPaymentUseCase.CreateVirtualAccount(context.Background(), userID, invoice.ID, &model.CreateVirtualAccountRequest{BankCode: "BNI"})
+	assert.ErrorIs(t, err, ErrInvoiceNotPayable)
+}
+
+func TestHandleQRCodeCallback(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.createInvoice(t, userID, "", 200000)
+
+	qr, err := env.DirectPaymentUseCase.CreateQRCode(context.Background(), userID, uuid.MustParse(created.ID))
+	require.NoError(t, err)
+	var attempt entity.PaymentAttempt
+	require.NoError(t, env.DB.Take(&attempt, "id = ?", qr.ID).Error)
+
+	callback := func(status string, amount float64) *model.XenditQRCodeCallback {
+		return &model.XenditQRCodeCallback{
+			Event: "qr.payment",
+			Data: model.XenditQRCodeCallbackData{
+				ID:          uuid.NewString(),
+				QRID:        attempt.GatewayID,
+				ReferenceID: qr.ID,
+				Amount:      amount,
+				Status:      status,
+			},
+		}
+	}
+
+	response, err := env.DirectPaymentUseCase.HandleQRCodeCallback(context.Background(), callback("FAILED", 200000))
+	require.NoError(t, err)
+	assert.Nil(t, response)
+	assert.Equal(t, entity.InvoiceStatusPending, env.findInvoice(t, created.ID).Status)
+
+	response, err = env.DirectPaymentUseCase.HandleQRCodeCallback(context.Background(), callback("SUCCEEDED", 150000))
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.InvoiceStatusUnderpaid), response.Status)
+
+	anomalies := outboxAnomalies(t, env)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, AnomalyUnderpayment, anomalies[0].Type)
+	assert.Equal(t, "xendit_qr_code", anomalies[0].Source)
+}
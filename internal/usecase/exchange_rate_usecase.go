@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+var ErrExchangeRateNotFound = utils.WrapMessageAsError(constants.ExchangeRateNotFound)
+
+type ExchangeRateUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Validate               *validator.Validate
+	Redis                  *redis.Client
+	Viper                  *viper.Viper
+	ExchangeRateRepository *repository.ExchangeRateRepository
+}
+
+func NewExchangeRateUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, viper *viper.Viper, redis *redis.Client, exchangeRateRepository *repository.ExchangeRateRepository) *ExchangeRateUseCase {
+	return &ExchangeRateUseCase{
+		DB:                     db,
+		Log:                    log,
+		Validate:               validate,
+		Redis:                  redis,
+		Viper:                  viper,
+		ExchangeRateRepository: exchangeRateRepository,
+	}
+}
+
+// Convert prices an amount in another currency and returns the rate it used,
+// so callers can snapshot it. Converting to the same currency uses rate 1.
+func (uc *ExchangeRateUseCase) Convert(ctx context.Context, amount money.Money, currency string) (money.Money, *entity.ExchangeRate, error) {
+	currency = strings.ToUpper(currency)
+	if currency == amount.Currency {
+		return amount, &entity.ExchangeRate{BaseCurrency: currency, QuoteCurrency: currency, Rate: 1, UpdatedAt: time.Now()}, nil
+	}
+
+	rate, err := uc.GetRate(ctx, amount.Currency, currency)
+	if err != nil {
+		return money.Money{}, nil, err
+	}
+
+	return money.Convert(amount, rate.Rate, currency), rate, nil
+}
+
+func (uc *ExchangeRateUseCase) GetRate(ctx context.Context, base, quote string) (*entity.ExchangeRate, error) {
+	key := exchangeRateCacheKey(base, quote)
+
+	if cached, err := uc.Redis.Get(ctx, key).Bytes(); err == nil {
+		rate := new(entity.ExchangeRate)
+		if err := json.Unmarshal(cached, rate); err == nil {
+			return rate, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		uc.Log.WithError(err).Warn("Failed to read exchange rate from cache")
+	}
+
+	rate := new(entity.ExchangeRate)
+	if err := uc.ExchangeRateRepository.FindByPair(uc.DB.WithContext(ctx), base, quote, rate); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExchangeRateNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if payload, err := json.Marshal(rate); err == nil {
+		if err := uc.Redis.Set(ctx, key, payload, uc.cacheTTL()).Err(); err != nil {
+			uc.Log.WithError(err).Warn("Failed to cache exchange rate")
+		}
+	}
+
+	return rate, nil
+}
+
+func (uc *ExchangeRateUseCase) ListRates(ctx context.Context) ([]*model.ExchangeRateResponse, error) {
+	var rates []entity.ExchangeRate
+	if err := uc.ExchangeRateRepository.FindAllOrdered(uc.DB.WithContext(ctx), &rates); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	response := make([]*model.ExchangeRateResponse, 0, len(rates))
+	for i := range rates {
+		response = append(response, toExchangeRateResponse(&rates[i]))
+	}
+	return response, nil
+}
+
+func (uc *ExchangeRateUseCase) UpsertRate(ctx context.Context, updatedBy string, request *model.UpsertExchangeRateRequest) (*model.ExchangeRateResponse, error) {
+	request.BaseCurrency = strings.ToUpper(request.BaseCurrency)
+	request.QuoteCurrency = strings.ToUpper(request.QuoteCurrency)
+
+	if err := uc.Validate.Struct(request); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	rate := new(entity.ExchangeRate)
+	err := uc.ExchangeRateRepository.FindByPair(tx, request.BaseCurrency, request.QuoteCurrency, rate)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		rate.ID = uuid.New()
+		rate.BaseCurrency = request.BaseCurrency
+		rate.QuoteCurrency = request.QuoteCurrency
+	}
+	rate.Rate = request.Rate
+	rate.UpdatedBy = updatedBy
+
+	if err := uc.ExchangeRateRepository.Update(tx, rate); err != nil {
+		uc.Log.WithError(err).Error("Failed to save exchange rate")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.evict(ctx, rate.BaseCurrency, rate.QuoteCurrency)
+	uc.Log.Infof("Exchange rate %s/%s set to %v by %s", rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, updatedBy)
+
+	return toExchangeRateResponse(rate), nil
+}
+
+func (uc *ExchangeRateUseCase) DeleteRate(ctx context.Context, base, quote string) error {
+	base, quote = strings.ToUpper(base), strings.ToUpper(quote)
+	tx := uc.DB.WithContext(ctx)
+
+	rate := new(entity.ExchangeRate)
+	if err := uc.ExchangeRateRepository.FindByPair(tx, base, quote, rate); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrExchangeRateNotFound
+		}
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := uc.ExchangeRateRepository.Delete(tx, rate); err != nil {
+		uc.Log.WithError(err).Error("Failed to delete exchange rate")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.evict(ctx, base, quote)
+	return nil
+}
+
+func (uc *ExchangeRateUseCase) evict(ctx context.Context, base, quote string) {
+	if err := uc.Redis.Del(ctx, exchangeRateCacheKey(base, quote)).Err(); err != nil {
+		uc.Log.WithError(err).Warnf("Failed to evict cached exchange rate %s/%s", base, quote)
+	}
+}
+
+func (uc *ExchangeRateUseCase) cacheTTL() time.Duration {
+	if ttl := uc.Viper.GetDuration("FX_RATE_CACHE_TTL"); ttl > 0 {
+		return ttl
+	}
+	return 10 * time.Minute
+}
+
+func exchangeRateCacheKey(base, quote string) string {
+	return fmt.Sprintf("payment:fx-rate:%s:%s", base, quote)
+}
+
+func toExchangeRateResponse(rate *entity.ExchangeRate) *model.ExchangeRateResponse {
+	return &model.ExchangeRateResponse{
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		UpdatedBy:     rate.UpdatedBy,
+		UpdatedAt:     rate.UpdatedAt,
+	}
+}
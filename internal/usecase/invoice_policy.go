@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"golectro-payment/internal/gateway"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// InvoicePolicy holds the server-side limits on what a caller may ask of the
+// hosted invoice page.
+type InvoicePolicy struct {
+	DefaultDuration       time.Duration
+	MinDuration           time.Duration
+	MaxDuration           time.Duration
+	AllowedPaymentMethods []string
+	DefaultLocale         string
+}
+
+func NewInvoicePolicy(viper *viper.Viper) *InvoicePolicy {
+	policy := &InvoicePolicy{
+		DefaultDuration: viper.GetDuration("INVOICE_DEFAULT_DURATION"),
+		MinDuration:     viper.GetDuration("INVOICE_MIN_DURATION"),
+		MaxDuration:     viper.GetDuration("INVOICE_MAX_DURATION"),
+		DefaultLocale:   viper.GetString("INVOICE_DEFAULT_LOCALE"),
+	}
+	for _, method := range viper.GetStringSlice("INVOICE_ALLOWED_PAYMENT_METHODS") {
+		policy.AllowedPaymentMethods = append(policy.AllowedPaymentMethods, strings.ToUpper(strings.TrimSpace(method)))
+	}
+
+	if policy.MinDuration <= 0 {
+		policy.MinDuration = 15 * time.Minute
+	}
+	if policy.MaxDuration <= 0 {
+		policy.MaxDuration = 72 * time.Hour
+	}
+	if policy.DefaultDuration <= 0 {
+		policy.DefaultDuration = 24 * time.Hour
+	}
+	policy.DefaultDuration = min(max(policy.DefaultDuration, policy.MinDuration), policy.MaxDuration)
+
+	return policy
+}
+
+func (p *InvoicePolicy) Duration(seconds int64) (time.Duration, error) {
+	if seconds == 0 {
+		return p.DefaultDuration, nil
+	}
+
+	duration := time.Duration(seconds) * time.Second
+	if duration < p.MinDuration || duration > p.MaxDuration {
+		return 0, ErrInvoiceDurationOutOfRange
+	}
+	return duration, nil
+}
+
+// PaymentMethods narrows the channels offered on the invoice to those the
+// caller asked for. Every requested channel must accept the currency and be
+// allowed by INVOICE_ALLOWED_PAYMENT_METHODS. Without a request or a
+// server-side list, IDR invoices offer whatever the gateway account enables.
+func (p *InvoicePolicy) PaymentMethods(currency string, requested []string) ([]string, error) {
+	available, err := invoicePaymentMethods(currency)
+	if err != nil {
+		return nil, err
+	}
+	if len(requested) == 0 && len(p.AllowedPaymentMethods) == 0 {
+		return available, nil
+	}
+
+	if available == nil {
+		available = gateway.ChannelsForCurrency(currency)
+	}
+	if len(p.AllowedPaymentMethods) > 0 {
+		available = slices.DeleteFunc(slices.Clone(available), func(method string) bool {
+			return !slices.Contains(p.AllowedPaymentMethods, method)
+		})
+	}
+
+	if len(requested) == 0 {
+		if len(available) == 0 {
+			return nil, ErrPaymentMethodNotAllowed
+		}
+		return available, nil
+	}
+
+	methods := make([]string, 0, len(requested))
+	for _, method := range requested {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if !slices.Contains(available, method) {
+			return nil, ErrPaymentMethodNotAllowed
+		}
+		if !slices.Contains(methods, method) {
+			methods = append(methods, method)
+		}
+	}
+	slices.Sort(methods)
+	return methods, nil
+}
+
+func (p *InvoicePolicy) Locale(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return p.DefaultLocale
+}
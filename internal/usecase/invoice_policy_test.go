@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInvoicePolicyDefaults(t *testing.T) {
+	v := viper.New()
+	v.Set("INVOICE_DEFAULT_DURATION", "5m")
+	v.Set("INVOICE_ALLOWED_PAYMENT_METHODS", []string{" bca", "Credit_Card "})
+
+	policy := NewInvoicePolicy(v)
+
+	assert.Equal(t, 15*time.Minute, policy.MinDuration)
+	assert.Equal(t, 72*time.Hour, policy.MaxDuration)
+	assert.Equal(t, 15*time.Minute, policy.DefaultDuration, "default is clamped to the minimum")
+	assert.Equal(t, []string{"BCA", "CREDIT_CARD"}, policy.AllowedPaymentMethods)
+}
+
+func TestInvoicePolicyDuration(t *testing.T) {
+	policy := NewInvoicePolicy(viper.New())
+
+	tests := []struct {
+		name     string
+		seconds  int64
+		duration time.Duration
+		err      error
+	}{
+		{"default", 0, 24 * time.Hour, nil},
+		{"within range", 3600, time.Hour, nil},
+		{"minimum", 900, 15 * time.Minute, nil},
+		{"maximum", 259200, 72 * time.Hour, nil},
+		{"too short", 60, 0, ErrInvoiceDurationOutOfRange},
+		{"too long", 259201, 0, ErrInvoiceDurationOutOfRange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			duration, err := policy.Duration(tt.seconds)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.duration, duration)
+		})
+	}
+}
+
+func TestInvoicePolicyPaymentMethods(t *testing.T) {
+	open := &InvoicePolicy{}
+	restricted := &InvoicePolicy{AllowedPaymentMethods: []string{"BCA", "QRIS", "CREDIT_CARD", "GCASH"}}
+
+	tests := []struct {
+		name      string
+		policy    *InvoicePolicy
+		currency  string
+		requested []string
+		methods   []string
+		err       error
+	}{
+		{"IDR left to the gateway", open, "IDR", nil, nil, nil},
+		{"other currency narrowed to its channels", open, "THB", nil, []string{"CREDIT_CARD", "PROMPTPAY", "SHOPEEPAY"}, nil},
+		{"requested channels normalized and sorted", open, "IDR", []string{"qris", " bca", "QRIS"}, []string{"BCA", "QRIS"}, nil},
+		{"requested channel not accepting the currency", open, "IDR", []string{"GCASH"}, nil, ErrPaymentMethodNotAllowed},
+		{"server list applied without a request", restricted, "IDR", nil, []string{"BCA", "CREDIT_CARD", "QRIS"}, nil},
+		{"server list intersected with the currency", restricted, "PHP", nil, []string{"CREDIT_CARD", "GCASH"}, nil},
+		{"requested channel outside the server list", restricted, "IDR", []string{"OVO"}, nil, ErrPaymentMethodNotAllowed},
+		{"server list without the currency", &InvoicePolicy{AllowedPaymentMethods: []string{"BCA"}}, "PHP", nil, nil, ErrPaymentMethodNotAllowed},
+		{"unsupported currency", open, "EUR", nil, nil, ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methods, err := tt.policy.PaymentMethods(tt.currency, tt.requested)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.methods, methods)
+		})
+	}
+}
+
+func TestInvoicePolicyLocale(t *testing.T) {
+	policy := &InvoicePolicy{DefaultLocale: "id"}
+
+	assert.Equal(t, "id", policy.Locale(""))
+	assert.Equal(t, "en", policy.Locale("en"))
+}
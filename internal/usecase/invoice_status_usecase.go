@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidStatusTransition = utils.WrapMessageAsError(constants.InvalidStatusTransition)
+
+type InvoiceStatusUseCase struct {
+	DB                             *gorm.DB
+	Log                            *logrus.Logger
+	Redis                          *redis.Client
+	InvoiceRepository              *repository.InvoiceRepository
+	InvoiceStatusHistoryRepository *repository.InvoiceStatusHistoryRepository
+	NotificationUseCase            *NotificationUseCase
+	VoucherUseCase                 *VoucherUseCase
+}
+
+func NewInvoiceStatusUsecase(db *gorm.DB, log *logrus.Logger, redis *redis.Client, invoiceRepository *repository.InvoiceRepository, invoiceStatusHistoryRepository *repository.InvoiceStatusHistoryRepository, notificationUseCase *NotificationUseCase, voucherUseCase *VoucherUseCase) *InvoiceStatusUseCase {
+	return &InvoiceStatusUseCase{
+		DB:                             db,
+		Log:                            log,
+		Redis:                          redis,
+		InvoiceRepository:              invoiceRepository,
+		InvoiceStatusHistoryRepository: invoiceStatusHistoryRepository,
+		NotificationUseCase:            notificationUseCase,
+		VoucherUseCase:                 voucherUseCase,
+	}
+}
+
+// Transition reports a repeated status as unchanged instead of rejecting it,
+// so redelivered provider callbacks stay idempotent. An invoice that ends
+// unpaid gives its voucher use back.
+func (uc *InvoiceStatusUseCase) Transition(tx *gorm.DB, invoice *entity.Invoice, next entity.InvoiceStatus, source string) (bool, error) {
+	previous := invoice.Status
+	if previous == next && !previous.CanTransitionTo(next) {
+		return false, nil
+	}
+
+	if err := invoice.TransitionTo(next); err != nil {
+		uc.Log.WithFields(logrus.Fields{
+			"invoice_id": invoice.ID,
+			"from":       previous,
+			"to":         next,
+			"source":     source,
+		}).Warn("Rejected invoice status transition")
+		return false, ErrInvalidStatusTransition
+	}
+
+	if err := uc.InvoiceRepository.UpdateStatus(tx, invoice.ID, next); err != nil {
+		invoice.Status = previous
+		return false, err
+	}
+	if next.IsVoid() {
+		invoice.OpenOrderID = nil
+	}
+
+	if err := uc.RecordHistory(tx, invoice, previous, source); err != nil {
+		invoice.Status = previous
+		return false, err
+	}
+
+	if next == entity.InvoiceStatusExpired || next == entity.InvoiceStatusFailed {
+		if err := uc.VoucherUseCase.Release(tx, invoice); err != nil {
+			invoice.Status = previous
+			return false, err
+		}
+	}
+
+	uc.Log.WithFields(logrus.Fields{
+		"invoice_id": invoice.ID,
+		"from":       previous,
+		"to":         next,
+		"source":     source,
+	}).Info("Invoice status changed")
+	return true, nil
+}
+
+// RecordHistory also queues the payer email for the new status, which covers
+// newly created and reissued invoices as well as every transition.
+func (uc *InvoiceStatusUseCase) RecordHistory(tx *gorm.DB, invoice *entity.Invoice, previous entity.InvoiceStatus, source string) error {
+	history := &entity.InvoiceStatusHistory{
+		ID:         uuid.New(),
+		InvoiceID:  invoice.ID,
+		FromStatus: previous,
+		ToStatus:   invoice.Status,
+		Source:     source,
+	}
+
+	if err := uc.InvoiceStatusHistoryRepository.Create(tx, history); err != nil {
+		uc.Log.WithError(err).Error("Failed to record invoice status history")
+		return err
+	}
+
+	if err := uc.NotificationUseCase.Enqueue(tx, invoice); err != nil {
+		uc.Log.WithError(err).Error("Failed to queue invoice notification")
+		return err
+	}
+	return nil
+}
+
+// Publish fans a committed transition out to every replica through Redis so
+// WatchInvoice streams see it regardless of which instance applied it.
+func (uc *InvoiceStatusUseCase) Publish(ctx context.Context, invoice *entity.Invoice, previous entity.InvoiceStatus, source string) {
+	event := &model.InvoiceStatusEvent{
+		InvoiceID:      invoice.ID.String(),
+		OrderID:        invoice.OrderID.String(),
+		Status:         string(invoice.Status),
+		PreviousStatus: string(previous),
+		Source:         source,
+		ChangedAt:      time.Now().Format(time.RFC3339),
+	}
+
+	value, err := json.Marshal(event)
+	if err != nil {
+		uc.Log.WithError(err).Error("Failed to encode invoice status event")
+		return
+	}
+
+	if err := uc.Redis.Publish(ctx, invoiceStatusChannel(invoice.OrderID), value).Err(); err != nil {
+		uc.Log.WithError(err).Errorf("Failed to publish status change of invoice %s", invoice.ID)
+	}
+}
+
+func (uc *InvoiceStatusUseCase) Watch(ctx context.Context, orderID uuid.UUID, send func(*model.InvoiceStatusEvent) error) error {
+	subscription := uc.Redis.Subscribe(ctx, invoiceStatusChannel(orderID))
+	defer subscription.Close()
+
+	if _, err := subscription.Receive(ctx); err != nil {
+		uc.Log.WithError(err).Error("Failed to subscribe to invoice status changes")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindLatestByOrderID(uc.DB.WithContext(ctx), orderID, &invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvoiceNotFound
+		}
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	current := invoice.ID.String()
+	if err := send(&model.InvoiceStatusEvent{
+		InvoiceID: current,
+		OrderID:   invoice.OrderID.String(),
+		Status:    string(invoice.Status),
+		Source:    "snapshot",
+		ChangedAt: invoice.UpdatedAt.Format(time.RFC3339),
+	}); err != nil {
+		return err
+	}
+	if watchEnds(invoice.Status) {
+		return nil
+	}
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+
+			event := new(model.InvoiceStatusEvent)
+			if err := json.Unmarshal([]byte(message.Payload), event); err != nil {
+				uc.Log.WithError(err).Warn("Ignoring malformed invoice status event")
+				continue
+			}
+
+			// A reissued invoice replaces the one being watched.
+			if event.InvoiceID != current && event.Status == string(entity.InvoiceStatusPending) {
+				current = event.InvoiceID
+			}
+
+			if err := send(event); err != nil {
+				return err
+			}
+
+			if event.InvoiceID == current && watchEnds(entity.InvoiceStatus(event.Status)) {
+				return nil
+			}
+		}
+	}
+}
+
+func invoiceStatusChannel(orderID uuid.UUID) string {
+	return "payment:invoice-status:" + orderID.String()
+}
+
+// watchEnds reports whether a watcher has seen the outcome it waits for: the
+// invoice was paid or can no longer change. Later settlement and refunds are
+// not followed.
+func watchEnds(status entity.InvoiceStatus) bool {
+	return status.IsPaid() || status.IsTerminal()
+}
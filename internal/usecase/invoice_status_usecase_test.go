@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestInvoiceStatusTransition(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    entity.InvoiceStatus
+		to      entity.InvoiceStatus
+		changed bool
+		err     error
+	}{
+		{"payment", entity.InvoiceStatusPending, entity.InvoiceStatusPaid, true, nil},
+		{"settlement", entity.InvoiceStatusPaid, entity.InvoiceStatusSettled, true, nil},
+		{"redelivered status", entity.InvoiceStatusPaid, entity.InvoiceStatusPaid, false, nil},
+		{"repeated partial refund", entity.InvoiceStatusPartiallyRefunded, entity.InvoiceStatusPartiallyRefunded, true, nil},
+		{"expiry after payment", entity.InvoiceStatusPaid, entity.InvoiceStatusExpired, false, ErrInvalidStatusTransition},
+		{"payment after expiry", entity.InvoiceStatusExpired, entity.InvoiceStatusPaid, false, ErrInvalidStatusTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			created := env.createInvoice(t, uuid.New(), "", 100000)
+			require.NoError(t, env.DB.Model(&entity.Invoice{}).Where("id = ?", created.ID).Update("status", tt.from).Error)
+			invoice := env.findInvoice(t, created.ID)
+
+			var changed bool
+			err := env.DB.Transaction(func(tx *gorm.DB) error {
+				var err error
+				changed, err = env.InvoiceStatusUseCase.Transition(tx, invoice, tt.to, "test")
+				return err
+			})
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.changed, changed)
+
+			expected := tt.from
+			if tt.changed {
+				expected = tt.to
+			}
+			assert.Equal(t, expected, invoice.Status)
+			assert.Equal(t, expected, env.findInvoice(t, created.ID).Status)
+
+			var history int64
+			require.NoError(t, env.DB.Model(&entity.InvoiceStatusHistory{}).Where("invoice_id = ? AND source = ?", created.ID, "test").Count(&history).Error)
+			assert.Equal(t, tt.changed, history == 1)
+		})
+	}
+}
+
+func TestWatchEndsOncePaid(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 100000)
+	orderID := uuid.MustParse(created.OrderID)
+
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	watcher := *env.InvoiceStatusUseCase
+	watcher.Redis = rdb
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan *model.InvoiceStatusEvent, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Watch(ctx, orderID, func(event *model.InvoiceStatusEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	snapshot := <-events
+	assert.Equal(t, "snapshot", snapshot.Source)
+	assert.Equal(t, string(entity.InvoiceStatusPending), snapshot.Status)
+
+	invoice := env.findInvoice(t, created.ID)
+	require.NoError(t, env.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := watcher.Transition(tx, invoice, entity.InvoiceStatusPaid, "test")
+		return err
+	}))
+	watcher.Publish(ctx, invoice, entity.InvoiceStatusPending, "test")
+
+	require.NoError(t, <-done)
+	paid := <-events
+	assert.Equal(t, string(entity.InvoiceStatusPaid), paid.Status)
+
+	// A watcher arriving after the payment only gets the snapshot.
+	require.NoError(t, watcher.Watch(ctx, orderID, func(event *model.InvoiceStatusEvent) error {
+		assert.Equal(t, string(entity.InvoiceStatusPaid), event.Status)
+		return nil
+	}))
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/mailer"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationInvoiceCreated = "invoice_created"
+	NotificationInvoicePaid    = "invoice_paid"
+	NotificationInvoiceExpired = "invoice_expired"
+)
+
+const notificationTimeLayout = "02 Jan 2006 15:04 MST"
+
+// ErrNotificationObsolete marks a notification that must not be sent any more,
+// e.g. a payment link for an invoice that has been paid in the meantime.
+var ErrNotificationObsolete = errors.New("notification is obsolete")
+
+var notificationSubjects = map[string]model.Message{
+	NotificationInvoiceCreated: constants.NotificationInvoiceCreatedSubject,
+	NotificationInvoicePaid:    constants.NotificationInvoicePaidSubject,
+	NotificationInvoiceExpired: constants.NotificationInvoiceExpiredSubject,
+}
+
+type NotificationUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	Viper                  *viper.Viper
+	Branding               *model.Branding
+	NotificationRepository *repository.NotificationRepository
+	InvoiceRepository      *repository.InvoiceRepository
+	Sender                 mailer.Sender
+}
+
+func NewNotificationUsecase(db *gorm.DB, log *logrus.Logger, viper *viper.Viper, branding *model.Branding, notificationRepository *repository.NotificationRepository, invoiceRepository *repository.InvoiceRepository, sender mailer.Sender) *NotificationUseCase {
+	return &NotificationUseCase{
+		DB:                     db,
+		Log:                    log,
+		Viper:                  viper,
+		Branding:               branding,
+		NotificationRepository: notificationRepository,
+		InvoiceRepository:      invoiceRepository,
+		Sender:                 sender,
+	}
+}
+
+// Enqueue schedules the email matching the invoice's current status in the
+// caller's transaction, so an email is only ever sent for a committed change.
+func (uc *NotificationUseCase) Enqueue(tx *gorm.DB, invoice *entity.Invoice) error {
+	template := notificationTemplate(invoice.Status)
+	if template == "" || invoice.PayerEmail == "" {
+		return nil
+	}
+
+	notification := &entity.Notification{
+		ID:            uuid.New(),
+		InvoiceID:     invoice.ID,
+		Template:      template,
+		Recipient:     invoice.PayerEmail,
+		Status:        entity.NotificationStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	return uc.NotificationRepository.CreateIfAbsent(tx, notification)
+}
+
+// Deliver renders the notification against the invoice as it is now rather
+// than when it was queued, so retries always carry up-to-date details.
+func (uc *NotificationUseCase) Deliver(ctx context.Context, notification *entity.Notification) error {
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindById(uc.DB.WithContext(ctx), &invoice, notification.InvoiceID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotificationObsolete
+		}
+		return err
+	}
+
+	if notification.Template == NotificationInvoiceCreated && invoice.Status != entity.InvoiceStatusPending {
+		return ErrNotificationObsolete
+	}
+
+	text, html, err := mailer.Render(notification.Template, uc.notificationData(&invoice))
+	if err != nil {
+		return err
+	}
+
+	subject := notificationSubjects[notification.Template]
+	return uc.Sender.Send(ctx, &mailer.Message{
+		To:      notification.Recipient,
+		Subject: subject["en"] + " / " + subject["id"] + " - " + uc.Branding.Name,
+		Text:    text,
+		HTML:    html,
+	})
+}
+
+func (uc *NotificationUseCase) notificationData(invoice *entity.Invoice) *model.InvoiceNotificationData {
+	location := uc.Branding.Location
+	data := &model.InvoiceNotificationData{
+		Brand:          uc.Branding.Name,
+		InvoiceID:      invoice.ID.String(),
+		OrderID:        invoice.OrderID.String(),
+		Amount:         invoice.Amount.Format(),
+		InvoiceURL:     invoice.InvoiceURL,
+		PaymentMethod:  invoice.PaymentMethod,
+		PaymentChannel: invoice.PaymentChannel,
+	}
+	if invoice.ExpiresAt != nil {
+		data.ExpiresAt = invoice.ExpiresAt.In(location).Format(notificationTimeLayout)
+	}
+	if invoice.PaidAt != nil {
+		data.Amount = invoice.CollectedAmount().Format()
+		data.PaidAt = invoice.PaidAt.In(location).Format(notificationTimeLayout)
+	}
+	return data
+}
+
+func notificationTemplate(status entity.InvoiceStatus) string {
+	switch status {
+	case entity.InvoiceStatusPending:
+		return NotificationInvoiceCreated
+	case entity.InvoiceStatusPaid, entity.InvoiceStatusSettled:
+		return NotificationInvoicePaid
+	case entity.InvoiceStatusExpired:
+		return NotificationInvoiceExpired
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/delivery/grpc/client"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	OrderEventCancelled     = "order.cancelled"
+	OrderEventAmountChanged = "order.amount_changed"
+
+	EventInvoiceReissued = "invoice.reissued"
+)
+
+var ErrInvalidOrderEvent = utils.WrapMessageAsError(constants.InvalidOrderEvent)
+
+type OrderEventUseCase struct {
+	DB                   *gorm.DB
+	Log                  *logrus.Logger
+	Validate             *validator.Validate
+	InvoiceRepository    *repository.InvoiceRepository
+	InvoiceStatusUseCase *InvoiceStatusUseCase
+	OutboxUseCase        *OutboxUseCase
+	ExchangeRateUseCase  *ExchangeRateUseCase
+	VoucherUseCase       *VoucherUseCase
+	PaymentUseCase       *PaymentUseCase
+	PaymentGateway       gateway.PaymentGateway
+	OrderClient          *client.OrderClient
+}
+
+func NewOrderEventUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, invoiceRepository *repository.InvoiceRepository, invoiceStatusUseCase *InvoiceStatusUseCase, outboxUseCase *OutboxUseCase, exchangeRateUseCase *ExchangeRateUseCase, voucherUseCase *VoucherUseCase, paymentUseCase *PaymentUseCase, paymentGateway gateway.PaymentGateway, orderClient *client.OrderClient) *OrderEventUseCase {
+	return &OrderEventUseCase{
+		DB:                   db,
+		Log:                  log,
+		Validate:             validate,
+		InvoiceRepository:    invoiceRepository,
+		InvoiceStatusUseCase: invoiceStatusUseCase,
+		OutboxUseCase:        outboxUseCase,
+		ExchangeRateUseCase:  exchangeRateUseCase,
+		VoucherUseCase:       voucherUseCase,
+		PaymentUseCase:       paymentUseCase,
+		PaymentGateway:       paymentGateway,
+		OrderClient:          orderClient,
+	}
+}
+
+func (uc *OrderEventUseCase) HandleOrderEvent(ctx context.Context, body []byte) error {
+	event := new(model.OrderEvent)
+	if err := json.Unmarshal(body, event); err != nil {
+		uc.Log.WithError(err).Warn("Failed to decode order event")
+		return ErrInvalidOrderEvent
+	}
+
+	if err := uc.Validate.Struct(event); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		uc.Log.Warnf("Invalid order event: %v", message)
+		return ErrInvalidOrderEvent
+	}
+
+	orderID := uuid.MustParse(event.OrderID)
+
+	switch event.Event {
+	case OrderEventCancelled:
+		return uc.ExpireInvoice(ctx, orderID)
+	case OrderEventAmountChanged:
+		return uc.ReissueInvoice(ctx, orderID, event.TotalAmount)
+	default:
+		uc.Log.Debugf("Ignoring order event %s for order %s", event.Event, event.OrderID)
+		return nil
+	}
+}
+
+func (uc *OrderEventUseCase) ExpireInvoice(ctx context.Context, orderID uuid.UUID) error {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindPendingByOrderIDForUpdate(tx, orderID, &invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			uc.Log.Infof("No open invoice to expire for cancelled order %s", orderID)
+			return nil
+		}
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	previous := invoice.Status
+	changed, settled, err := uc.closeInvoice(ctx, tx, &invoice, "order_cancelled")
+	if err != nil {
+		return err
+	}
+	if settled != nil {
+		tx.Rollback()
+		return uc.applySettled(ctx, &invoice, settled)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if changed {
+		uc.InvoiceStatusUseCase.Publish(ctx, &invoice, previous, "order_cancelled")
+	}
+
+	return nil
+}
+
+func (uc *OrderEventUseCase) ReissueInvoice(ctx context.Context, orderID uuid.UUID, totalAmount int64) error {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindPendingByOrderIDForUpdate(tx, orderID, &invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			uc.Log.Infof("No open invoice to reissue for order %s", orderID)
+			return nil
+		}
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	orderAmount := money.New(totalAmount, invoice.OrderAmount.Currency)
+	if invoice.OrderAmount == orderAmount {
+		return nil
+	}
+
+	_, rate, err := uc.ExchangeRateUseCase.Convert(ctx, orderAmount, invoice.Amount.Currency)
+	if err != nil {
+		return err
+	}
+	paymentMethods := invoice.AllowedMethods
+	if len(paymentMethods) == 0 {
+		if paymentMethods, err = invoicePaymentMethods(invoice.Amount.Currency); err != nil {
+			return err
+		}
+	}
+	items := uc.currentItems(ctx, orderID, orderAmount)
+
+	previous := invoice.Status
+	changed, settled, err := uc.closeInvoice(ctx, tx, &invoice, "order_amount_changed")
+	if err != nil {
+		return err
+	}
+	if settled != nil {
+		tx.Rollback()
+		return uc.applySettled(ctx, &invoice, settled)
+	}
+	if invoice.Status != entity.InvoiceStatusExpired {
+		uc.Log.Warnf("Invoice %s for order %s was %s at the gateway, not reissuing", invoice.ID, orderID, invoice.Status)
+		if err := tx.Commit().Error; err != nil {
+			return utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+		if changed {
+			uc.InvoiceStatusUseCase.Publish(ctx, &invoice, previous, "order_amount_changed")
+		}
+		return nil
+	}
+
+	reissuedID := uuid.New()
+	payable, discount, voucherCode := uc.reapplyVoucher(tx, &invoice, reissuedID, orderAmount)
+	amount := convertAt(payable, rate)
+	fees := discountFees(discount, rate.Rate, amount.Currency)
+
+	resp, err := uc.PaymentGateway.CreateInvoice(ctx, &gateway.CreateInvoiceParams{
+		ExternalID:         orderID.String(),
+		Amount:             amount,
+		PayerEmail:         invoice.PayerEmail,
+		Description:        invoice.Description,
+		Customer:           gateway.Customer{Name: invoice.CustomerName, Phone: invoice.CustomerPhone},
+		Locale:             invoice.Locale,
+		Duration:           time.Duration(invoice.Duration) * time.Second,
+		PaymentMethods:     paymentMethods,
+		Items:              toGatewayItems(items, rate.Rate, amount, fees),
+		Fees:               fees,
+		SuccessRedirectURL: invoice.SuccessURL,
+		FailureRedirectURL: invoice.FailureURL,
+	})
+	if err != nil {
+		uc.Log.WithError(err).Error("Failed to reissue invoice in payment gateway")
+		return utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
+	}
+
+	reissued := &entity.Invoice{
+		ID:             reissuedID,
+		OrderID:        orderID,
+		OpenOrderID:    &orderID,
+		UserID:         invoice.UserID,
+		Amount:         resp.Amount,
+		OrderAmount:    orderAmount,
+		VoucherCode:    voucherCode,
+		Discount:       discount,
+		ExchangeRate:   rate.Rate,
+		ExchangeRateAt: &rate.UpdatedAt,
+		PayerEmail:     resp.PayerEmail,
+		CustomerName:   invoice.CustomerName,
+		CustomerPhone:  invoice.CustomerPhone,
+		Locale:         invoice.Locale,
+		AllowedMethods: invoice.AllowedMethods,
+		Duration:       invoice.Duration,
+		ExpiresAt:      resp.ExpiryDate,
+		Description:    resp.Description,
+		Status:         entity.InvoiceStatus(resp.Status),
+		XenditID:       resp.ID,
+		InvoiceURL:     resp.InvoiceURL,
+		SuccessURL:     invoice.SuccessURL,
+		FailureURL:     invoice.FailureURL,
+		Items:          items,
+	}
+
+	if err := uc.InvoiceRepository.Create(tx, reissued); err != nil {
+		uc.Log.WithError(err).Error("Failed to create reissued invoice")
+		return utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
+	}
+
+	if err := uc.InvoiceStatusUseCase.RecordHistory(tx, reissued, "", "order_amount_changed"); err != nil {
+		return utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
+	}
+
+	if err := uc.OutboxUseCase.Enqueue(tx, orderID.String(), EventInvoiceReissued, toInvoiceResponse(reissued)); err != nil {
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Errorf("Failed to commit reissued invoice, gateway invoice %s is orphaned", resp.ID)
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.InvoiceStatusUseCase.Publish(ctx, reissued, "", "order_amount_changed")
+	if changed {
+		uc.InvoiceStatusUseCase.Publish(ctx, &invoice, previous, "order_amount_changed")
+	}
+
+	uc.Log.Infof("Reissued invoice %s for order %s with amount %s", reissued.ID, orderID, amount)
+	return nil
+}
+
+// reapplyVoucher carries the closed invoice's voucher over to its
+// replacement, priced against the new order total. The old use was released
+// when the invoice expired; if the voucher no longer applies the order is
+// reissued at full price rather than not at all.
+func (uc *OrderEventUseCase) reapplyVoucher(tx *gorm.DB, invoice *entity.Invoice, reissuedID uuid.UUID, orderAmount money.Money) (money.Money, money.Money, string) {
+	if invoice.VoucherCode == "" {
+		return orderAmount, money.Money{}, ""
+	}
+
+	redemption, err := uc.VoucherUseCase.Redeem(tx, invoice.VoucherCode, invoice.UserID, invoice.OrderID, reissuedID, orderAmount)
+	if err != nil {
+		uc.Log.WithError(err).Warnf("Voucher %s no longer applies to order %s, reissuing without it", invoice.VoucherCode, invoice.OrderID)
+		return orderAmount, money.Money{}, ""
+	}
+	return orderAmount.Sub(redemption.Discount), redemption.Discount, invoice.VoucherCode
+}
+
+// currentItems fetches the order's line items for a reissued invoice. The
+// order service may not have caught up with the amount change yet, so items
+// are only used when they belong to the same total as the event.
+func (uc *OrderEventUseCase) currentItems(ctx context.Context, orderID uuid.UUID, orderAmount money.Money) []entity.InvoiceItem {
+	order, err := uc.OrderClient.GetOrderByID(ctx, orderID.String())
+	if err != nil {
+		uc.Log.WithError(err).Warnf("Reissuing invoice for order %s without line items", orderID)
+		return nil
+	}
+	if order.GetTotalAmount() != orderAmount.Value {
+		uc.Log.Warnf("Order %s total %d does not match event amount %s, reissuing without line items", orderID, order.GetTotalAmount(), orderAmount)
+		return nil
+	}
+	return toInvoiceItems(order.GetItems(), orderAmount.Currency)
+}
+
+// closeInvoice expires the invoice at the gateway and applies the resulting
+// status locally, reporting whether the local status changed. When the
+// gateway refuses because the invoice was already settled there, nothing is
+// applied and the gateway's invoice is returned instead, so the caller can
+// record the payment once it has released the invoice row.
+func (uc *OrderEventUseCase) closeInvoice(ctx context.Context, tx *gorm.DB, invoice *entity.Invoice, source string) (bool, *gateway.Invoice, error) {
+	resp, err := uc.PaymentGateway.ExpireInvoice(ctx, invoice.XenditID)
+	if err != nil {
+		current, getErr := uc.PaymentGateway.GetInvoice(ctx, invoice.XenditID)
+		if getErr != nil || current.Status == string(entity.InvoiceStatusPending) {
+			uc.Log.WithError(err).Errorf("Failed to expire invoice %s at payment gateway", invoice.ID)
+			return false, nil, err
+		}
+		if current.Status != string(entity.InvoiceStatusExpired) {
+			return false, current, nil
+		}
+		resp = current
+	}
+
+	changed, err := uc.InvoiceStatusUseCase.Transition(tx, invoice, entity.InvoiceStatus(resp.Status), source)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if changed {
+		if err := uc.OutboxUseCase.Enqueue(tx, invoice.OrderID.String(), EventInvoiceUpdated, toInvoiceResponse(invoice)); err != nil {
+			return false, nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	return changed, nil, nil
+}
+
+// applySettled records a payment that beat the order event to the gateway
+// through the callback path, so it gets the same amount checks, payment
+// details and outbox event as if the callback had arrived first.
+func (uc *OrderEventUseCase) applySettled(ctx context.Context, invoice *entity.Invoice, settled *gateway.Invoice) error {
+	uc.Log.Warnf("Invoice %s for order %s was already %s at the gateway, recording it instead of closing", invoice.ID, invoice.OrderID, settled.Status)
+	if _, err := uc.PaymentUseCase.ApplyGatewayInvoice(ctx, settled); err != nil {
+		uc.Log.WithError(err).Errorf("Failed to apply gateway status %s to invoice %s", settled.Status, invoice.ID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"golectro-payment/internal/entity"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func orderEvent(event, orderID string, totalAmount int64) []byte {
+	return fmt.Appendf(nil, `{"event":%q,"order_id":%q,"total_amount":%d}`, event, orderID, totalAmount)
+}
+
+func TestHandleOrderCancelled(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+
+	require.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent(OrderEventCancelled, created.OrderID, 0)))
+
+	invoice := env.findInvoice(t, created.ID)
+	assert.Equal(t, entity.InvoiceStatusExpired, invoice.Status)
+	assert.Nil(t, invoice.OpenOrderID)
+	provider, err := env.Gateway.GetInvoice(context.Background(), created.XenditID)
+	require.NoError(t, err)
+	assert.Equal(t, "EXPIRED", provider.Status)
+	assert.Equal(t, 1, countOutboxEvents(t, env, EventInvoiceUpdated))
+
+	// A redelivered event finds no open invoice.
+	require.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent(OrderEventCancelled, created.OrderID, 0)))
+	assert.Equal(t, 1, countOutboxEvents(t, env, EventInvoiceUpdated))
+}
+
+func TestHandleOrderCancelledAfterPayment(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+	_, err := env.Gateway.MarkInvoicePaid(created.XenditID, "BANK_TRANSFER", "BCA")
+	require.NoError(t, err)
+
+	require.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent(OrderEventCancelled, created.OrderID, 0)))
+
+	invoice := env.findInvoice(t, created.ID)
+	assert.Equal(t, entity.InvoiceStatusPaid, invoice.Status)
+	assert.Equal(t, "BCA", invoice.PaymentChannel)
+}
+
+func TestHandleOrderAmountChanged(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.createInvoice(t, userID, "", 150000, 50000)
+
+	order := env.Orders.Add(userID, 150000, 80000)
+	order.Id = created.OrderID
+	env.Orders.orders[created.OrderID] = order
+
+	require.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent(OrderEventAmountChanged, created.OrderID, 230000)))
+
+	assert.Equal(t, entity.InvoiceStatusExpired, env.findInvoice(t, created.ID).Status)
+
+	var reissued entity.Invoice
+	require.NoError(t, env.DB.Preload("Items").Take(&reissued, "order_id = ? AND id <> ?", created.OrderID, created.ID).Error)
+	assert.Equal(t, entity.InvoiceStatusPending, reissued.Status)
+	assert.Equal(t, int64(230000), reissued.Amount.Value)
+	assert.Equal(t, int64(230000), reissued.OrderAmount.Value)
+	assert.Equal(t, &reissued.OrderID, reissued.OpenOrderID)
+	assert.Equal(t, userID, reissued.UserID)
+	assert.Len(t, reissued.Items, 2)
+	assert.Equal(t, 1, countOutboxEvents(t, env, EventInvoiceReissued))
+
+	// The same total again leaves the reissued invoice alone.
+	require.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent(OrderEventAmountChanged, created.OrderID, 230000)))
+	assert.Equal(t, entity.InvoiceStatusPending, env.findInvoice(t, reissued.ID.String()).Status)
+}
+
+func TestHandleOrderEventRejectsInvalidEvents(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, body := range []string{
+		`not json`,
+		`{"event":"order.cancelled"}`,
+		`{"event":"order.cancelled","order_id":"not-a-uuid"}`,
+		`{"event":"order.amount_changed","order_id":"` + uuid.NewString() + `"}`,
+	} {
+		assert.ErrorIs(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), []byte(body)), ErrInvalidOrderEvent, body)
+	}
+
+	assert.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent("order.shipped", uuid.NewString(), 0)))
+	assert.NoError(t, env.OrderEventUseCase.HandleOrderEvent(context.Background(), orderEvent(OrderEventCancelled, uuid.NewString(), 0)))
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"encoding/json"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	OutboxAggregateOrder = "order"
+
+	EventInvoiceUpdated  = "invoice.updated"
+	EventRefundCompleted = "refund.completed"
+)
+
+type OutboxUseCase struct {
+	Log              *logrus.Logger
+	OutboxRepository *repository.OutboxRepository
+}
+
+func NewOutboxUsecase(log *logrus.Logger, outboxRepository *repository.OutboxRepository) *OutboxUseCase {
+	return &OutboxUseCase{
+		Log:              log,
+		OutboxRepository: outboxRepository,
+	}
+}
+
+// Enqueue must be called with the same transaction that mutates the
+// aggregate so the event is only published if that change commits.
+func (uc *OutboxUseCase) Enqueue(tx *gorm.DB, orderID, eventType string, payload any) error {
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event := &entity.OutboxEvent{
+		AggregateType: OutboxAggregateOrder,
+		AggregateID:   orderID,
+		EventType:     eventType,
+		Payload:       value,
+		Status:        entity.OutboxEventStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	if err := uc.OutboxRepository.Create(tx, event); err != nil {
+		uc.Log.WithError(err).Errorf("Failed to enqueue %s outbox event", eventType)
+		return err
+	}
+	return nil
+}
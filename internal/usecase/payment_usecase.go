@@ -2,94 +2,260 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"golectro-payment/internal/constants"
+	"golectro-payment/internal/delivery/grpc/client"
+	orderpb "golectro-payment/internal/delivery/grpc/proto/order"
 	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
 	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/redirect"
 	"golectro-payment/internal/repository"
 	"golectro-payment/internal/utils"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/spf13/viper"
-	"github.com/xendit/xendit-go"
-	"github.com/xendit/xendit-go/invoice"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvoiceNotFound      = utils.WrapMessageAsError(constants.InvoiceNotFound)
+	ErrInvoiceAlreadyExists = utils.WrapMessageAsError(constants.InvoiceAlreadyExists)
+	ErrOrderNotFound        = utils.WrapMessageAsError(constants.OrderNotFound)
+	ErrOrderNotOwned        = utils.WrapMessageAsError(constants.OrderNotOwned)
+	ErrOrderNotPayable      = utils.WrapMessageAsError(constants.OrderNotPayable)
+	ErrOrderTotalMismatch   = utils.WrapMessageAsError(constants.OrderTotalMismatch)
+	ErrUnsupportedCurrency  = utils.WrapMessageAsError(constants.UnsupportedCurrency)
+	ErrRedirectNotAllowed   = utils.WrapMessageAsError(constants.RedirectURLNotAllowed)
+
+	ErrInvoiceDurationOutOfRange = utils.WrapMessageAsError(constants.InvoiceDurationOutOfRange)
+	ErrPaymentMethodNotAllowed   = utils.WrapMessageAsError(constants.PaymentMethodNotAllowed)
+
+	ErrCallbackCurrencyMismatch = utils.WrapMessageAsError(constants.CallbackCurrencyMismatch)
+)
+
 type PaymentUseCase struct {
-	DB                *gorm.DB
-	Log               *logrus.Logger
-	Validate          *validator.Validate
-	InvoiceRepository *repository.InvoiceRepository
-	Viper             *viper.Viper
+	DB                   *gorm.DB
+	Log                  *logrus.Logger
+	Validate             *validator.Validate
+	InvoiceRepository    *repository.InvoiceRepository
+	InvoiceStatusUseCase *InvoiceStatusUseCase
+	OutboxUseCase        *OutboxUseCase
+	PaymentGateway       gateway.PaymentGateway
+	ExchangeRateUseCase  *ExchangeRateUseCase
+	AnomalyUseCase       *AnomalyUseCase
+	VoucherUseCase       *VoucherUseCase
+	OrderClient          *client.OrderClient
+	RedirectPolicy       *redirect.Policy
+	InvoicePolicy        *InvoicePolicy
+	Viper                *viper.Viper
 }
 
-func NewPaymentUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, viper *viper.Viper, invoiceRepository *repository.InvoiceRepository) *PaymentUseCase {
+func NewPaymentUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, viper *viper.Viper, invoiceRepository *repository.InvoiceRepository, invoiceStatusUseCase *InvoiceStatusUseCase, outboxUseCase *OutboxUseCase, exchangeRateUseCase *ExchangeRateUseCase, anomalyUseCase *AnomalyUseCase, voucherUseCase *VoucherUseCase, paymentGateway gateway.PaymentGateway, orderClient *client.OrderClient) *PaymentUseCase {
 	return &PaymentUseCase{
-		DB:                db,
-		Log:               log,
-		Validate:          validate,
-		InvoiceRepository: invoiceRepository,
-		Viper:             viper,
+		DB:                   db,
+		Log:                  log,
+		Validate:             validate,
+		InvoiceRepository:    invoiceRepository,
+		InvoiceStatusUseCase: invoiceStatusUseCase,
+		OutboxUseCase:        outboxUseCase,
+		ExchangeRateUseCase:  exchangeRateUseCase,
+		AnomalyUseCase:       anomalyUseCase,
+		VoucherUseCase:       voucherUseCase,
+		PaymentGateway:       paymentGateway,
+		OrderClient:          orderClient,
+		RedirectPolicy:       redirect.NewPolicy(viper),
+		InvoicePolicy:        NewInvoicePolicy(viper),
+		Viper:                viper,
 	}
 }
 
-func (uc *PaymentUseCase) CreateInvoice(ctx context.Context, userID uuid.UUID, email string, request *model.CreateInvoiceRequest, totalAmount int64) (*model.CreateInvoiceResponse, error) {
+func (uc *PaymentUseCase) CreateInvoice(ctx context.Context, userID uuid.UUID, email string, request *model.CreateInvoiceRequest) (*model.CreateInvoiceResponse, error) {
+	request.Currency = strings.ToUpper(request.Currency)
+	if err := uc.Validate.Struct(request); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+
+	successURL, failureURL, ok := uc.RedirectPolicy.Resolve(request.SuccessURL, request.FailureURL)
+	if !ok {
+		uc.Log.Warnf("Rejected redirect URLs for order %s: %q, %q", request.OrderID, request.SuccessURL, request.FailureURL)
+		return nil, ErrRedirectNotAllowed
+	}
+	duration, err := uc.InvoicePolicy.Duration(request.InvoiceDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := uc.OrderClient.GetOrderByID(ctx, request.OrderID)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if err != nil || order == nil {
+		return nil, ErrOrderNotFound
+	}
+	if err := uc.validateOrder(userID, order); err != nil {
+		return nil, err
+	}
+
 	tx := uc.DB.WithContext(ctx).Begin()
 	defer tx.Rollback()
 
-	if err := uc.Validate.Struct(request); err != nil {
-		message := utils.TranslateValidationError(uc.Validate, err)
-		return nil, utils.WrapMessageAsError(message)
+	orderID := uuid.MustParse(request.OrderID)
+
+	var existing entity.Invoice
+	if err := uc.InvoiceRepository.FindOpenByOrderID(tx, orderID, &existing); err == nil {
+		uc.Log.Warnf("Invoice %s is already %s for order %s", existing.ID, existing.Status, request.OrderID)
+		return nil, ErrInvoiceAlreadyExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	currency := request.Currency
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	paymentMethods, err := uc.InvoicePolicy.PaymentMethods(currency, request.PaymentMethods)
+	if err != nil {
+		uc.Log.Warnf("Rejected payment methods %v in %s for order %s", request.PaymentMethods, currency, request.OrderID)
+		return nil, err
+	}
+	locale := uc.InvoicePolicy.Locale(request.Locale)
+
+	invoiceID := uuid.New()
+	orderAmount := money.New(order.TotalAmount, money.DefaultCurrency)
+	payable, discount, voucherCode := orderAmount, money.Money{}, ""
+	var redemption *entity.VoucherRedemption
+	if request.VoucherCode != "" {
+		redemption, err = uc.VoucherUseCase.Reserve(ctx, request.VoucherCode, userID, orderID, invoiceID, orderAmount)
+		if err != nil {
+			return nil, err
+		}
+		payable, discount, voucherCode = orderAmount.Sub(redemption.Discount), redemption.Discount, normalizeVoucherCode(request.VoucherCode)
 	}
 
-	xendit.Opt.SecretKey = uc.Viper.GetString("XENDIT_SECRET_KEY")
+	committed := false
+	defer func() {
+		if redemption == nil || committed {
+			return
+		}
+		if err := uc.VoucherUseCase.CancelReservation(context.WithoutCancel(ctx), redemption); err != nil {
+			uc.Log.WithError(err).Errorf("Failed to cancel voucher reservation %s", redemption.ID)
+		}
+	}()
 
-	resp, err := invoice.Create(&invoice.CreateParams{
+	amount, rate, err := uc.ExchangeRateUseCase.Convert(ctx, payable, currency)
+	if err != nil {
+		return nil, err
+	}
+	items := toInvoiceItems(order.GetItems(), orderAmount.Currency)
+	fees := discountFees(discount, rate.Rate, amount.Currency)
+
+	resp, err := uc.PaymentGateway.CreateInvoice(ctx, &gateway.CreateInvoiceParams{
 		ExternalID:         request.OrderID,
-		Amount:             float64(totalAmount),
+		Amount:             amount,
 		PayerEmail:         email,
 		Description:        request.Description,
-		SuccessRedirectURL: "",
-		FailureRedirectURL: "",
+		Customer:           gateway.Customer{Name: request.CustomerName, Phone: request.CustomerPhone},
+		Locale:             locale,
+		Duration:           duration,
+		PaymentMethods:     paymentMethods,
+		Items:              toGatewayItems(items, rate.Rate, amount, fees),
+		Fees:               fees,
+		SuccessRedirectURL: successURL,
+		FailureRedirectURL: failureURL,
 	})
 	if err != nil {
-		uc.Log.WithError(err).Error("Failed to create invoice in Xendit")
+		uc.Log.WithError(err).Error("Failed to create invoice in payment gateway")
 		return nil, utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
 	}
 
 	invoice := &entity.Invoice{
-		ID:            uuid.New(),
-		OrderID:       uuid.MustParse(resp.ExternalID),
-		UserID:        userID,
-		Amount:        resp.Amount,
-		PaymentMethod: resp.PaymentMethod,
-		PayerEmail:    resp.PayerEmail,
-		Description:   resp.Description,
-		Status:        resp.Status,
-		XenditID:      resp.ID,
-		InvoiceURL:    resp.InvoiceURL,
+		ID:             invoiceID,
+		OrderID:        uuid.MustParse(resp.ExternalID),
+		OpenOrderID:    &orderID,
+		UserID:         userID,
+		Amount:         resp.Amount,
+		OrderAmount:    orderAmount,
+		VoucherCode:    voucherCode,
+		Discount:       discount,
+		ExchangeRate:   rate.Rate,
+		ExchangeRateAt: &rate.UpdatedAt,
+		PaymentMethod:  resp.PaymentMethod,
+		PayerEmail:     resp.PayerEmail,
+		CustomerName:   request.CustomerName,
+		CustomerPhone:  request.CustomerPhone,
+		Locale:         locale,
+		AllowedMethods: paymentMethods,
+		Duration:       int64(duration.Seconds()),
+		ExpiresAt:      resp.ExpiryDate,
+		Description:    resp.Description,
+		Status:         entity.InvoiceStatus(resp.Status),
+		XenditID:       resp.ID,
+		InvoiceURL:     resp.InvoiceURL,
+		SuccessURL:     successURL,
+		FailureURL:     failureURL,
+		Items:          items,
 	}
 
 	if err := uc.InvoiceRepository.Create(tx, invoice); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			// A concurrent request invoiced the order after the check above.
+			uc.Log.Warnf("Invoice already exists for order %s, expiring gateway invoice %s", request.OrderID, resp.ID)
+			if _, err := uc.PaymentGateway.ExpireInvoice(context.WithoutCancel(ctx), resp.ID); err != nil {
+				uc.Log.WithError(err).Errorf("Failed to expire duplicate gateway invoice %s", resp.ID)
+			}
+			return nil, ErrInvoiceAlreadyExists
+		}
 		uc.Log.WithError(err).Error("Failed to create invoice")
 		return nil, utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
 	}
 
+	if err := uc.InvoiceStatusUseCase.RecordHistory(tx, invoice, "", "create_invoice"); err != nil {
+		return nil, utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
+	}
+
+	if redemption != nil {
+		if err := uc.VoucherUseCase.Confirm(tx, redemption); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		uc.Log.WithError(err).Error("Failed to commit transaction")
 		return nil, utils.WrapMessageAsError(constants.FailedToCreateInvoice, err)
 	}
+	committed = true
 
 	response := &model.CreateInvoiceResponse{
-		ID:         invoice.ID.String(),
-		OrderID:    invoice.OrderID.String(),
-		XenditID:   invoice.XenditID,
-		InvoiceURL: invoice.InvoiceURL,
-		Amount:     invoice.Amount,
-		Status:     invoice.Status,
+		ID:             invoice.ID.String(),
+		OrderID:        invoice.OrderID.String(),
+		XenditID:       invoice.XenditID,
+		InvoiceURL:     invoice.InvoiceURL,
+		SuccessURL:     invoice.SuccessURL,
+		FailureURL:     invoice.FailureURL,
+		Amount:         invoice.Amount,
+		OrderAmount:    invoice.OrderAmount,
+		VoucherCode:    invoice.VoucherCode,
+		Discount:       invoice.Discount,
+		ExchangeRate:   invoice.ExchangeRate,
+		ExchangeRateAt: invoice.ExchangeRateAt,
+		Status:         string(invoice.Status),
+		ExpiresAt:      invoice.ExpiresAt,
+		PaymentMethods: invoice.AllowedMethods,
+		CustomerName:   invoice.CustomerName,
+		CustomerPhone:  invoice.CustomerPhone,
+		Locale:         invoice.Locale,
+		Items:          toInvoiceItemResponses(invoice.Items),
 	}
 
 	return response, nil
@@ -99,7 +265,7 @@ func (uc *PaymentUseCase) GetInvoiceByUserID(ctx context.Context, userID uuid.UU
 	tx := uc.DB.WithContext(ctx)
 	var invoices []entity.Invoice
 
-	if err := uc.InvoiceRepository.FindAllExceptDeletedByUserID(tx, userID, &invoices); err != nil {
+	if err := uc.InvoiceRepository.FindAllExceptDeletedByUserID(uc.InvoiceRepository.WithItems(tx), userID, &invoices); err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, utils.WrapMessageAsError(constants.InvoiceNotFound)
 		}
@@ -109,67 +275,130 @@ func (uc *PaymentUseCase) GetInvoiceByUserID(ctx context.Context, userID uuid.UU
 
 	var response []*model.InvoiceResponse
 	for _, inv := range invoices {
-		response = append(response, &model.InvoiceResponse{
-			ID:          inv.ID.String(),
-			OrderID:     inv.OrderID.String(),
-			XenditID:    inv.XenditID,
-			InvoiceURL:  inv.InvoiceURL,
-			Amount:      inv.Amount,
-			Status:      inv.Status,
-			PayerEmail:  inv.PayerEmail,
-			Description: inv.Description,
-		})
+		response = append(response, toInvoiceResponse(&inv))
 	}
 
 	return response, nil
 }
 
-func (uc *PaymentUseCase) GetInvoiceByOrderID(ctx context.Context, orderID uuid.UUID) (*model.InvoiceResponse, error) {
+func (uc *PaymentUseCase) SearchInvoices(ctx context.Context, request *model.SearchInvoiceRequest) ([]*model.InvoiceResponse, *model.PageMetadata, error) {
+	if err := uc.Validate.Struct(request); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, nil, utils.WrapMessageAsError(message, err)
+	}
+
+	if request.Page == 0 {
+		request.Page = 1
+	}
+	if request.PageSize == 0 {
+		request.PageSize = 10
+	}
+
+	conditions := []string{"1 = 1"}
+	var args []any
+
+	if request.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, request.UserID)
+	}
+	if request.Search != "" {
+		pattern := "%" + request.Search + "%"
+		conditions = append(conditions, "(order_id LIKE ? OR xendit_id LIKE ? OR payer_email LIKE ? OR description LIKE ?)")
+		args = append(args, pattern, pattern, pattern, pattern)
+	}
+	if request.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, request.Status)
+	}
+	if request.CreatedFrom != "" {
+		from, _ := time.ParseInLocation(time.DateOnly, request.CreatedFrom, time.Local)
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, from)
+	}
+	if request.CreatedTo != "" {
+		to, _ := time.ParseInLocation(time.DateOnly, request.CreatedTo, time.Local)
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, to.AddDate(0, 0, 1))
+	}
+	// Amounts are minor units of the invoice currency, so bounds only mean
+	// something within one currency, which validation makes mandatory.
+	if request.Currency != "" {
+		conditions = append(conditions, "currency = ?")
+		args = append(args, strings.ToUpper(request.Currency))
+	}
+	if request.MinAmount != nil {
+		conditions = append(conditions, "amount >= ?")
+		args = append(args, *request.MinAmount)
+	}
+	if request.MaxAmount != nil {
+		conditions = append(conditions, "amount <= ?")
+		args = append(args, *request.MaxAmount)
+	}
+
+	condition := strings.Join(conditions, " AND ")
 	tx := uc.DB.WithContext(ctx)
-	var invoice entity.Invoice
 
-	if err := uc.InvoiceRepository.FindByOrderID(tx, orderID, &invoice); err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, utils.WrapMessageAsError(constants.InvoiceNotFound)
-		}
-		uc.Log.WithError(err).Error("Failed to retrieve invoice by order ID")
-		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	total, err := uc.InvoiceRepository.CountByCondition(tx, condition, args...)
+	if err != nil {
+		uc.Log.WithError(err).Error("Failed to count invoices")
+		return nil, nil, utils.WrapMessageAsError(constants.InternalServerError, err)
 	}
 
-	response := &model.InvoiceResponse{
-		ID:          invoice.ID.String(),
-		OrderID:     invoice.OrderID.String(),
-		XenditID:    invoice.XenditID,
-		InvoiceURL:  invoice.InvoiceURL,
-		Amount:      invoice.Amount,
-		Status:      invoice.Status,
-		PayerEmail:  invoice.PayerEmail,
-		Description: invoice.Description,
+	var invoices []entity.Invoice
+	if err := uc.InvoiceRepository.FindByConditionWithPaginationAndOrder(uc.InvoiceRepository.WithItems(tx), &invoices, condition, request.Page, request.PageSize, invoiceSortOrder(request.Sort), args...); err != nil {
+		uc.Log.WithError(err).Error("Failed to retrieve invoices")
+		return nil, nil, utils.WrapMessageAsError(constants.InternalServerError, err)
 	}
 
-	return response, nil
+	response := make([]*model.InvoiceResponse, 0, len(invoices))
+	for _, inv := range invoices {
+		response = append(response, toInvoiceResponse(&inv))
+	}
+
+	totalPage := (total + int64(request.PageSize) - 1) / int64(request.PageSize)
+	paging := &model.PageMetadata{
+		CurrentPage: request.Page,
+		PageSize:    request.PageSize,
+		TotalItem:   total,
+		TotalPage:   totalPage,
+		HasNext:     int64(request.Page) < totalPage,
+		HasPrevious: request.Page > 1,
+	}
+
+	return response, paging, nil
 }
 
-func (uc *PaymentUseCase) GetInvoiceByID(ctx context.Context, orderID uuid.UUID) (*model.InvoiceResponse, error) {
+func (uc *PaymentUseCase) GetInvoiceByOrderID(ctx context.Context, orderID uuid.UUID) (*model.InvoiceResponse, error) {
 	tx := uc.DB.WithContext(ctx)
 	var invoice entity.Invoice
 
-	if err := uc.InvoiceRepository.FindByOrderID(tx, orderID, &invoice); err != nil {
-		return nil, nil
+	if err := uc.InvoiceRepository.FindLatestByOrderID(uc.InvoiceRepository.WithItems(tx), orderID, &invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		uc.Log.WithError(err).Error("Failed to retrieve invoice by order ID")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
 	}
 
-	response := &model.InvoiceResponse{
-		ID:          invoice.ID.String(),
-		OrderID:     invoice.OrderID.String(),
-		XenditID:    invoice.XenditID,
-		InvoiceURL:  invoice.InvoiceURL,
-		Amount:      invoice.Amount,
-		Status:      invoice.Status,
-		PayerEmail:  invoice.PayerEmail,
-		Description: invoice.Description,
-	}
+	return toInvoiceResponse(&invoice), nil
+}
 
-	return response, nil
+// ApplyGatewayInvoice applies the gateway's view of an invoice as if it had
+// arrived as a callback, so pulled updates take the same path as pushed ones.
+func (uc *PaymentUseCase) ApplyGatewayInvoice(ctx context.Context, provider *gateway.Invoice) (*model.InvoiceResponse, error) {
+	return uc.HandleXenditCallback(ctx, &model.XenditCallbackData{
+		ID:             provider.ID,
+		ExternalID:     provider.ExternalID,
+		Amount:         provider.Amount.Major(),
+		PaidAmount:     provider.PaidAmount.Major(),
+		Currency:       provider.Amount.Currency,
+		Status:         provider.Status,
+		PayerEmail:     provider.PayerEmail,
+		Description:    provider.Description,
+		PaymentMethod:  provider.PaymentMethod,
+		PaymentChannel: provider.PaymentChannel,
+		PaidAt:         provider.PaidAt,
+	})
 }
 
 func (uc *PaymentUseCase) HandleXenditCallback(ctx context.Context, callbackData *model.XenditCallbackData) (*model.InvoiceResponse, error) {
@@ -183,35 +412,101 @@ func (uc *PaymentUseCase) HandleXenditCallback(ctx context.Context, callbackData
 
 	orderID, err := uuid.Parse(callbackData.ExternalID)
 	if err != nil {
-		uc.Log.WithError(err).Error("Invalid invoice ID from Xendit callback")
+		uc.Log.WithError(err).Error("Invalid external ID from Xendit callback")
 		return nil, utils.WrapMessageAsError(constants.InvalidRequestData, err)
 	}
 
 	var invoice entity.Invoice
-	if err := uc.InvoiceRepository.FindByOrderID(tx, orderID, &invoice); err != nil {
+	if err := uc.InvoiceRepository.FindByXenditIDForUpdate(uc.InvoiceRepository.WithItems(tx), callbackData.ID, &invoice); err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, utils.WrapMessageAsError(constants.InvoiceNotFound)
+			return nil, ErrInvoiceNotFound
 		}
-		uc.Log.WithError(err).Error("Failed to find invoice by ID")
+		uc.Log.WithError(err).Error("Failed to find invoice by Xendit ID")
 		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
 	}
 
-	invoice.Status = callbackData.Status
-	invoice.Description = callbackData.Description
-	invoice.PaymentMethod = callbackData.PaymentMethod
-	invoice.PaymentChannel = callbackData.PaymentChannel
-	invoice.PayerEmail = callbackData.PayerEmail
-	invoice.XenditID = callbackData.ID
+	if invoice.OrderID != orderID {
+		uc.Log.Errorf("Xendit callback external ID %s does not match invoice order %s", orderID, invoice.OrderID)
+		return nil, utils.WrapMessageAsError(constants.InvalidRequestData)
+	}
+
+	previous := invoice.Status
+	next := entity.InvoiceStatus(callbackData.Status)
 
-	orderID, err = uuid.Parse(callbackData.ExternalID)
+	// A direct payment expires the hosted invoice once it has paid it, and
+	// that expiry must not be rejected as a transition away from PAID.
+	if next == entity.InvoiceStatusExpired && previous != entity.InvoiceStatusPending && invoice.PaidAt != nil {
+		uc.Log.Infof("Ignoring expiry of hosted invoice %s already paid through %s", invoice.XenditID, invoice.PaymentChannel)
+		return toInvoiceResponse(&invoice), nil
+	}
+
+	// The payer can still pay the hosted page after a virtual account or QRIS
+	// payment, until the hosted invoice is closed. That is a second charge,
+	// not a redelivery.
+	if next.IsPaid() && paidThroughAttempt(&invoice) {
+		return uc.flagHostedDuplicate(ctx, tx, &invoice, callbackData)
+	}
+
+	var anomaly *model.PaymentAnomaly
+	if next.IsPaid() {
+		received := callbackPaidAmount(callbackData, invoice.Amount.Currency)
+		if received.Currency != invoice.Amount.Currency {
+			uc.Log.Errorf("Xendit callback for invoice %s paid in %s, expected %s", invoice.ID, received.Currency, invoice.Amount.Currency)
+			return nil, uc.rejectCallback(ctx, tx, newPaymentAnomaly(AnomalyCurrencyMismatch, &invoice, received))
+		}
+
+		invoice.PaidAmount = received
+		invoice.PaidAt = callbackData.PaidAt
+		if invoice.PaidAt == nil {
+			now := time.Now()
+			invoice.PaidAt = &now
+		}
+		switch {
+		case received.Value < invoice.Amount.Value:
+			next = entity.InvoiceStatusUnderpaid
+			anomaly = newPaymentAnomaly(AnomalyUnderpayment, &invoice, received)
+		case received.Value > invoice.Amount.Value:
+			anomaly = newPaymentAnomaly(AnomalyOverpayment, &invoice, received)
+		}
+	}
+
+	changed, err := uc.InvoiceStatusUseCase.Transition(tx, &invoice, next, "xendit_callback")
 	if err != nil {
-		uc.Log.WithError(err).Error("Invalid external ID from Xendit callback")
-		return nil, utils.WrapMessageAsError(constants.InvalidRequestData, err)
+		return nil, err
 	}
 
-	if err := uc.InvoiceRepository.UpdateInvoice(tx, orderID, callbackData.ID, &invoice); err != nil {
-		uc.Log.WithError(err).Error("Failed to update invoice")
-		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	// Only the callback that first records the payment raises an anomaly, so a
+	// later SETTLED callback or a redelivery does not alert finance twice.
+	if anomaly != nil && (!changed || previous != entity.InvoiceStatusPending) {
+		anomaly = nil
+	}
+
+	if changed {
+		invoice.Description = callbackData.Description
+		invoice.PaymentMethod = callbackData.PaymentMethod
+		invoice.PaymentChannel = callbackData.PaymentChannel
+		invoice.PayerEmail = callbackData.PayerEmail
+
+		if err := uc.InvoiceRepository.UpdateInvoice(tx, orderID, callbackData.ID, &invoice); err != nil {
+			uc.Log.WithError(err).Error("Failed to update invoice")
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	response := toInvoiceResponse(&invoice)
+
+	if changed {
+		if err := uc.OutboxUseCase.Enqueue(tx, response.OrderID, EventInvoiceUpdated, response); err != nil {
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	if anomaly != nil {
+		anomaly.InvoiceStatus = string(invoice.Status)
+		uc.Log.Warnf("%s on invoice %s: expected %s, received %s", anomaly.Type, invoice.ID, anomaly.Expected, anomaly.Received)
+		if err := uc.OutboxUseCase.Enqueue(tx, response.OrderID, EventPaymentAnomaly, anomaly); err != nil {
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
 	}
 
 	if err := tx.Commit().Error; err != nil {
@@ -219,20 +514,108 @@ func (uc *PaymentUseCase) HandleXenditCallback(ctx context.Context, callbackData
 		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
 	}
 
-	response := &model.InvoiceResponse{
-		ID:          invoice.ID.String(),
-		OrderID:     invoice.OrderID.String(),
-		XenditID:    invoice.XenditID,
-		InvoiceURL:  invoice.InvoiceURL,
-		Amount:      invoice.Amount,
-		Status:      invoice.Status,
-		PayerEmail:  invoice.PayerEmail,
-		Description: invoice.Description,
+	if changed {
+		uc.InvoiceStatusUseCase.Publish(ctx, &invoice, previous, "xendit_callback")
+	}
+	if anomaly != nil {
+		uc.AnomalyUseCase.Record(ctx, anomaly)
+	}
+
+	return response, nil
+}
+
+// flagHostedDuplicate alerts finance to a hosted page payment on an invoice
+// already paid directly, once per hosted invoice, and leaves the invoice as
+// the direct payment settled it.
+func (uc *PaymentUseCase) flagHostedDuplicate(ctx context.Context, tx *gorm.DB, invoice *entity.Invoice, callbackData *model.XenditCallbackData) (*model.InvoiceResponse, error) {
+	response := toInvoiceResponse(invoice)
+	anomaly := newPaymentAnomaly(AnomalyDuplicatePayment, invoice, callbackPaidAmount(callbackData, invoice.Amount.Currency))
+
+	raised, err := uc.AnomalyUseCase.Claim(tx, callbackData.ID, anomaly)
+	if err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if !raised {
+		return response, nil
+	}
+
+	uc.Log.Warnf("%s on invoice %s: hosted invoice %s paid %s after %s payment", anomaly.Type, invoice.ID, invoice.XenditID, anomaly.Received, invoice.PaymentChannel)
+	if err := uc.OutboxUseCase.Enqueue(tx, response.OrderID, EventPaymentAnomaly, anomaly); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
 	}
 
+	uc.AnomalyUseCase.Record(ctx, anomaly)
 	return response, nil
 }
 
+// rejectCallback leaves the invoice untouched but still alerts on the
+// anomaly, so the stored webhook can be replayed once finance has reviewed it.
+// The alert goes out once per payment however often the callback is retried.
+func (uc *PaymentUseCase) rejectCallback(ctx context.Context, tx *gorm.DB, anomaly *model.PaymentAnomaly) error {
+	raised, err := uc.AnomalyUseCase.Claim(tx, anomaly.XenditID, anomaly)
+	if err != nil {
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if !raised {
+		uc.Log.Infof("%s on invoice %s was already raised", anomaly.Type, anomaly.InvoiceID)
+		return ErrCallbackCurrencyMismatch
+	}
+
+	if err := uc.OutboxUseCase.Enqueue(tx, anomaly.OrderID, EventPaymentAnomaly, anomaly); err != nil {
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.AnomalyUseCase.Record(ctx, anomaly)
+	return ErrCallbackCurrencyMismatch
+}
+
+// callbackPaidAmount reads the amount the payer actually paid. Xendit only
+// sends paid_amount on payment callbacks and omits the currency for invoices
+// in the account's default currency.
+func callbackPaidAmount(callbackData *model.XenditCallbackData, currency string) money.Money {
+	if callbackData.Currency != "" {
+		currency = callbackData.Currency
+	}
+	paid := callbackData.PaidAmount
+	if paid == 0 {
+		paid = callbackData.Amount
+	}
+	return money.FromMajor(paid, currency)
+}
+
+func paidThroughAttempt(invoice *entity.Invoice) bool {
+	return slices.ContainsFunc(invoice.Attempts, func(attempt entity.PaymentAttempt) bool {
+		return attempt.Status == entity.PaymentAttemptStatusPaid
+	})
+}
+
+func newPaymentAnomaly(kind string, invoice *entity.Invoice, received money.Money) *model.PaymentAnomaly {
+	anomaly := &model.PaymentAnomaly{
+		Event:         EventPaymentAnomaly,
+		Type:          kind,
+		InvoiceID:     invoice.ID.String(),
+		OrderID:       invoice.OrderID.String(),
+		XenditID:      invoice.XenditID,
+		InvoiceStatus: string(invoice.Status),
+		Expected:      invoice.Amount,
+		Received:      received,
+		Source:        "xendit_callback",
+		DetectedAt:    time.Now(),
+	}
+	if received.Currency == invoice.Amount.Currency {
+		anomaly.Difference = received.Sub(invoice.Amount)
+	}
+	return anomaly
+}
+
 func (uc *PaymentUseCase) CheckInvoiceExists(ctx context.Context, userID uuid.UUID, xenditID string) (bool, error) {
 	if xenditID == "" {
 		return false, utils.WrapMessageAsError(constants.InvalidRequestData)
@@ -272,3 +655,167 @@ func (uc *PaymentUseCase) DeleteInvoice(ctx context.Context, userID uuid.UUID, x
 
 	return nil
 }
+
+func invoiceSortOrder(sort string) string {
+	if sort == "" {
+		return "created_at DESC"
+	}
+	if column, ok := strings.CutPrefix(sort, "-"); ok {
+		return column + " DESC"
+	}
+	return sort + " ASC"
+}
+
+func (uc *PaymentUseCase) validateOrder(userID uuid.UUID, order *orderpb.GetOrderByIdResponse) error {
+	if order.GetUserId() != userID.String() {
+		uc.Log.Warnf("User %s attempted to create an invoice for order %s owned by %s", userID, order.GetId(), order.GetUserId())
+		return ErrOrderNotOwned
+	}
+
+	payable := uc.Viper.GetStringSlice("ORDER_PAYABLE_STATUSES")
+	if len(payable) == 0 {
+		payable = []string{"PENDING"}
+	}
+	if !slices.ContainsFunc(payable, func(status string) bool { return strings.EqualFold(status, order.GetStatus()) }) {
+		uc.Log.Warnf("Order %s is %s and cannot be paid", order.GetId(), order.GetStatus())
+		return ErrOrderNotPayable
+	}
+
+	var sum int64
+	for _, item := range order.GetItems() {
+		sum += item.GetPrice() * int64(item.GetQuantity())
+	}
+	if order.GetTotalAmount() <= 0 || sum != order.GetTotalAmount() {
+		uc.Log.Warnf("Order %s total %d does not match item sum %d", order.GetId(), order.GetTotalAmount(), sum)
+		return ErrOrderTotalMismatch
+	}
+
+	return nil
+}
+
+// invoicePaymentMethods leaves the default currency to the gateway's own
+// channel setup and narrows other currencies to the channels that settle them.
+func invoicePaymentMethods(currency string) ([]string, error) {
+	channels := gateway.ChannelsForCurrency(currency)
+	if len(channels) == 0 {
+		return nil, ErrUnsupportedCurrency
+	}
+	if currency == money.DefaultCurrency {
+		return nil, nil
+	}
+	return channels, nil
+}
+
+func toInvoiceResponse(invoice *entity.Invoice) *model.InvoiceResponse {
+	return &model.InvoiceResponse{
+		ID:             invoice.ID.String(),
+		OrderID:        invoice.OrderID.String(),
+		XenditID:       invoice.XenditID,
+		InvoiceURL:     invoice.InvoiceURL,
+		SuccessURL:     invoice.SuccessURL,
+		FailureURL:     invoice.FailureURL,
+		Amount:         invoice.Amount,
+		OrderAmount:    invoice.OrderAmount,
+		PaidAmount:     invoice.PaidAmount,
+		VoucherCode:    invoice.VoucherCode,
+		Discount:       invoice.Discount,
+		ExchangeRate:   invoice.ExchangeRate,
+		ExchangeRateAt: invoice.ExchangeRateAt,
+		Status:         string(invoice.Status),
+		PaidAt:         invoice.PaidAt,
+		ExpiresAt:      invoice.ExpiresAt,
+		PayerEmail:     invoice.PayerEmail,
+		PaymentMethods: invoice.AllowedMethods,
+		CustomerName:   invoice.CustomerName,
+		CustomerPhone:  invoice.CustomerPhone,
+		Locale:         invoice.Locale,
+		Description:    invoice.Description,
+		VirtualAccount: toVirtualAccountResponse(latestAttempt(invoice.Attempts, entity.PaymentAttemptMethodVirtualAccount)),
+		QRCode:         toQRCodeResponse(latestAttempt(invoice.Attempts, entity.PaymentAttemptMethodQRIS)),
+		Items:          toInvoiceItemResponses(invoice.Items),
+	}
+}
+
+func toInvoiceItems(orderItems []*orderpb.OrderItem, currency string) []entity.InvoiceItem {
+	items := make([]entity.InvoiceItem, 0, len(orderItems))
+	for i, orderItem := range orderItems {
+		items = append(items, entity.InvoiceItem{
+			ID:          uuid.New(),
+			OrderItemID: orderItem.GetId(),
+			ProductID:   orderItem.GetProductId(),
+			Quantity:    orderItem.GetQuantity(),
+			Price:       money.New(orderItem.GetPrice(), currency),
+			Position:    i,
+		})
+	}
+	return items
+}
+
+// toGatewayItems prices line items in the invoice currency at the rate the
+// invoice total was converted with. Each line is rounded on its own, so the
+// remainder that makes items and fees add up to the invoice amount goes on
+// the last line.
+func toGatewayItems(items []entity.InvoiceItem, rate float64, amount money.Money, fees []gateway.InvoiceFee) []gateway.InvoiceItem {
+	result := make([]gateway.InvoiceItem, 0, len(items)+1)
+	remainder := amount.Value
+	for _, fee := range fees {
+		remainder -= fee.Value.Value
+	}
+	for _, item := range items {
+		price := money.Convert(item.Price, rate, amount.Currency)
+		remainder -= price.Value * int64(item.Quantity)
+		result = append(result, gateway.InvoiceItem{
+			Name:     item.ProductID,
+			Quantity: int(item.Quantity),
+			Price:    price,
+		})
+	}
+	if remainder == 0 || len(result) == 0 {
+		return result
+	}
+
+	last := &result[len(result)-1]
+	if remainder%int64(last.Quantity) == 0 {
+		last.Price.Value += remainder / int64(last.Quantity)
+		return result
+	}
+	// The remainder does not split evenly over the units, so one unit of
+	// the last line carries it.
+	last.Quantity--
+	single := gateway.InvoiceItem{Name: last.Name, Quantity: 1, Price: last.Price}
+	single.Price.Value += remainder
+	return append(result, single)
+}
+
+// convertAt prices an amount with a rate that was already looked up.
+func convertAt(amount money.Money, rate *entity.ExchangeRate) money.Money {
+	if amount.Currency == rate.QuoteCurrency {
+		return amount
+	}
+	return money.Convert(amount, rate.Rate, rate.QuoteCurrency)
+}
+
+// discountFees passes a voucher discount to the gateway as a negative fee so
+// the hosted invoice shows it next to the full-priced items.
+func discountFees(discount money.Money, rate float64, currency string) []gateway.InvoiceFee {
+	if discount.IsZero() {
+		return nil
+	}
+	return []gateway.InvoiceFee{{
+		Type:  "DISCOUNT",
+		Value: money.Convert(money.New(-discount.Value, discount.Currency), rate, currency),
+	}}
+}
+
+func toInvoiceItemResponses(items []entity.InvoiceItem) []*model.InvoiceItemResponse {
+	response := make([]*model.InvoiceItemResponse, 0, len(items))
+	for i := range items {
+		response = append(response, &model.InvoiceItemResponse{
+			ProductID: items[i].ProductID,
+			Quantity:  items[i].Quantity,
+			Price:     items[i].Price,
+			Subtotal:  items[i].Subtotal(),
+		})
+	}
+	return response
+}
@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCreateInvoice(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+
+	response := env.createInvoice(t, userID, "", 150000, 50000)
+
+	assert.Equal(t, string(entity.InvoiceStatusPending), response.Status)
+	assert.Equal(t, int64(200000), response.Amount.Value)
+	assert.Equal(t, "IDR", response.Amount.Currency)
+	assert.Equal(t, response.Amount, response.OrderAmount)
+	assert.True(t, response.Discount.IsZero())
+	assert.Equal(t, float64(1), response.ExchangeRate)
+	assert.Nil(t, response.PaymentMethods)
+	assert.Len(t, response.Items, 2)
+
+	provider, err := env.Gateway.GetInvoice(context.Background(), response.XenditID)
+	require.NoError(t, err)
+	assert.Equal(t, response.OrderID, provider.ExternalID)
+	assert.Equal(t, response.Amount, provider.Amount)
+
+	invoice := env.findInvoice(t, response.ID)
+	assert.Equal(t, userID, invoice.UserID)
+	assert.Equal(t, entity.InvoiceStatusPending, invoice.Status)
+
+	var history []entity.InvoiceStatusHistory
+	require.NoError(t, env.DB.Find(&history, "invoice_id = ?", response.ID).Error)
+	require.Len(t, history, 1)
+	assert.Equal(t, entity.InvoiceStatusPending, history[0].ToStatus)
+
+	var notifications int64
+	require.NoError(t, env.DB.Model(&entity.Notification{}).Where("invoice_id = ?", response.ID).Count(&notifications).Error)
+	assert.Equal(t, int64(1), notifications)
+}
+
+func TestCreateInvoiceRejectsInvalidOrders(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+
+	existing := env.createInvoice(t, userID, "", 100000)
+	paid := env.createInvoice(t, userID, "", 100000)
+	_, err := env.pay(t, paid.XenditID, 100000)
+	require.NoError(t, err)
+	notPayable := env.Orders.Add(userID, 100000)
+	notPayable.Status = "CANCELLED"
+	mismatched := env.Orders.Add(userID, 100000)
+	mismatched.TotalAmount = 90000
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		orderID string
+		err     error
+	}{
+		{"unknown order", userID, uuid.NewString(), ErrOrderNotFound},
+		{"order of another user", uuid.New(), env.Orders.Add(userID, 100000).Id, ErrOrderNotOwned},
+		{"order not payable", userID, notPayable.Id, ErrOrderNotPayable},
+		{"total not matching items", userID, mismatched.Id, ErrOrderTotalMismatch},
+		{"invoice already exists", userID, existing.OrderID, ErrInvoiceAlreadyExists},
+		{"invoice already paid", userID, paid.OrderID, ErrInvoiceAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.PaymentUseCase.CreateInvoice(context.Background(), tt.userID, "payer@golectro.local", &model.CreateInvoiceRequest{
+				OrderID:     tt.orderID,
+				Description: "Golectro order",
+			})
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestCreateInvoiceAfterExpiry(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	expired := env.createInvoice(t, userID, "", 100000)
+
+	invoice := env.findInvoice(t, expired.ID)
+	require.NoError(t, env.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := env.InvoiceStatusUseCase.Transition(tx, invoice, entity.InvoiceStatusExpired, "test")
+		return err
+	}))
+	assert.Nil(t, env.findInvoice(t, expired.ID).OpenOrderID)
+
+	reissued, err := env.PaymentUseCase.CreateInvoice(context.Background(), userID, "payer@golectro.local", &model.CreateInvoiceRequest{
+		OrderID:     expired.OrderID,
+		Description: "Golectro order",
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, expired.ID, reissued.ID)
+}
+
+func TestInvoiceOpenOrderIsUnique(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 100000)
+	orderID := uuid.MustParse(created.OrderID)
+
+	// The row a concurrent request would insert after passing the existence
+	// check is refused by the open order index.
+	err := env.DB.Create(&entity.Invoice{
+		ID:          uuid.New(),
+		OrderID:     orderID,
+		OpenOrderID: &orderID,
+		Status:      entity.InvoiceStatusPending,
+	}).Error
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func TestCreateInvoiceRejectsUnsupportedPaymentMethods(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	order := env.Orders.Add(userID, 100000)
+
+	_, err := env.PaymentUseCase.CreateInvoice(context.Background(), userID, "payer@golectro.local", &model.CreateInvoiceRequest{
+		OrderID:        order.Id,
+		Description:    "Golectro order",
+		PaymentMethods: []string{"GCASH"},
+	})
+	assert.ErrorIs(t, err, ErrPaymentMethodNotAllowed)
+
+	var invoices int64
+	require.NoError(t, env.DB.Model(&entity.Invoice{}).Count(&invoices).Error)
+	assert.Zero(t, invoices)
+}
+
+func TestHandleXenditCallbackPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		paid    int64
+		status  entity.InvoiceStatus
+		anomaly string
+	}{
+		{"exact payment", 200000, entity.InvoiceStatusPaid, ""},
+		{"underpayment", 150000, entity.InvoiceStatusUnderpaid, AnomalyUnderpayment},
+		{"overpayment", 250000, entity.InvoiceStatusPaid, AnomalyOverpayment},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			created := env.createInvoice(t, uuid.New(), "", 200000)
+
+			response, err := env.pay(t, created.XenditID, tt.paid)
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.status), response.Status)
+			assert.Equal(t, tt.paid, response.PaidAmount.Value)
+			assert.NotNil(t, response.PaidAt)
+
+			invoice := env.findInvoice(t, created.ID)
+			assert.Equal(t, tt.status, invoice.Status)
+			assert.Equal(t, "BCA", invoice.PaymentChannel)
+
+			assert.Equal(t, 1, countOutboxEvents(t, env, EventInvoiceUpdated))
+			anomalies := outboxAnomalies(t, env)
+			if tt.anomaly == "" {
+				assert.Empty(t, anomalies)
+				return
+			}
+			require.Len(t, anomalies, 1)
+			assert.Equal(t, tt.anomaly, anomalies[0].Type)
+			assert.Equal(t, tt.paid-200000, anomalies[0].Difference.Value)
+			assert.Equal(t, string(tt.status), anomalies[0].InvoiceStatus)
+		})
+	}
+}
+
+func TestHandleXenditCallbackPaymentDetails(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+	callback := func(status string) *model.XenditCallbackData {
+		return &model.XenditCallbackData{
+			ID:          created.XenditID,
+			ExternalID:  created.OrderID,
+			Amount:      200000,
+			Status:      status,
+			PayerEmail:  "payer@golectro.local",
+			Description: "Golectro order",
+		}
+	}
+
+	// Xendit only reports how an invoice was paid once it is paid.
+	_, err := env.PaymentUseCase.HandleXenditCallback(context.Background(), callback("PAID"))
+	assert.Error(t, err)
+
+	response, err := env.PaymentUseCase.HandleXenditCallback(context.Background(), callback("EXPIRED"))
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.InvoiceStatusExpired), response.Status)
+}
+
+func TestHandleXenditCallbackRedeliveryIsIdempotent(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+
+	_, err := env.pay(t, created.XenditID, 150000)
+	require.NoError(t, err)
+	response, err := env.pay(t, created.XenditID, 150000)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(entity.InvoiceStatusUnderpaid), response.Status)
+	assert.Equal(t, 1, countOutboxEvents(t, env, EventInvoiceUpdated))
+	assert.Len(t, outboxAnomalies(t, env), 1)
+}
+
+func TestHandleXenditCallbackRejectsCurrencyMismatchOnce(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+	callback := &model.XenditCallbackData{
+		ID:             created.XenditID,
+		ExternalID:     created.OrderID,
+		Amount:         200000,
+		PaidAmount:     15,
+		Currency:       "USD",
+		Status:         "PAID",
+		PayerEmail:     "payer@golectro.local",
+		Description:    "Golectro order",
+		PaymentMethod:  "CREDIT_CARD",
+		PaymentChannel: "CREDIT_CARD",
+	}
+
+	for range 2 {
+		_, err := env.PaymentUseCase.HandleXenditCallback(context.Background(), callback)
+		assert.ErrorIs(t, err, ErrCallbackCurrencyMismatch)
+	}
+
+	assert.Equal(t, entity.InvoiceStatusPending, env.findInvoice(t, created.ID).Status)
+	anomalies := outboxAnomalies(t, env)
+	require.Len(t, anomalies, 1)
+	assert.Equal(t, AnomalyCurrencyMismatch, anomalies[0].Type)
+}
+
+func TestInvoicePaymentMethods(t *testing.T) {
+	tests := []struct {
+		currency string
+		methods  []string
+		err      error
+	}{
+		{"IDR", nil, nil},
+		{"PHP", []string{"CREDIT_CARD", "GCASH", "GRABPAY", "PAYMAYA", "SHOPEEPAY"}, nil},
+		{"USD", []string{"CREDIT_CARD"}, nil},
+		{"EUR", nil, ErrUnsupportedCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			methods, err := invoicePaymentMethods(tt.currency)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.methods, methods)
+		})
+	}
+}
+
+func TestToGatewayItemsAddUpAfterConversion(t *testing.T) {
+	const rate = 0.0000613
+	item := func(product string, quantity int32, price int64) entity.InvoiceItem {
+		return entity.InvoiceItem{ProductID: product, Quantity: quantity, Price: money.New(price, "IDR")}
+	}
+	usd := func(product string, quantity int, cents int64) gateway.InvoiceItem {
+		return gateway.InvoiceItem{Name: product, Quantity: quantity, Price: money.New(cents, "USD")}
+	}
+
+	tests := []struct {
+		name     string
+		items    []entity.InvoiceItem
+		discount int64
+		amount   int64
+		fee      int64
+		expected []gateway.InvoiceItem
+	}{
+		{
+			"remainder on the last line",
+			[]entity.InvoiceItem{item("a", 1, 33333), item("b", 1, 33333), item("c", 1, 33334)},
+			0, 613, 0,
+			[]gateway.InvoiceItem{usd("a", 1, 204), usd("b", 1, 204), usd("c", 1, 205)},
+		},
+		{
+			"remainder split off the last line",
+			[]entity.InvoiceItem{item("a", 1, 10050), item("b", 2, 24900)},
+			7500, 321, -46,
+			[]gateway.InvoiceItem{usd("a", 1, 62), usd("b", 1, 153), usd("b", 1, 152)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var total int64
+			for _, item := range tt.items {
+				total += item.Subtotal().Value
+			}
+			amount := money.Convert(money.New(total-tt.discount, "IDR"), rate, "USD")
+			require.Equal(t, tt.amount, amount.Value)
+
+			fees := discountFees(money.New(tt.discount, "IDR"), rate, "USD")
+			items := toGatewayItems(tt.items, rate, amount, fees)
+			assert.Equal(t, tt.expected, items)
+
+			sum := tt.fee
+			if tt.fee != 0 {
+				require.Len(t, fees, 1)
+				assert.Equal(t, tt.fee, fees[0].Value.Value)
+			}
+			for _, item := range items {
+				sum += item.Price.Value * int64(item.Quantity)
+			}
+			assert.Equal(t, amount.Value, sum)
+		})
+	}
+}
+
+func countOutboxEvents(t *testing.T, env *testEnv, eventType string) int {
+	t.Helper()
+
+	var count int64
+	require.NoError(t, env.DB.Model(&entity.OutboxEvent{}).Where("event_type = ?", eventType).Count(&count).Error)
+	return int(count)
+}
+
+func outboxAnomalies(t *testing.T, env *testEnv) []model.PaymentAnomaly {
+	t.Helper()
+
+	var events []entity.OutboxEvent
+	require.NoError(t, env.DB.Order("id").Find(&events, "event_type = ?", EventPaymentAnomaly).Error)
+
+	anomalies := make([]model.PaymentAnomaly, 0, len(events))
+	for _, event := range events {
+		var anomaly model.PaymentAnomaly
+		require.NoError(t, json.Unmarshal(event.Payload, &anomaly))
+		anomalies = append(anomalies, anomaly)
+	}
+	return anomalies
+}
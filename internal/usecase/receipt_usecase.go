@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/receipt"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+type ReceiptUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	Redis             *redis.Client
+	Viper             *viper.Viper
+	Branding          *model.Branding
+	InvoiceRepository *repository.InvoiceRepository
+}
+
+func NewReceiptUsecase(db *gorm.DB, log *logrus.Logger, viper *viper.Viper, branding *model.Branding, redis *redis.Client, invoiceRepository *repository.InvoiceRepository) *ReceiptUseCase {
+	return &ReceiptUseCase{
+		DB:                db,
+		Log:               log,
+		Redis:             redis,
+		Viper:             viper,
+		Branding:          branding,
+		InvoiceRepository: invoiceRepository,
+	}
+}
+
+// GetReceipt renders the invoice document for its owner. Rendered PDFs are
+// cached per invoice revision, so any status change produces a fresh one.
+func (uc *ReceiptUseCase) GetReceipt(ctx context.Context, userID, invoiceID uuid.UUID) ([]byte, error) {
+	var invoice entity.Invoice
+	tx := uc.InvoiceRepository.WithItems(uc.DB.WithContext(ctx))
+	if err := uc.InvoiceRepository.FindOne(tx, &invoice, "id = ? AND user_id = ?", invoiceID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		uc.Log.WithError(err).Error("Failed to retrieve invoice for receipt")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	key := fmt.Sprintf("payment:receipt:%s:%d", invoice.ID, invoice.UpdatedAt.UnixNano())
+	if cached, err := uc.Redis.Get(ctx, key).Bytes(); err == nil {
+		return cached, nil
+	} else if !errors.Is(err, redis.Nil) {
+		uc.Log.WithError(err).Warn("Failed to read cached receipt")
+	}
+
+	document, err := receipt.Render(&invoice, receipt.Options{
+		Brand:    uc.Branding.Name,
+		Location: uc.Branding.Location,
+	})
+	if err != nil {
+		uc.Log.WithError(err).Errorf("Failed to render receipt for invoice %s", invoice.ID)
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := uc.Redis.Set(ctx, key, document, uc.cacheTTL()).Err(); err != nil {
+		uc.Log.WithError(err).Warn("Failed to cache receipt")
+	}
+
+	return document, nil
+}
+
+func (uc *ReceiptUseCase) cacheTTL() time.Duration {
+	if ttl := uc.Viper.GetDuration("RECEIPT_CACHE_TTL"); ttl > 0 {
+		return ttl
+	}
+	return 24 * time.Hour
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+const (
+	DiscrepancyAmountMismatch   = "AMOUNT_MISMATCH"
+	DiscrepancyUnknownInvoice   = "UNKNOWN_PROVIDER_INVOICE"
+	DiscrepancyTransitionFailed = "TRANSITION_FAILED"
+	DiscrepancyGatewayError     = "GATEWAY_ERROR"
+
+	reconciliationLockKey = "payment:reconciliation:lock"
+)
+
+// Paid invoices only move on when the gateway settles them, so they are
+// checked while recent instead of on every run forever. Refunded invoices are
+// driven by refund callbacks, which the invoice status does not reflect.
+var reconcilablePaidStatuses = []entity.InvoiceStatus{entity.InvoiceStatusPaid, entity.InvoiceStatusUnderpaid}
+
+type ReconciliationUseCase struct {
+	DB                *gorm.DB
+	Log               *logrus.Logger
+	Redis             *redis.Client
+	Collection        *mongo.Collection
+	InvoiceRepository *repository.InvoiceRepository
+	PaymentUseCase    *PaymentUseCase
+	PaymentGateway    gateway.PaymentGateway
+	OlderThan         time.Duration
+	PaidWindow        time.Duration
+	BatchSize         int
+	LockTTL           time.Duration
+}
+
+func NewReconciliationUsecase(db *gorm.DB, log *logrus.Logger, viper *viper.Viper, redis *redis.Client, mongoDB *mongo.Database, invoiceRepository *repository.InvoiceRepository, paymentUseCase *PaymentUseCase, paymentGateway gateway.PaymentGateway) *ReconciliationUseCase {
+	uc := &ReconciliationUseCase{
+		DB:                db,
+		Log:               log,
+		Redis:             redis,
+		Collection:        mongoDB.Collection("reconciliation_reports"),
+		InvoiceRepository: invoiceRepository,
+		PaymentUseCase:    paymentUseCase,
+		PaymentGateway:    paymentGateway,
+		OlderThan:         viper.GetDuration("RECONCILE_OLDER_THAN"),
+		PaidWindow:        viper.GetDuration("RECONCILE_PAID_WINDOW"),
+		BatchSize:         viper.GetInt("RECONCILE_BATCH_SIZE"),
+		LockTTL:           viper.GetDuration("RECONCILE_LOCK_TTL"),
+	}
+
+	if uc.OlderThan <= 0 {
+		uc.OlderThan = 30 * time.Minute
+	}
+	if uc.PaidWindow <= 0 {
+		uc.PaidWindow = 7 * 24 * time.Hour
+	}
+	if uc.BatchSize <= 0 {
+		uc.BatchSize = 100
+	}
+	if uc.LockTTL <= 0 {
+		uc.LockTTL = 10 * time.Minute
+	}
+
+	return uc
+}
+
+// Reconcile returns a nil report when another replica holds the lock.
+func (uc *ReconciliationUseCase) Reconcile(ctx context.Context, trigger string, olderThan time.Duration) (*model.ReconciliationReport, error) {
+	if olderThan <= 0 {
+		olderThan = uc.OlderThan
+	}
+
+	token := uuid.NewString()
+	acquired, err := uc.Redis.SetNX(ctx, reconciliationLockKey, token, uc.LockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		uc.Log.Info("Reconciliation already running on another instance, skipping")
+		return nil, nil
+	}
+	defer uc.releaseLock(token)
+
+	report := &model.ReconciliationReport{
+		Trigger:       trigger,
+		OlderThan:     olderThan.String(),
+		Discrepancies: []model.ReconciliationDiscrepancy{},
+		StartedAt:     time.Now(),
+	}
+
+	before := report.StartedAt.Add(-olderThan)
+	paidSince := report.StartedAt.Add(-uc.PaidWindow)
+	var afterCreatedAt time.Time
+	afterID := uuid.Nil
+
+	for {
+		var invoices []entity.Invoice
+		if err := uc.InvoiceRepository.FindAllForReconciliation(uc.DB.WithContext(ctx), reconcilablePaidStatuses, before, paidSince, afterCreatedAt, afterID, uc.BatchSize, &invoices); err != nil {
+			return nil, err
+		}
+
+		for _, invoice := range invoices {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			uc.reconcileInvoice(ctx, &invoice, report)
+		}
+
+		if len(invoices) < uc.BatchSize {
+			break
+		}
+		last := invoices[len(invoices)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+
+	report.FinishedAt = time.Now()
+	if _, err := uc.Collection.InsertOne(ctx, report); err != nil {
+		uc.Log.WithError(err).Error("Failed to save reconciliation report")
+		return report, err
+	}
+
+	uc.Log.WithFields(logrus.Fields{
+		"checked":       report.Checked,
+		"updated":       report.Updated,
+		"discrepancies": len(report.Discrepancies),
+	}).Info("Reconciliation completed")
+	return report, nil
+}
+
+func (uc *ReconciliationUseCase) reconcileInvoice(ctx context.Context, invoice *entity.Invoice, report *model.ReconciliationReport) {
+	report.Checked++
+
+	discrepancy := model.ReconciliationDiscrepancy{
+		InvoiceID:   invoice.ID.String(),
+		OrderID:     invoice.OrderID.String(),
+		XenditID:    invoice.XenditID,
+		LocalStatus: string(invoice.Status),
+		LocalAmount: invoice.Amount,
+	}
+
+	provider, err := uc.PaymentGateway.GetInvoice(ctx, invoice.XenditID)
+	if err != nil {
+		discrepancy.Type = DiscrepancyGatewayError
+		if errors.Is(err, gateway.ErrInvoiceNotFound) {
+			discrepancy.Type = DiscrepancyUnknownInvoice
+		}
+		discrepancy.Detail = err.Error()
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+		return
+	}
+
+	discrepancy.ProviderStatus = provider.Status
+	discrepancy.ProviderAmount = &provider.Amount
+
+	// A mismatched amount needs a human; applying the provider status could
+	// mark an order paid for the wrong total.
+	if provider.Amount != invoice.Amount {
+		discrepancy.Type = DiscrepancyAmountMismatch
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+		return
+	}
+
+	if provider.Status == string(invoice.Status) {
+		return
+	}
+	// The hosted invoice of an invoice paid through a virtual account or QRIS
+	// is expired on purpose.
+	if provider.Status == string(entity.InvoiceStatusExpired) && invoice.PaidAt != nil {
+		return
+	}
+
+	response, err := uc.PaymentUseCase.ApplyGatewayInvoice(ctx, provider)
+	if err != nil {
+		discrepancy.Type = DiscrepancyTransitionFailed
+		discrepancy.Detail = err.Error()
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+		return
+	}
+	// An underpaid invoice stays underpaid while the gateway reports it PAID.
+	if response.Status == string(invoice.Status) {
+		return
+	}
+
+	uc.Log.Infof("Reconciled invoice %s from %s to %s", invoice.ID, invoice.Status, provider.Status)
+	report.Updated++
+}
+
+func (uc *ReconciliationUseCase) releaseLock(token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if value, err := uc.Redis.Get(ctx, reconciliationLockKey).Result(); err == nil && value == token {
+		uc.Redis.Del(ctx, reconciliationLockKey)
+	}
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileInvoice(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(t *testing.T, env *testEnv, invoice *entity.Invoice)
+		status      entity.InvoiceStatus
+		updated     int
+		discrepancy string
+	}{
+		{
+			name:   "unchanged at the gateway",
+			status: entity.InvoiceStatusPending,
+		},
+		{
+			name: "paid at the gateway",
+			prepare: func(t *testing.T, env *testEnv, invoice *entity.Invoice) {
+				_, err := env.Gateway.MarkInvoicePaid(invoice.XenditID, "EWALLET", "OVO")
+				require.NoError(t, err)
+			},
+			status:  entity.InvoiceStatusPaid,
+			updated: 1,
+		},
+		{
+			name: "amount differs from the gateway",
+			prepare: func(t *testing.T, env *testEnv, invoice *entity.Invoice) {
+				_, err := env.Gateway.MarkInvoicePaid(invoice.XenditID, "EWALLET", "OVO")
+				require.NoError(t, err)
+				invoice.Amount.Value = 100000
+			},
+			status:      entity.InvoiceStatusPending,
+			discrepancy: DiscrepancyAmountMismatch,
+		},
+		{
+			name: "unknown at the gateway",
+			prepare: func(t *testing.T, env *testEnv, invoice *entity.Invoice) {
+				invoice.XenditID = "missing"
+			},
+			status:      entity.InvoiceStatusPending,
+			discrepancy: DiscrepancyUnknownInvoice,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			created := env.createInvoice(t, uuid.New(), "", 200000)
+			invoice := env.findInvoice(t, created.ID)
+			if tt.prepare != nil {
+				tt.prepare(t, env, invoice)
+			}
+
+			report := &model.ReconciliationReport{}
+			env.ReconciliationUseCase.reconcileInvoice(context.Background(), invoice, report)
+
+			assert.Equal(t, 1, report.Checked)
+			assert.Equal(t, tt.updated, report.Updated)
+			assert.Equal(t, tt.status, env.findInvoice(t, created.ID).Status)
+			if tt.discrepancy == "" {
+				assert.Empty(t, report.Discrepancies)
+				return
+			}
+			require.Len(t, report.Discrepancies, 1)
+			assert.Equal(t, tt.discrepancy, report.Discrepancies[0].Type)
+		})
+	}
+}
+
+func TestReconciliationScope(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	now := time.Now()
+
+	invoiceCreatedAt := func(status entity.InvoiceStatus, age time.Duration) string {
+		created := env.createInvoice(t, userID, "", 200000)
+		require.NoError(t, env.DB.Model(&entity.Invoice{}).Where("id = ?", created.ID).
+			UpdateColumns(map[string]any{"status": status, "created_at": now.Add(-age)}).Error)
+		return created.ID
+	}
+
+	stalePending := invoiceCreatedAt(entity.InvoiceStatusPending, 30*24*time.Hour)
+	recentPaid := invoiceCreatedAt(entity.InvoiceStatusPaid, 24*time.Hour)
+	recentUnderpaid := invoiceCreatedAt(entity.InvoiceStatusUnderpaid, 24*time.Hour)
+	invoiceCreatedAt(entity.InvoiceStatusPaid, 30*24*time.Hour)
+	invoiceCreatedAt(entity.InvoiceStatusPending, time.Minute)
+	invoiceCreatedAt(entity.InvoiceStatusExpired, 24*time.Hour)
+	invoiceCreatedAt(entity.InvoiceStatusPartiallyRefunded, 24*time.Hour)
+
+	uc := env.ReconciliationUseCase
+	var invoices []entity.Invoice
+	require.NoError(t, uc.InvoiceRepository.FindAllForReconciliation(env.DB, reconcilablePaidStatuses, now.Add(-uc.OlderThan), now.Add(-uc.PaidWindow), time.Time{}, uuid.Nil, uc.BatchSize, &invoices))
+
+	ids := make([]string, 0, len(invoices))
+	for _, invoice := range invoices {
+		ids = append(ids, invoice.ID.String())
+	}
+	assert.ElementsMatch(t, []string{stalePending, recentPaid, recentUnderpaid}, ids)
+}
@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/delivery/grpc/client"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotRefundable = utils.WrapMessageAsError(constants.InvoiceNotRefundable)
+	ErrRefundAmountExceeded = utils.WrapMessageAsError(constants.RefundAmountExceeded)
+	ErrRefundNotFound       = utils.WrapMessageAsError(constants.RefundNotFound)
+	ErrOrderNotRefundable   = utils.WrapMessageAsError(constants.OrderNotRefundable)
+)
+
+var refundableInvoiceStatuses = []entity.InvoiceStatus{entity.InvoiceStatusPaid, entity.InvoiceStatusUnderpaid, entity.InvoiceStatusSettled, entity.InvoiceStatusPartiallyRefunded}
+
+type RefundUseCase struct {
+	DB                   *gorm.DB
+	Log                  *logrus.Logger
+	Validate             *validator.Validate
+	InvoiceRepository    *repository.InvoiceRepository
+	RefundRepository     *repository.RefundRepository
+	InvoiceStatusUseCase *InvoiceStatusUseCase
+	OutboxUseCase        *OutboxUseCase
+	PaymentGateway       gateway.PaymentGateway
+	OrderClient          *client.OrderClient
+	Viper                *viper.Viper
+}
+
+func NewRefundUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, viper *viper.Viper, invoiceRepository *repository.InvoiceRepository, refundRepository *repository.RefundRepository, invoiceStatusUseCase *InvoiceStatusUseCase, outboxUseCase *OutboxUseCase, paymentGateway gateway.PaymentGateway, orderClient *client.OrderClient) *RefundUseCase {
+	return &RefundUseCase{
+		DB:                   db,
+		Log:                  log,
+		Validate:             validate,
+		Viper:                viper,
+		InvoiceRepository:    invoiceRepository,
+		RefundRepository:     refundRepository,
+		InvoiceStatusUseCase: invoiceStatusUseCase,
+		OutboxUseCase:        outboxUseCase,
+		PaymentGateway:       paymentGateway,
+		OrderClient:          orderClient,
+	}
+}
+
+// CreateRefund refunds the payer's own invoice once the order service has put
+// its order in a refundable status.
+func (uc *RefundUseCase) CreateRefund(ctx context.Context, userID, invoiceID uuid.UUID, request *model.CreateRefundRequest) (*model.RefundResponse, error) {
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindOne(uc.DB.WithContext(ctx), &invoice, "id = ? AND user_id = ?", invoiceID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := uc.checkOrderRefundable(ctx, &invoice); err != nil {
+		return nil, err
+	}
+
+	return uc.createRefund(ctx, invoice.ID, request)
+}
+
+// CreateRefundByAdmin lets finance staff refund any user's invoice regardless
+// of the order status.
+func (uc *RefundUseCase) CreateRefundByAdmin(ctx context.Context, invoiceID uuid.UUID, request *model.CreateRefundRequest) (*model.RefundResponse, error) {
+	return uc.createRefund(ctx, invoiceID, request)
+}
+
+func (uc *RefundUseCase) checkOrderRefundable(ctx context.Context, invoice *entity.Invoice) error {
+	order, err := uc.OrderClient.GetOrderByID(ctx, invoice.OrderID.String())
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return ErrOrderNotRefundable
+		}
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	refundable := uc.Viper.GetStringSlice("ORDER_REFUNDABLE_STATUSES")
+	if len(refundable) == 0 {
+		refundable = []string{"CANCELLED"}
+	}
+	if !slices.ContainsFunc(refundable, func(status string) bool { return strings.EqualFold(status, order.GetStatus()) }) {
+		uc.Log.Warnf("Refund rejected for invoice %s, order %s is %s", invoice.ID, order.GetId(), order.GetStatus())
+		return ErrOrderNotRefundable
+	}
+
+	return nil
+}
+
+func (uc *RefundUseCase) createRefund(ctx context.Context, invoiceID uuid.UUID, request *model.CreateRefundRequest) (*model.RefundResponse, error) {
+	if err := uc.Validate.Struct(request); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindByIDForUpdate(tx, invoiceID, &invoice); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if !slices.Contains(refundableInvoiceStatuses, invoice.Status) {
+		uc.Log.Warnf("Refund rejected for invoice %s in status %s", invoice.ID, invoice.Status)
+		return nil, ErrInvoiceNotRefundable
+	}
+
+	reserved, err := uc.RefundRepository.SumAmountByInvoiceID(tx, invoice.ID, entity.RefundStatusPending, entity.RefundStatusSucceeded)
+	if err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	refundable := invoice.CollectedAmount().Sub(money.New(reserved, invoice.Amount.Currency))
+	amount := money.New(request.Amount, invoice.Amount.Currency)
+	if amount.IsZero() {
+		amount = refundable
+	}
+	if amount.Value <= 0 || amount.Value > refundable.Value {
+		uc.Log.Warnf("Refund amount %s exceeds refundable %s for invoice %s", amount, refundable, invoice.ID)
+		return nil, ErrRefundAmountExceeded
+	}
+
+	refund := &entity.Refund{
+		ID:        uuid.New(),
+		InvoiceID: invoice.ID,
+		UserID:    invoice.UserID,
+		Amount:    amount,
+		Reason:    request.Reason,
+		Status:    entity.RefundStatusPending,
+	}
+
+	if err := uc.RefundRepository.Create(tx, refund); err != nil {
+		uc.Log.WithError(err).Error("Failed to create refund")
+		return nil, utils.WrapMessageAsError(constants.FailedToCreateRefund, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.FailedToCreateRefund, err)
+	}
+
+	resp, err := uc.PaymentGateway.Refund(ctx, &gateway.RefundParams{
+		InvoiceID:   invoice.XenditID,
+		ReferenceID: refund.ID.String(),
+		Amount:      amount,
+		Reason:      request.Reason,
+	})
+	if err != nil && !gateway.IsRejected(err) {
+		// The gateway may have accepted the refund before the error, so it
+		// stays PENDING and keeps its amount reserved until the callback
+		// settles it.
+		uc.Log.WithError(err).Warnf("Refund %s outcome unknown, waiting for the gateway callback", refund.ID)
+		return uc.applyRefundUpdate(ctx, refund.ID, "", entity.RefundStatusPending, "")
+	}
+	if err != nil {
+		uc.Log.WithError(err).Error("Payment gateway rejected refund")
+		if _, markErr := uc.applyRefundUpdate(ctx, refund.ID, "", entity.RefundStatusFailed, err.Error()); markErr != nil {
+			uc.Log.WithError(markErr).Error("Failed to mark refund as failed")
+		}
+		return nil, utils.WrapMessageAsError(constants.FailedToCreateRefund, err)
+	}
+
+	return uc.applyRefundUpdate(ctx, refund.ID, resp.ID, entity.RefundStatus(resp.Status), "")
+}
+
+func (uc *RefundUseCase) HandleXenditRefundCallback(ctx context.Context, callback *model.XenditRefundCallback) (*model.RefundResponse, error) {
+	if err := uc.Validate.Struct(callback); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+
+	refundID, err := uuid.Parse(callback.Data.ReferenceID)
+	if err != nil {
+		uc.Log.WithError(err).Error("Invalid reference ID from Xendit refund callback")
+		return nil, utils.WrapMessageAsError(constants.InvalidRequestData, err)
+	}
+
+	return uc.applyRefundUpdate(ctx, refundID, callback.Data.ID, entity.RefundStatus(callback.Data.Status), callback.Data.FailureCode)
+}
+
+func (uc *RefundUseCase) applyRefundUpdate(ctx context.Context, refundID uuid.UUID, xenditRefundID string, status entity.RefundStatus, failureCode string) (*model.RefundResponse, error) {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	var refund entity.Refund
+	if err := uc.RefundRepository.FindByIDForUpdate(tx, refundID, &refund); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefundNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindByIDForUpdate(tx, refund.InvoiceID, &invoice); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if xenditRefundID != "" {
+		refund.XenditRefundID = xenditRefundID
+	}
+
+	completed := false
+	if refund.Status == entity.RefundStatusPending && status != entity.RefundStatusPending {
+		now := time.Now()
+		refund.Status = status
+		refund.FailureCode = failureCode
+		refund.CompletedAt = &now
+		completed = status == entity.RefundStatusSucceeded
+	} else if refund.Status != status {
+		uc.Log.Warnf("Ignoring refund %s update to %s, refund is already %s", refund.ID, status, refund.Status)
+	}
+
+	if err := uc.RefundRepository.Update(tx, &refund); err != nil {
+		uc.Log.WithError(err).Error("Failed to update refund")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	refunded, err := uc.RefundRepository.SumAmountByInvoiceID(tx, invoice.ID, entity.RefundStatusSucceeded)
+	if err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	previous := invoice.Status
+	statusChanged := false
+	if completed {
+		next := entity.InvoiceStatusPartiallyRefunded
+		if refunded >= invoice.CollectedAmount().Value {
+			next = entity.InvoiceStatusRefunded
+		}
+
+		if statusChanged, err = uc.InvoiceStatusUseCase.Transition(tx, &invoice, next, "refund"); err != nil {
+			uc.Log.WithError(err).Error("Failed to update invoice status after refund")
+			return nil, err
+		}
+	}
+
+	response := toRefundResponse(&refund, &invoice, money.New(refunded, invoice.Amount.Currency))
+
+	if completed {
+		event := &model.RefundEvent{
+			Event:          EventRefundCompleted,
+			RefundID:       response.ID,
+			InvoiceID:      response.InvoiceID,
+			OrderID:        response.OrderID,
+			Amount:         response.Amount,
+			RefundedAmount: response.RefundedAmount,
+			InvoiceStatus:  response.InvoiceStatus,
+		}
+		if err := uc.OutboxUseCase.Enqueue(tx, response.OrderID, EventRefundCompleted, event); err != nil {
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if statusChanged {
+		uc.InvoiceStatusUseCase.Publish(ctx, &invoice, previous, "refund")
+	}
+
+	return response, nil
+}
+
+func (uc *RefundUseCase) GetRefundsByInvoiceID(ctx context.Context, userID, invoiceID uuid.UUID) ([]*model.RefundResponse, error) {
+	tx := uc.DB.WithContext(ctx)
+
+	var invoice entity.Invoice
+	if err := uc.InvoiceRepository.FindOne(tx, &invoice, "id = ? AND user_id = ?", invoiceID, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	var refunds []entity.Refund
+	if err := uc.RefundRepository.FindAllByInvoiceID(tx, invoiceID, &refunds); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	refunded := money.New(0, invoice.Amount.Currency)
+	for _, refund := range refunds {
+		if refund.Status == entity.RefundStatusSucceeded {
+			refunded = refunded.Add(refund.Amount)
+		}
+	}
+
+	response := make([]*model.RefundResponse, 0, len(refunds))
+	for i := range refunds {
+		response = append(response, toRefundResponse(&refunds[i], &invoice, refunded))
+	}
+	return response, nil
+}
+
+func toRefundResponse(refund *entity.Refund, invoice *entity.Invoice, refunded money.Money) *model.RefundResponse {
+	return &model.RefundResponse{
+		ID:             refund.ID.String(),
+		InvoiceID:      refund.InvoiceID.String(),
+		OrderID:        invoice.OrderID.String(),
+		XenditRefundID: refund.XenditRefundID,
+		Amount:         refund.Amount,
+		Reason:         refund.Reason,
+		Status:         string(refund.Status),
+		FailureCode:    refund.FailureCode,
+		InvoiceStatus:  string(invoice.Status),
+		RefundedAmount: refunded,
+	}
+}
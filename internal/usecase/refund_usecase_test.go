@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refundFailingGateway answers refund requests with the given error, as the
+// gateway client does on timeouts and rejected requests.
+type refundFailingGateway struct {
+	*gateway.FakeGateway
+	err error
+}
+
+func (g *refundFailingGateway) Refund(ctx context.Context, params *gateway.RefundParams) (*gateway.Refund, error) {
+	return nil, g.err
+}
+
+// refundableInvoice creates a paid invoice whose order the order service has
+// since cancelled.
+func (env *testEnv) refundableInvoice(t *testing.T, userID uuid.UUID, amount int64) *model.CreateInvoiceResponse {
+	t.Helper()
+
+	created := env.createInvoice(t, userID, "", amount)
+	_, err := env.pay(t, created.XenditID, amount)
+	require.NoError(t, err)
+	env.Orders.SetStatus(created.OrderID, "CANCELLED")
+	return created
+}
+
+func TestCreateRefund(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.refundableInvoice(t, userID, 200000)
+	invoiceID := uuid.MustParse(created.ID)
+
+	partial, err := env.RefundUseCase.CreateRefund(context.Background(), userID, invoiceID, &model.CreateRefundRequest{Amount: 50000, Reason: "REQUESTED_BY_CUSTOMER"})
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.RefundStatusSucceeded), partial.Status)
+	assert.NotEmpty(t, partial.XenditRefundID)
+	assert.Equal(t, int64(50000), partial.RefundedAmount.Value)
+	assert.Equal(t, string(entity.InvoiceStatusPartiallyRefunded), partial.InvoiceStatus)
+
+	_, err = env.RefundUseCase.CreateRefund(context.Background(), userID, invoiceID, &model.CreateRefundRequest{Amount: 150001, Reason: "REQUESTED_BY_CUSTOMER"})
+	assert.ErrorIs(t, err, ErrRefundAmountExceeded)
+
+	rest, err := env.RefundUseCase.CreateRefund(context.Background(), userID, invoiceID, &model.CreateRefundRequest{Reason: "CANCELLATION"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(150000), rest.Amount.Value)
+	assert.Equal(t, int64(200000), rest.RefundedAmount.Value)
+	assert.Equal(t, string(entity.InvoiceStatusRefunded), rest.InvoiceStatus)
+
+	assert.Equal(t, entity.InvoiceStatusRefunded, env.findInvoice(t, created.ID).Status)
+	assert.Equal(t, 2, countOutboxEvents(t, env, EventRefundCompleted))
+}
+
+func TestCreateRefundRejections(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+
+	unpaid := env.createInvoice(t, userID, "", 200000)
+	env.Orders.SetStatus(unpaid.OrderID, "CANCELLED")
+	orderActive := env.createInvoice(t, userID, "", 200000)
+	_, err := env.pay(t, orderActive.XenditID, 200000)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		invoiceID string
+		err       error
+	}{
+		{"invoice not paid", userID, unpaid.ID, ErrInvoiceNotRefundable},
+		{"order not cancelled", userID, orderActive.ID, ErrOrderNotRefundable},
+		{"invoice of another user", uuid.New(), unpaid.ID, ErrInvoiceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := env.RefundUseCase.CreateRefund(context.Background(), tt.userID, uuid.MustParse(tt.invoiceID), &model.CreateRefundRequest{Reason: "CANCELLATION"})
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestCreateRefundByAdmin(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.createInvoice(t, userID, "", 200000)
+	_, err := env.pay(t, created.XenditID, 200000)
+	require.NoError(t, err)
+
+	response, err := env.RefundUseCase.CreateRefundByAdmin(context.Background(), uuid.MustParse(created.ID), &model.CreateRefundRequest{Amount: 50000, Reason: "OTHERS"})
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.RefundStatusSucceeded), response.Status)
+	assert.Equal(t, string(entity.InvoiceStatusPartiallyRefunded), response.InvoiceStatus)
+
+	var refund entity.Refund
+	require.NoError(t, env.DB.Take(&refund, "id = ?", response.ID).Error)
+	assert.Equal(t, userID, refund.UserID)
+}
+
+func TestCreateRefundGatewayErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status entity.RefundStatus
+	}{
+		{"rejected", fmt.Errorf("%w: amount exceeds refundable amount", gateway.ErrRequestRejected), entity.RefundStatusFailed},
+		{"outcome unknown", errors.New("context deadline exceeded"), entity.RefundStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			userID := uuid.New()
+			created := env.refundableInvoice(t, userID, 200000)
+			env.RefundUseCase.PaymentGateway = &refundFailingGateway{FakeGateway: env.Gateway, err: tt.err}
+			invoiceID := uuid.MustParse(created.ID)
+
+			response, err := env.RefundUseCase.CreateRefund(context.Background(), userID, invoiceID, &model.CreateRefundRequest{Reason: "CANCELLATION"})
+			if tt.status == entity.RefundStatusFailed {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, string(tt.status), response.Status)
+			}
+
+			var refunds []entity.Refund
+			require.NoError(t, env.DB.Find(&refunds, "invoice_id = ?", created.ID).Error)
+			require.Len(t, refunds, 1)
+			assert.Equal(t, tt.status, refunds[0].Status)
+			assert.Equal(t, entity.InvoiceStatusPaid, env.findInvoice(t, created.ID).Status)
+
+			// A pending refund keeps its amount reserved until the gateway
+			// reports the outcome, a failed one gives it back.
+			env.RefundUseCase.PaymentGateway = env.Gateway
+			_, err = env.RefundUseCase.CreateRefund(context.Background(), userID, invoiceID, &model.CreateRefundRequest{Amount: 1, Reason: "CANCELLATION"})
+			if tt.status == entity.RefundStatusFailed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrRefundAmountExceeded)
+			}
+		})
+	}
+}
+
+func TestHandleXenditRefundCallback(t *testing.T) {
+	env := newTestEnv(t)
+	userID := uuid.New()
+	created := env.refundableInvoice(t, userID, 200000)
+	env.RefundUseCase.PaymentGateway = &refundFailingGateway{FakeGateway: env.Gateway, err: errors.New("connection reset by peer")}
+
+	pending, err := env.RefundUseCase.CreateRefund(context.Background(), userID, uuid.MustParse(created.ID), &model.CreateRefundRequest{Amount: 50000, Reason: "CANCELLATION"})
+	require.NoError(t, err)
+
+	callback := &model.XenditRefundCallback{
+		Event: "refund.succeeded",
+		Data: model.XenditRefundCallbackData{
+			ID:          "rfd-1",
+			ReferenceID: pending.ID,
+			Amount:      50000,
+			Status:      string(entity.RefundStatusSucceeded),
+		},
+	}
+	response, err := env.RefundUseCase.HandleXenditRefundCallback(context.Background(), callback)
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.RefundStatusSucceeded), response.Status)
+	assert.Equal(t, "rfd-1", response.XenditRefundID)
+	assert.Equal(t, string(entity.InvoiceStatusPartiallyRefunded), response.InvoiceStatus)
+
+	// A late failure report cannot undo a refund that already succeeded.
+	callback.Data.Status = string(entity.RefundStatusFailed)
+	response, err = env.RefundUseCase.HandleXenditRefundCallback(context.Background(), callback)
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.RefundStatusSucceeded), response.Status)
+	assert.Equal(t, 1, countOutboxEvents(t, env, EventRefundCompleted))
+}
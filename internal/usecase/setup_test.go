@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/delivery/grpc/client"
+	orderpb "golectro-payment/internal/delivery/grpc/proto/order"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/gateway"
+	"golectro-payment/internal/mailer"
+	"golectro-payment/internal/migrations"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// The repositories target MySQL, so the SQLite connections used by the tests
+// get the functions they rely on.
+var registerTestDriver = sync.OnceFunc(func() {
+	sql.Register("sqlite3_usecase_test", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("greatest", func(a, b int64) int64 {
+				return max(a, b)
+			}, true)
+		},
+	})
+})
+
+type testEnv struct {
+	DB      *gorm.DB
+	Gateway *gateway.FakeGateway
+	Orders  *fakeOrderService
+
+	VoucherUseCase       *VoucherUseCase
+	InvoiceStatusUseCase *InvoiceStatusUseCase
+	PaymentUseCase       *PaymentUseCase
+	RefundUseCase        *RefundUseCase
+	DirectPaymentUseCase *DirectPaymentUseCase
+	WebhookUseCase       *WebhookUseCase
+	OrderEventUseCase    *OrderEventUseCase
+
+	ReconciliationUseCase *ReconciliationUseCase
+}
+
+// newTestEnv wires the use cases as Bootstrap does, against an in-memory
+// database, the fake payment gateway and an in-process order service. Redis
+// and MongoDB point at closed ports, which the use cases only log about.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	v := viper.New()
+	validate := validator.New()
+	utils.RegisterValidations(validate)
+
+	db := newTestDB(t)
+	orders := newFakeOrderService(t, v)
+
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { rdb.Close() })
+
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { mongoClient.Disconnect(context.Background()) })
+
+	paymentGateway := gateway.NewFakeGateway(log)
+	branding := &model.Branding{Name: "Golectro", Location: time.UTC}
+
+	invoiceRepository := repository.NewInvoiceRepository(log)
+	notificationUseCase := NewNotificationUsecase(db, log, v, branding, repository.NewNotificationRepository(log), invoiceRepository, mailer.NewLogSender(log))
+	voucherUseCase := NewVoucherUsecase(db, log, validate, v, repository.NewVoucherRepository(log), repository.NewVoucherRedemptionRepository(log))
+	invoiceStatusUseCase := NewInvoiceStatusUsecase(db, log, rdb, invoiceRepository, repository.NewInvoiceStatusHistoryRepository(log), notificationUseCase, voucherUseCase)
+	outboxUseCase := NewOutboxUsecase(log, repository.NewOutboxRepository(log))
+	exchangeRateUseCase := NewExchangeRateUsecase(db, log, validate, v, rdb, repository.NewExchangeRateRepository(log))
+	anomalyUseCase := NewAnomalyUsecase(log, mongoClient.Database("payment_test"), repository.NewRaisedAnomalyRepository(log))
+	orderClient := client.NewOrderClient(log, v)
+	paymentUseCase := NewPaymentUsecase(db, log, validate, v, invoiceRepository, invoiceStatusUseCase, outboxUseCase, exchangeRateUseCase, anomalyUseCase, voucherUseCase, paymentGateway, orderClient)
+	refundUseCase := NewRefundUsecase(db, log, validate, v, invoiceRepository, repository.NewRefundRepository(log), invoiceStatusUseCase, outboxUseCase, paymentGateway, orderClient)
+	directPaymentUseCase := NewDirectPaymentUsecase(db, log, validate, v, branding, rdb, invoiceRepository, repository.NewPaymentAttemptRepository(log), invoiceStatusUseCase, outboxUseCase, anomalyUseCase, paymentGateway)
+
+	return &testEnv{
+		DB:                    db,
+		Gateway:               paymentGateway,
+		Orders:                orders,
+		VoucherUseCase:        voucherUseCase,
+		InvoiceStatusUseCase:  invoiceStatusUseCase,
+		PaymentUseCase:        paymentUseCase,
+		RefundUseCase:         refundUseCase,
+		DirectPaymentUseCase:  directPaymentUseCase,
+		OrderEventUseCase:     NewOrderEventUsecase(db, log, validate, invoiceRepository, invoiceStatusUseCase, outboxUseCase, exchangeRateUseCase, voucherUseCase, paymentUseCase, paymentGateway, orderClient),
+		WebhookUseCase:        NewWebhookUsecase(db, log, repository.NewWebhookEventRepository(log), paymentUseCase, refundUseCase, directPaymentUseCase),
+		ReconciliationUseCase: NewReconciliationUsecase(db, log, v, rdb, mongoClient.Database("payment_test"), invoiceRepository, paymentUseCase, paymentGateway),
+	}
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	registerTestDriver()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared&_busy_timeout=5000", uuid.NewString())
+	db, err := gorm.Open(sqlite.New(sqlite.Config{DriverName: "sqlite3_usecase_test", DSN: dsn}), &gorm.Config{
+		Logger:         logger.Discard,
+		TranslateError: true,
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	require.NoError(t, migrations.Migrate(db))
+	return db
+}
+
+// fakeOrderService serves the orders registered with Add and reports any
+// other order as not found.
+type fakeOrderService struct {
+	orderpb.UnimplementedOrderServiceServer
+	mu     sync.Mutex
+	orders map[string]*orderpb.GetOrderByIdResponse
+}
+
+func newFakeOrderService(t *testing.T, v *viper.Viper) *fakeOrderService {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	service := &fakeOrderService{orders: make(map[string]*orderpb.GetOrderByIdResponse)}
+	server := grpc.NewServer()
+	orderpb.RegisterOrderServiceServer(server, service)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	v.Set("GRPC_ORDER_SERVICE", listener.Addr().String())
+	return service
+}
+
+func (s *fakeOrderService) GetOrderByID(ctx context.Context, request *orderpb.GetOrderByIdRequest) (*orderpb.GetOrderByIdResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[request.GetId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "order not found")
+	}
+	return order, nil
+}
+
+// Add registers a pending order of the user whose items add up to the total.
+func (s *fakeOrderService) Add(userID uuid.UUID, prices ...int64) *orderpb.GetOrderByIdResponse {
+	order := &orderpb.GetOrderByIdResponse{
+		Id:     uuid.NewString(),
+		UserId: userID.String(),
+		Status: "PENDING",
+	}
+	for i, price := range prices {
+		order.Items = append(order.Items, &orderpb.OrderItem{
+			Id:        uuid.NewString(),
+			ProductId: fmt.Sprintf("product-%d", i+1),
+			Quantity:  1,
+			Price:     price,
+		})
+		order.TotalAmount += price
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[order.Id] = order
+	return order
+}
+
+func (s *fakeOrderService) SetStatus(orderID, status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders[orderID].Status = status
+}
+
+// createInvoice creates an IDR invoice for a new order of the user.
+func (env *testEnv) createInvoice(t *testing.T, userID uuid.UUID, voucherCode string, prices ...int64) *model.CreateInvoiceResponse {
+	t.Helper()
+
+	response, err := env.tryCreateInvoice(userID, voucherCode, prices...)
+	require.NoError(t, err)
+	return response
+}
+
+func (env *testEnv) tryCreateInvoice(userID uuid.UUID, voucherCode string, prices ...int64) (*model.CreateInvoiceResponse, error) {
+	order := env.Orders.Add(userID, prices...)
+	return env.PaymentUseCase.CreateInvoice(context.Background(), userID, "payer@golectro.local", &model.CreateInvoiceRequest{
+		OrderID:     order.Id,
+		Description: "Golectro order",
+		VoucherCode: voucherCode,
+	})
+}
+
+// pay marks the invoice paid at the fake gateway and applies it as the
+// payment callback would, reporting paid as the amount the payer sent.
+func (env *testEnv) pay(t *testing.T, xenditID string, paid int64) (*model.InvoiceResponse, error) {
+	t.Helper()
+
+	provider, err := env.Gateway.MarkInvoicePaid(xenditID, "BANK_TRANSFER", "BCA")
+	require.NoError(t, err)
+	provider.PaidAmount.Value = paid
+	return env.PaymentUseCase.ApplyGatewayInvoice(context.Background(), provider)
+}
+
+func (env *testEnv) findInvoice(t *testing.T, id string) *entity.Invoice {
+	t.Helper()
+
+	invoice := new(entity.Invoice)
+	require.NoError(t, env.DB.Take(invoice, "id = ?", id).Error)
+	return invoice
+}
+
+func (env *testEnv) createVoucher(t *testing.T, voucher *entity.Voucher) *entity.Voucher {
+	t.Helper()
+
+	voucher.ID = uuid.New()
+	voucher.Active = true
+	if voucher.Currency == "" {
+		voucher.Currency = "IDR"
+	}
+	require.NoError(t, env.DB.Create(voucher).Error)
+	return voucher
+}
@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrVoucherNotFound          = utils.WrapMessageAsError(constants.VoucherNotFound)
+	ErrVoucherNotActive         = utils.WrapMessageAsError(constants.VoucherNotActive)
+	ErrVoucherMinimumSpend      = utils.WrapMessageAsError(constants.VoucherMinimumSpend)
+	ErrVoucherUsageLimitReached = utils.WrapMessageAsError(constants.VoucherUsageLimitReached)
+	ErrVoucherUserLimitReached  = utils.WrapMessageAsError(constants.VoucherUserLimitReached)
+	ErrVoucherNotApplicable     = utils.WrapMessageAsError(constants.VoucherNotApplicable)
+	ErrVoucherInvalidPercentage = utils.WrapMessageAsError(constants.VoucherInvalidPercentage)
+	ErrVoucherInvalidPeriod     = utils.WrapMessageAsError(constants.VoucherInvalidPeriod)
+)
+
+type VoucherUseCase struct {
+	DB                          *gorm.DB
+	Log                         *logrus.Logger
+	Validate                    *validator.Validate
+	VoucherRepository           *repository.VoucherRepository
+	VoucherRedemptionRepository *repository.VoucherRedemptionRepository
+	ReservationTTL              time.Duration
+}
+
+func NewVoucherUsecase(db *gorm.DB, log *logrus.Logger, validate *validator.Validate, viper *viper.Viper, voucherRepository *repository.VoucherRepository, voucherRedemptionRepository *repository.VoucherRedemptionRepository) *VoucherUseCase {
+	uc := &VoucherUseCase{
+		DB:                          db,
+		Log:                         log,
+		Validate:                    validate,
+		VoucherRepository:           voucherRepository,
+		VoucherRedemptionRepository: voucherRedemptionRepository,
+		ReservationTTL:              viper.GetDuration("VOUCHER_RESERVATION_TTL"),
+	}
+
+	if uc.ReservationTTL <= 0 {
+		uc.ReservationTTL = 15 * time.Minute
+	}
+
+	return uc
+}
+
+// Redeem applies a voucher to an invoice about to be created in tx. The
+// voucher row stays locked until tx ends, so concurrent checkouts cannot
+// both take the last use.
+func (uc *VoucherUseCase) Redeem(tx *gorm.DB, code string, userID, orderID, invoiceID uuid.UUID, total money.Money) (*entity.VoucherRedemption, error) {
+	return uc.redeem(tx, code, userID, orderID, invoiceID, total, entity.VoucherRedemptionRedeemed)
+}
+
+// Reserve takes a voucher use for an invoice that is yet to be created at the
+// gateway. It commits on its own, so the voucher row is only locked for the
+// checks and not for the gateway call. The reservation must be confirmed in
+// the transaction creating the invoice, or cancelled if that fails. One left
+// behind by a crash is released once ReservationTTL has passed.
+func (uc *VoucherUseCase) Reserve(ctx context.Context, code string, userID, orderID, invoiceID uuid.UUID, total money.Money) (*entity.VoucherRedemption, error) {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	redemption, err := uc.redeem(tx, code, userID, orderID, invoiceID, total, entity.VoucherRedemptionReserved)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	return redemption, nil
+}
+
+func (uc *VoucherUseCase) Confirm(tx *gorm.DB, redemption *entity.VoucherRedemption) error {
+	confirmed, err := uc.VoucherRedemptionRepository.UpdateStatus(tx, redemption.ID, entity.VoucherRedemptionReserved, entity.VoucherRedemptionRedeemed, nil)
+	if err != nil {
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if !confirmed {
+		return utils.WrapMessageAsError(constants.InternalServerError, errors.New("voucher reservation is no longer held"))
+	}
+	redemption.Status = entity.VoucherRedemptionRedeemed
+	return nil
+}
+
+// ReleaseExpiredReservations cancels reservations whose checkout never
+// confirmed or cancelled them, and reports how many it released.
+func (uc *VoucherUseCase) ReleaseExpiredReservations(ctx context.Context, limit int) (int, error) {
+	var redemptions []entity.VoucherRedemption
+	if err := uc.VoucherRedemptionRepository.FindExpiredReservations(uc.DB.WithContext(ctx), time.Now(), limit, &redemptions); err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for i := range redemptions {
+		if err := uc.CancelReservation(ctx, &redemptions[i]); err != nil {
+			return released, err
+		}
+		if redemptions[i].Status == entity.VoucherRedemptionReleased {
+			uc.Log.Warnf("Released voucher reservation %s for invoice %s that was never confirmed", redemptions[i].ID, redemptions[i].InvoiceID)
+			released++
+		}
+	}
+	return released, nil
+}
+
+// CancelReservation gives back a use taken by Reserve for an invoice that was
+// never created. A reservation that was confirmed in the meantime is kept.
+func (uc *VoucherUseCase) CancelReservation(ctx context.Context, redemption *entity.VoucherRedemption) error {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	cancelled, err := uc.VoucherRedemptionRepository.UpdateStatus(tx, redemption.ID, entity.VoucherRedemptionReserved, entity.VoucherRedemptionReleased, map[string]any{"released_at": time.Now()})
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return nil
+	}
+	if err := uc.VoucherRepository.AddUsage(tx, redemption.VoucherID, -1); err != nil {
+		uc.Log.WithError(err).Error("Failed to update voucher usage")
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return err
+	}
+	redemption.Status = entity.VoucherRedemptionReleased
+	return nil
+}
+
+func (uc *VoucherUseCase) redeem(tx *gorm.DB, code string, userID, orderID, invoiceID uuid.UUID, total money.Money, status entity.VoucherRedemptionStatus) (*entity.VoucherRedemption, error) {
+	voucher := new(entity.Voucher)
+	if err := uc.VoucherRepository.FindByCodeForUpdate(tx, normalizeVoucherCode(code), voucher); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVoucherNotFound
+		}
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if !voucher.Active {
+		return nil, ErrVoucherNotFound
+	}
+	if !voucher.IsValidAt(time.Now()) {
+		return nil, ErrVoucherNotActive
+	}
+	if voucher.Currency != total.Currency {
+		return nil, ErrVoucherNotApplicable
+	}
+	if total.Value < voucher.MinSpend {
+		return nil, ErrVoucherMinimumSpend
+	}
+	if voucher.UsageLimit > 0 && voucher.UsedCount >= voucher.UsageLimit {
+		return nil, ErrVoucherUsageLimitReached
+	}
+	if voucher.PerUserLimit > 0 {
+		used, err := uc.VoucherRedemptionRepository.CountRedeemedByUser(tx, voucher.ID, userID)
+		if err != nil {
+			return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+		}
+		if used >= int64(voucher.PerUserLimit) {
+			return nil, ErrVoucherUserLimitReached
+		}
+	}
+
+	// The gateway cannot bill a zero amount, so a voucher may not cover the
+	// whole order.
+	discount := voucher.Discount(total)
+	if discount.IsZero() || discount.Value >= total.Value {
+		return nil, ErrVoucherNotApplicable
+	}
+
+	redemption := &entity.VoucherRedemption{
+		ID:        uuid.New(),
+		VoucherID: voucher.ID,
+		UserID:    userID,
+		InvoiceID: invoiceID,
+		OrderID:   orderID,
+		Discount:  discount,
+		Status:    status,
+	}
+	if status == entity.VoucherRedemptionReserved {
+		reservedUntil := time.Now().Add(uc.ReservationTTL)
+		redemption.ReservedUntil = &reservedUntil
+	}
+	if err := uc.VoucherRedemptionRepository.Create(tx, redemption); err != nil {
+		uc.Log.WithError(err).Error("Failed to record voucher redemption")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if err := uc.VoucherRepository.AddUsage(tx, voucher.ID, 1); err != nil {
+		uc.Log.WithError(err).Error("Failed to update voucher usage")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.Log.WithFields(logrus.Fields{
+		"voucher":    voucher.Code,
+		"invoice_id": invoiceID,
+		"discount":   discount.String(),
+	}).Info("Voucher redeemed")
+	return redemption, nil
+}
+
+// Release gives the invoice's voucher use back, so an unpaid invoice does not
+// count against the voucher's limits.
+func (uc *VoucherUseCase) Release(tx *gorm.DB, invoice *entity.Invoice) error {
+	if invoice.VoucherCode == "" {
+		return nil
+	}
+
+	redemption := new(entity.VoucherRedemption)
+	if err := uc.VoucherRedemptionRepository.FindRedeemedByInvoiceID(tx, invoice.ID, redemption); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		uc.Log.WithError(err).Error("Failed to find voucher redemption")
+		return err
+	}
+
+	now := time.Now()
+	redemption.Status = entity.VoucherRedemptionReleased
+	redemption.ReleasedAt = &now
+	if err := uc.VoucherRedemptionRepository.Update(tx, redemption); err != nil {
+		uc.Log.WithError(err).Error("Failed to release voucher redemption")
+		return err
+	}
+	if err := uc.VoucherRepository.AddUsage(tx, redemption.VoucherID, -1); err != nil {
+		uc.Log.WithError(err).Error("Failed to update voucher usage")
+		return err
+	}
+
+	uc.Log.Infof("Released voucher %s from invoice %s", invoice.VoucherCode, invoice.ID)
+	return nil
+}
+
+func (uc *VoucherUseCase) ListVouchers(ctx context.Context) ([]*model.VoucherResponse, error) {
+	var vouchers []entity.Voucher
+	if err := uc.VoucherRepository.FindAllOrdered(uc.DB.WithContext(ctx), &vouchers); err != nil {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	response := make([]*model.VoucherResponse, 0, len(vouchers))
+	for i := range vouchers {
+		response = append(response, toVoucherResponse(&vouchers[i]))
+	}
+	return response, nil
+}
+
+// UpsertVoucher creates or updates a voucher by code. Usage counters are
+// never written from the request, so editing a running campaign keeps them.
+func (uc *VoucherUseCase) UpsertVoucher(ctx context.Context, updatedBy string, request *model.UpsertVoucherRequest) (*model.VoucherResponse, error) {
+	request.Code = normalizeVoucherCode(request.Code)
+	request.Type = strings.ToUpper(request.Type)
+	request.Currency = strings.ToUpper(request.Currency)
+
+	if err := uc.Validate.Struct(request); err != nil {
+		message := utils.TranslateValidationError(uc.Validate, err)
+		return nil, utils.WrapMessageAsError(message, err)
+	}
+	if request.Type == string(entity.VoucherTypePercentage) && request.Value > 100 {
+		return nil, ErrVoucherInvalidPercentage
+	}
+	if request.StartsAt != nil && request.EndsAt != nil && !request.EndsAt.After(*request.StartsAt) {
+		return nil, ErrVoucherInvalidPeriod
+	}
+	if request.Currency == "" {
+		request.Currency = money.DefaultCurrency
+	}
+
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	voucher := new(entity.Voucher)
+	err := uc.VoucherRepository.FindByCodeForUpdate(tx, request.Code, voucher)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		voucher.ID = uuid.New()
+		voucher.Code = request.Code
+		voucher.Active = true
+	}
+	voucher.Description = request.Description
+	voucher.Type = entity.VoucherType(request.Type)
+	voucher.Value = request.Value
+	voucher.MaxDiscount = request.MaxDiscount
+	voucher.MinSpend = request.MinSpend
+	voucher.Currency = request.Currency
+	voucher.UsageLimit = request.UsageLimit
+	voucher.PerUserLimit = request.PerUserLimit
+	voucher.StartsAt = request.StartsAt
+	voucher.EndsAt = request.EndsAt
+	if request.Active != nil {
+		voucher.Active = *request.Active
+	}
+	voucher.UpdatedBy = updatedBy
+
+	if err := uc.VoucherRepository.Update(tx, voucher); err != nil {
+		uc.Log.WithError(err).Error("Failed to save voucher")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return nil, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	uc.Log.Infof("Voucher %s saved by %s", voucher.Code, updatedBy)
+	return toVoucherResponse(voucher), nil
+}
+
+// DeactivateVoucher stops new redemptions but keeps the voucher and its
+// redemptions for reporting.
+func (uc *VoucherUseCase) DeactivateVoucher(ctx context.Context, updatedBy, code string) error {
+	tx := uc.DB.WithContext(ctx).Begin()
+	defer tx.Rollback()
+
+	voucher := new(entity.Voucher)
+	if err := uc.VoucherRepository.FindByCodeForUpdate(tx, normalizeVoucherCode(code), voucher); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVoucherNotFound
+		}
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	voucher.Active = false
+	voucher.UpdatedBy = updatedBy
+	if err := uc.VoucherRepository.Update(tx, voucher); err != nil {
+		uc.Log.WithError(err).Error("Failed to deactivate voucher")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		uc.Log.WithError(err).Error("Failed to commit transaction")
+		return utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	return nil
+}
+
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toVoucherResponse(voucher *entity.Voucher) *model.VoucherResponse {
+	return &model.VoucherResponse{
+		Code:         voucher.Code,
+		Description:  voucher.Description,
+		Type:         string(voucher.Type),
+		Value:        voucher.Value,
+		MaxDiscount:  voucher.MaxDiscount,
+		MinSpend:     voucher.MinSpend,
+		Currency:     voucher.Currency,
+		UsageLimit:   voucher.UsageLimit,
+		PerUserLimit: voucher.PerUserLimit,
+		UsedCount:    voucher.UsedCount,
+		StartsAt:     voucher.StartsAt,
+		EndsAt:       voucher.EndsAt,
+		Active:       voucher.Active,
+		UpdatedBy:    voucher.UpdatedBy,
+		UpdatedAt:    voucher.UpdatedAt,
+	}
+}
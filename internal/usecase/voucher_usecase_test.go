@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCreateInvoiceWithVoucher(t *testing.T) {
+	env := newTestEnv(t)
+	voucher := env.createVoucher(t, &entity.Voucher{Code: "HEMAT10", Type: entity.VoucherTypePercentage, Value: 10, MaxDiscount: 15000})
+
+	response := env.createInvoice(t, uuid.New(), " hemat10 ", 200000)
+
+	assert.Equal(t, "HEMAT10", response.VoucherCode)
+	assert.Equal(t, int64(15000), response.Discount.Value)
+	assert.Equal(t, int64(200000), response.OrderAmount.Value)
+	assert.Equal(t, int64(185000), response.Amount.Value)
+
+	provider, err := env.Gateway.GetInvoice(context.Background(), response.XenditID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(185000), provider.Amount.Value)
+
+	assert.Equal(t, 1, usedCount(t, env, voucher))
+	assert.Equal(t, []entity.VoucherRedemptionStatus{entity.VoucherRedemptionRedeemed}, redemptionStatuses(t, env, voucher))
+}
+
+func TestCreateInvoiceVoucherLimits(t *testing.T) {
+	now := time.Now()
+	yesterday, tomorrow := now.Add(-24*time.Hour), now.Add(24*time.Hour)
+
+	tests := []struct {
+		name     string
+		voucher  *entity.Voucher
+		inactive bool
+		err      error
+	}{
+		{"unknown code", nil, false, ErrVoucherNotFound},
+		{"inactive", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 10000}, true, ErrVoucherNotFound},
+		{"not started", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 10000, StartsAt: &tomorrow}, false, ErrVoucherNotActive},
+		{"ended", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 10000, EndsAt: &yesterday}, false, ErrVoucherNotActive},
+		{"other currency", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 1000, Currency: "USD"}, false, ErrVoucherNotApplicable},
+		{"below minimum spend", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 10000, MinSpend: 150000}, false, ErrVoucherMinimumSpend},
+		{"covering the whole order", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 100000}, false, ErrVoucherNotApplicable},
+		{"usage limit reached", &entity.Voucher{Type: entity.VoucherTypeFixed, Value: 10000, UsageLimit: 5, UsedCount: 5}, false, ErrVoucherUsageLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			if tt.voucher != nil {
+				tt.voucher.Code = "PROMO"
+				env.createVoucher(t, tt.voucher)
+			}
+			if tt.inactive {
+				require.NoError(t, env.DB.Model(tt.voucher).Update("active", false).Error)
+			}
+
+			_, err := env.tryCreateInvoice(uuid.New(), "PROMO", 100000)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestCreateInvoiceVoucherUsageLimits(t *testing.T) {
+	env := newTestEnv(t)
+	voucher := env.createVoucher(t, &entity.Voucher{Code: "ONCE", Type: entity.VoucherTypeFixed, Value: 10000, UsageLimit: 2, PerUserLimit: 1})
+	first, second := uuid.New(), uuid.New()
+
+	env.createInvoice(t, first, "ONCE", 100000)
+
+	_, err := env.tryCreateInvoice(first, "ONCE", 100000)
+	assert.ErrorIs(t, err, ErrVoucherUserLimitReached)
+
+	env.createInvoice(t, second, "ONCE", 100000)
+
+	_, err = env.tryCreateInvoice(uuid.New(), "ONCE", 100000)
+	assert.ErrorIs(t, err, ErrVoucherUsageLimitReached)
+	assert.Equal(t, 2, usedCount(t, env, voucher))
+}
+
+func TestCreateInvoiceCancelsVoucherReservationOnFailure(t *testing.T) {
+	env := newTestEnv(t)
+	voucher := env.createVoucher(t, &entity.Voucher{Code: "ONCE", Type: entity.VoucherTypeFixed, Value: 10000, UsageLimit: 1})
+	userID := uuid.New()
+	order := env.Orders.Add(userID, 100000)
+
+	// No IDR to USD rate is configured, so the invoice fails after the
+	// voucher use has been reserved.
+	_, err := env.PaymentUseCase.CreateInvoice(context.Background(), userID, "payer@golectro.local", &model.CreateInvoiceRequest{
+		OrderID:     order.Id,
+		Description: "Golectro order",
+		Currency:    "USD",
+		VoucherCode: "ONCE",
+	})
+	assert.ErrorIs(t, err, ErrExchangeRateNotFound)
+
+	assert.Equal(t, 0, usedCount(t, env, voucher))
+	assert.Equal(t, []entity.VoucherRedemptionStatus{entity.VoucherRedemptionReleased}, redemptionStatuses(t, env, voucher))
+
+	env.createInvoice(t, userID, "ONCE", 100000)
+	assert.Equal(t, 1, usedCount(t, env, voucher))
+}
+
+func TestReleaseExpiredReservations(t *testing.T) {
+	env := newTestEnv(t)
+	voucher := env.createVoucher(t, &entity.Voucher{Code: "ONCE", Type: entity.VoucherTypeFixed, Value: 10000, UsageLimit: 2})
+	total := money.New(100000, "IDR")
+
+	// A checkout that crashed during the gateway call leaves its reservation
+	// behind, while another is still waiting for the gateway.
+	env.VoucherUseCase.ReservationTTL = -time.Minute
+	_, err := env.VoucherUseCase.Reserve(context.Background(), "ONCE", uuid.New(), uuid.New(), uuid.New(), total)
+	require.NoError(t, err)
+	env.VoucherUseCase.ReservationTTL = time.Hour
+	_, err = env.VoucherUseCase.Reserve(context.Background(), "ONCE", uuid.New(), uuid.New(), uuid.New(), total)
+	require.NoError(t, err)
+	_, err = env.tryCreateInvoice(uuid.New(), "ONCE", 100000)
+	assert.ErrorIs(t, err, ErrVoucherUsageLimitReached)
+
+	released, err := env.VoucherUseCase.ReleaseExpiredReservations(context.Background(), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	assert.Equal(t, 1, usedCount(t, env, voucher))
+	assert.ElementsMatch(t, []entity.VoucherRedemptionStatus{entity.VoucherRedemptionReleased, entity.VoucherRedemptionReserved}, redemptionStatuses(t, env, voucher))
+
+	env.createInvoice(t, uuid.New(), "ONCE", 100000)
+}
+
+func TestExpiredInvoiceReleasesVoucher(t *testing.T) {
+	env := newTestEnv(t)
+	voucher := env.createVoucher(t, &entity.Voucher{Code: "ONCE", Type: entity.VoucherTypeFixed, Value: 10000, PerUserLimit: 1})
+	userID := uuid.New()
+	created := env.createInvoice(t, userID, "ONCE", 100000)
+
+	invoice := env.findInvoice(t, created.ID)
+	require.NoError(t, env.DB.Transaction(func(tx *gorm.DB) error {
+		_, err := env.InvoiceStatusUseCase.Transition(tx, invoice, entity.InvoiceStatusExpired, "test")
+		return err
+	}))
+
+	assert.Equal(t, 0, usedCount(t, env, voucher))
+	assert.Equal(t, []entity.VoucherRedemptionStatus{entity.VoucherRedemptionReleased}, redemptionStatuses(t, env, voucher))
+
+	env.createInvoice(t, userID, "ONCE", 100000)
+}
+
+func usedCount(t *testing.T, env *testEnv, voucher *entity.Voucher) int {
+	t.Helper()
+
+	current := new(entity.Voucher)
+	require.NoError(t, env.DB.Take(current, "id = ?", voucher.ID).Error)
+	return current.UsedCount
+}
+
+func redemptionStatuses(t *testing.T, env *testEnv, voucher *entity.Voucher) []entity.VoucherRedemptionStatus {
+	t.Helper()
+
+	var redemptions []entity.VoucherRedemption
+	require.NoError(t, env.DB.Order("created_at").Find(&redemptions, "voucher_id = ?", voucher.ID).Error)
+
+	statuses := make([]entity.VoucherRedemptionStatus, 0, len(redemptions))
+	for _, redemption := range redemptions {
+		statuses = append(statuses, redemption.Status)
+	}
+	return statuses
+}
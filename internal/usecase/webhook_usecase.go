@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"golectro-payment/internal/constants"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	WebhookProviderXendit   = "xendit"
+	WebhookEventTypeInvoice = "invoice"
+	WebhookEventTypeRefund  = "refund"
+
+	WebhookEventTypeVirtualAccount = "virtual_account"
+	WebhookEventTypeQRCode         = "qr_code"
+)
+
+var ErrInvalidWebhookPayload = utils.WrapMessageAsError(constants.InvalidRequestData)
+
+var redactedWebhookHeaders = []string{"X-Callback-Token", "Authorization"}
+
+type WebhookUseCase struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	WebhookEventRepository *repository.WebhookEventRepository
+	PaymentUseCase         *PaymentUseCase
+	RefundUseCase          *RefundUseCase
+	DirectPaymentUseCase   *DirectPaymentUseCase
+}
+
+func NewWebhookUsecase(db *gorm.DB, log *logrus.Logger, webhookEventRepository *repository.WebhookEventRepository, paymentUseCase *PaymentUseCase, refundUseCase *RefundUseCase, directPaymentUseCase *DirectPaymentUseCase) *WebhookUseCase {
+	return &WebhookUseCase{
+		DB:                     db,
+		Log:                    log,
+		WebhookEventRepository: webhookEventRepository,
+		PaymentUseCase:         paymentUseCase,
+		RefundUseCase:          refundUseCase,
+		DirectPaymentUseCase:   directPaymentUseCase,
+	}
+}
+
+// Receive stores the delivery verbatim and reports whether an earlier
+// delivery of the same provider event was already processed successfully.
+func (uc *WebhookUseCase) Receive(ctx context.Context, eventType string, header http.Header, body []byte) (*entity.WebhookEvent, bool, error) {
+	eventID, err := webhookEventID(eventType, header, body)
+	if err != nil {
+		uc.Log.WithError(err).Warn("Unable to derive webhook event ID")
+		return nil, false, ErrInvalidWebhookPayload
+	}
+
+	headers, err := json.Marshal(redactWebhookHeaders(header))
+	if err != nil {
+		return nil, false, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	tx := uc.DB.WithContext(ctx)
+	event := &entity.WebhookEvent{
+		ID:         uuid.New(),
+		Provider:   WebhookProviderXendit,
+		EventID:    eventID,
+		EventType:  eventType,
+		Headers:    headers,
+		Body:       string(body),
+		Status:     entity.WebhookEventStatusReceived,
+		Deliveries: 1,
+		ReceivedAt: time.Now(),
+	}
+
+	created, err := uc.WebhookEventRepository.CreateIfNotExists(tx, event)
+	if err != nil {
+		return nil, false, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if created {
+		return event, false, nil
+	}
+
+	existing := new(entity.WebhookEvent)
+	if err := uc.WebhookEventRepository.FindByProviderEventID(tx, WebhookProviderXendit, eventID, existing); err != nil {
+		return nil, false, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+	if err := uc.WebhookEventRepository.IncrementDeliveries(tx, existing); err != nil {
+		return nil, false, utils.WrapMessageAsError(constants.InternalServerError, err)
+	}
+
+	duplicate := existing.Status == entity.WebhookEventStatusProcessed
+	uc.Log.WithFields(logrus.Fields{
+		"event_id":  eventID,
+		"status":    existing.Status,
+		"duplicate": duplicate,
+	}).Info("Received redelivered webhook event")
+	return existing, duplicate, nil
+}
+
+func (uc *WebhookUseCase) HandleInvoiceEvent(ctx context.Context, event *entity.WebhookEvent) (*model.InvoiceResponse, error) {
+	request := new(model.XenditCallbackData)
+	if err := json.Unmarshal([]byte(event.Body), request); err != nil {
+		uc.recordOutcome(ctx, event, err)
+		return nil, ErrInvalidWebhookPayload
+	}
+
+	result, err := uc.PaymentUseCase.HandleXenditCallback(ctx, request)
+	uc.recordOutcome(ctx, event, err)
+	return result, err
+}
+
+func (uc *WebhookUseCase) HandleRefundEvent(ctx context.Context, event *entity.WebhookEvent) (*model.RefundResponse, error) {
+	request := new(model.XenditRefundCallback)
+	if err := json.Unmarshal([]byte(event.Body), request); err != nil {
+		uc.recordOutcome(ctx, event, err)
+		return nil, ErrInvalidWebhookPayload
+	}
+
+	result, err := uc.RefundUseCase.HandleXenditRefundCallback(ctx, request)
+	uc.recordOutcome(ctx, event, err)
+	return result, err
+}
+
+func (uc *WebhookUseCase) HandleVirtualAccountEvent(ctx context.Context, event *entity.WebhookEvent) (*model.InvoiceResponse, error) {
+	request := new(model.XenditVirtualAccountCallback)
+	if err := json.Unmarshal([]byte(event.Body), request); err != nil {
+		uc.recordOutcome(ctx, event, err)
+		return nil, ErrInvalidWebhookPayload
+	}
+
+	result, err := uc.DirectPaymentUseCase.HandleVirtualAccountCallback(ctx, request)
+	uc.recordOutcome(ctx, event, err)
+	return result, err
+}
+
+func (uc *WebhookUseCase) HandleQRCodeEvent(ctx context.Context, event *entity.WebhookEvent) (*model.InvoiceResponse, error) {
+	request := new(model.XenditQRCodeCallback)
+	if err := json.Unmarshal([]byte(event.Body), request); err != nil {
+		uc.recordOutcome(ctx, event, err)
+		return nil, ErrInvalidWebhookPayload
+	}
+
+	result, err := uc.DirectPaymentUseCase.HandleQRCodeCallback(ctx, request)
+	uc.recordOutcome(ctx, event, err)
+	return result, err
+}
+
+func (uc *WebhookUseCase) Replay(ctx context.Context, eventIDs []string, from, to time.Time) (int, int, error) {
+	tx := uc.DB.WithContext(ctx)
+
+	var events []entity.WebhookEvent
+	var err error
+	if len(eventIDs) > 0 {
+		err = uc.WebhookEventRepository.FindAllByEventIDs(tx, WebhookProviderXendit, eventIDs, &events)
+	} else {
+		err = uc.WebhookEventRepository.FindAllByReceivedAtRange(tx, WebhookProviderXendit, from, to, &events)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	processed, failed := 0, 0
+	for i := range events {
+		event := &events[i]
+
+		var handleErr error
+		switch event.EventType {
+		case WebhookEventTypeInvoice:
+			_, handleErr = uc.HandleInvoiceEvent(ctx, event)
+		case WebhookEventTypeRefund:
+			_, handleErr = uc.HandleRefundEvent(ctx, event)
+		case WebhookEventTypeVirtualAccount:
+			_, handleErr = uc.HandleVirtualAccountEvent(ctx, event)
+		case WebhookEventTypeQRCode:
+			_, handleErr = uc.HandleQRCodeEvent(ctx, event)
+		default:
+			handleErr = fmt.Errorf("unsupported webhook event type %q", event.EventType)
+			uc.recordOutcome(ctx, event, handleErr)
+		}
+
+		if handleErr != nil {
+			failed++
+			uc.Log.WithError(handleErr).WithField("event_id", event.EventID).Warn("Webhook replay failed")
+			continue
+		}
+		processed++
+	}
+
+	return processed, failed, nil
+}
+
+func (uc *WebhookUseCase) recordOutcome(ctx context.Context, event *entity.WebhookEvent, err error) {
+	now := time.Now()
+	event.Attempts++
+	event.ProcessedAt = &now
+	event.Status = entity.WebhookEventStatusProcessed
+	event.LastError = ""
+	if err != nil {
+		event.Status = entity.WebhookEventStatusFailed
+		event.LastError = err.Error()
+	}
+
+	if err := uc.WebhookEventRepository.Update(uc.DB.WithContext(ctx), event); err != nil {
+		uc.Log.WithError(err).WithField("event_id", event.EventID).Error("Failed to record webhook event outcome")
+	}
+}
+
+func webhookEventID(eventType string, header http.Header, body []byte) (string, error) {
+	if id := header.Get("webhook-id"); id != "" {
+		return id, nil
+	}
+
+	var payload struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Event  string `json:"event"`
+		Data   struct {
+			ID     string `json:"id"`
+			Status string `json:"status"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", err
+	}
+
+	switch {
+	case (eventType == WebhookEventTypeRefund || eventType == WebhookEventTypeQRCode) && payload.Data.ID != "":
+		return strings.Join([]string{eventType, payload.Data.ID, payload.Data.Status}, ":"), nil
+	case payload.ID != "":
+		return strings.Join([]string{eventType, payload.ID, payload.Status}, ":"), nil
+	default:
+		return "", errors.New("webhook payload has no identifier")
+	}
+}
+
+func redactWebhookHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range redactedWebhookHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, "[REDACTED]")
+		}
+	}
+	return redacted
+}
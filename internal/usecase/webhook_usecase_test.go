@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func invoiceCallbackBody(t *testing.T, created *model.CreateInvoiceResponse, status string) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(&model.XenditCallbackData{
+		ID:             created.XenditID,
+		ExternalID:     created.OrderID,
+		Amount:         float64(created.Amount.Value),
+		PaidAmount:     float64(created.Amount.Value),
+		Currency:       created.Amount.Currency,
+		Status:         status,
+		PayerEmail:     "payer@golectro.local",
+		Description:    "Golectro order",
+		PaymentMethod:  "BANK_TRANSFER",
+		PaymentChannel: "BCA",
+	})
+	require.NoError(t, err)
+	return body
+}
+
+func TestWebhookRedeliveryIsSkippedOnceProcessed(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+	_, err := env.Gateway.MarkInvoicePaid(created.XenditID, "BANK_TRANSFER", "BCA")
+	require.NoError(t, err)
+
+	header := http.Header{"X-Callback-Token": {"secret"}}
+	body := invoiceCallbackBody(t, created, "PAID")
+
+	event, duplicate, err := env.WebhookUseCase.Receive(context.Background(), WebhookEventTypeInvoice, header, body)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+	assert.Equal(t, "invoice:"+created.XenditID+":PAID", event.EventID)
+	assert.NotContains(t, string(event.Headers), "secret")
+
+	response, err := env.WebhookUseCase.HandleInvoiceEvent(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, string(entity.InvoiceStatusPaid), response.Status)
+
+	redelivered, duplicate, err := env.WebhookUseCase.Receive(context.Background(), WebhookEventTypeInvoice, header, body)
+	require.NoError(t, err)
+	assert.True(t, duplicate)
+	assert.Equal(t, event.ID, redelivered.ID)
+
+	stored := findWebhookEvent(t, env, event.EventID)
+	assert.Equal(t, entity.WebhookEventStatusProcessed, stored.Status)
+	assert.Equal(t, 2, stored.Deliveries)
+	assert.Equal(t, 1, stored.Attempts)
+}
+
+func TestWebhookRedeliveryRetriesFailedEvents(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+	header := http.Header{"Webhook-Id": {"delivery-1"}}
+	body := invoiceCallbackBody(t, created, "PAID")
+
+	// The first delivery names an invoice this service does not know.
+	created.XenditID = "unknown"
+	failing := invoiceCallbackBody(t, created, "PAID")
+	event, _, err := env.WebhookUseCase.Receive(context.Background(), WebhookEventTypeInvoice, header, failing)
+	require.NoError(t, err)
+	assert.Equal(t, "delivery-1", event.EventID)
+	_, err = env.WebhookUseCase.HandleInvoiceEvent(context.Background(), event)
+	require.Error(t, err)
+	assert.Equal(t, entity.WebhookEventStatusFailed, findWebhookEvent(t, env, "delivery-1").Status)
+
+	_, duplicate, err := env.WebhookUseCase.Receive(context.Background(), WebhookEventTypeInvoice, header, body)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+}
+
+func TestWebhookReceiveRejectsPayloadsWithoutIdentifier(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, body := range []string{`not json`, `{"status":"PAID"}`} {
+		_, _, err := env.WebhookUseCase.Receive(context.Background(), WebhookEventTypeInvoice, http.Header{}, []byte(body))
+		assert.ErrorIs(t, err, ErrInvalidWebhookPayload, body)
+	}
+}
+
+func TestWebhookReplay(t *testing.T) {
+	env := newTestEnv(t)
+	created := env.createInvoice(t, uuid.New(), "", 200000)
+
+	_, err := env.Gateway.MarkInvoicePaid(created.XenditID, "BANK_TRANSFER", "BCA")
+	require.NoError(t, err)
+
+	// The event was stored but the service stopped before handling it.
+	event, _, err := env.WebhookUseCase.Receive(context.Background(), WebhookEventTypeInvoice, http.Header{}, invoiceCallbackBody(t, created, "PAID"))
+	require.NoError(t, err)
+
+	processed, failed, err := env.WebhookUseCase.Replay(context.Background(), nil, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Zero(t, failed)
+	assert.Equal(t, entity.InvoiceStatusPaid, env.findInvoice(t, created.ID).Status)
+
+	stored := findWebhookEvent(t, env, event.EventID)
+	assert.Equal(t, entity.WebhookEventStatusProcessed, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+}
+
+func findWebhookEvent(t *testing.T, env *testEnv, eventID string) *entity.WebhookEvent {
+	t.Helper()
+
+	event := new(entity.WebhookEvent)
+	require.NoError(t, env.DB.Take(event, "event_id = ?", eventID).Error)
+	return event
+}
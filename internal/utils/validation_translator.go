@@ -3,6 +3,7 @@ package utils
 import (
 	"golectro-payment/internal/constants"
 	"golectro-payment/internal/model"
+	"golectro-payment/internal/money"
 
 	"github.com/go-playground/locales/en"
 	"github.com/go-playground/locales/id"
@@ -12,6 +13,15 @@ import (
 	id_translations "github.com/go-playground/validator/v10/translations/id"
 )
 
+// RegisterValidations adds the tags the request models use on top of the
+// validator's built-in ones. Amounts are stored in minor units, so currency
+// only accepts currencies whose precision is known.
+func RegisterValidations(v *validator.Validate) {
+	_ = v.RegisterValidation("currency", func(fl validator.FieldLevel) bool {
+		return money.IsKnown(fl.Field().String())
+	})
+}
+
 func InitTranslator(v *validator.Validate) (enTrans, idTrans ut.Translator) {
 	uni := ut.New(en.New(), en.New(), id.New())
 
@@ -20,6 +30,8 @@ func InitTranslator(v *validator.Validate) (enTrans, idTrans ut.Translator) {
 
 	_ = en_translations.RegisterDefaultTranslations(v, enTrans)
 	_ = id_translations.RegisterDefaultTranslations(v, idTrans)
+	registerTranslation(v, enTrans, "currency", "{0} must be a supported currency")
+	registerTranslation(v, idTrans, "currency", "{0} harus berupa mata uang yang didukung")
 
 	return
 }
@@ -38,6 +50,15 @@ func TranslateValidationError(v *validator.Validate, err error) model.Message {
 	}
 }
 
+func registerTranslation(v *validator.Validate, trans ut.Translator, tag, text string) {
+	_ = v.RegisterTranslation(tag, trans, func(ut ut.Translator) error {
+		return ut.Add(tag, text, true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		message, _ := ut.T(tag, fe.Field())
+		return message
+	})
+}
+
 func joinMessages(msgs map[string]string) string {
 	result := ""
 	for _, m := range msgs {
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"golectro-payment/internal/repository"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// retryPolicy spaces out the attempts to deliver a queued row exponentially
+// and gives up after MaxAttempts, so a poison row is not retried for ever.
+type retryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (p retryPolicy) backoff(attempts int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+// claimDue leases due rows of the queue to this worker and commits the claim,
+// so no row stays locked while it is being delivered. ready narrows the due
+// rows down to the ones to claim; nil claims them all.
+func claimDue[T any](ctx context.Context, db *gorm.DB, queue *repository.LeaseQueue[T], limit int, lease time.Duration, id func(*T) any, ready func(tx *gorm.DB, due []T) ([]T, error)) ([]T, error) {
+	var claimed []T
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var due []T
+		if err := queue.FindDueForUpdate(tx, now, limit, &due); err != nil {
+			return err
+		}
+		if len(due) == 0 {
+			return nil
+		}
+
+		claimed = due
+		if ready != nil {
+			var err error
+			if claimed, err = ready(tx, due); err != nil {
+				return err
+			}
+		}
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		ids := make([]any, len(claimed))
+		for i := range claimed {
+			ids[i] = id(&claimed[i])
+		}
+		return queue.Claim(tx, ids, now.Add(lease))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// recordFailure schedules another attempt at a row that failed to deliver, or
+// closes it in failed once the policy gives up on it.
+func recordFailure[T any](db *gorm.DB, queue *repository.LeaseQueue[T], policy retryPolicy, log *logrus.Entry, id any, attempts int, failed any, cause error) error {
+	if attempts >= policy.MaxAttempts {
+		log.WithError(cause).Errorf("Giving up on %s", queue.Name)
+		return queue.MarkClosed(db, id, failed, cause.Error())
+	}
+
+	next := time.Now().Add(policy.backoff(attempts))
+	log.WithError(cause).WithField("next_attempt_at", next).Warnf("Failed to deliver %s", queue.Name)
+	return queue.MarkRetry(db, id, next, cause.Error())
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/repository"
+	"golectro-payment/internal/usecase"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+var (
+	notificationsSent = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_notifications_sent_total",
+		Help: "Payer emails delivered to the mail server.",
+	}, []string{"template"})
+	notificationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_notification_failures_total",
+		Help: "Failed attempts to deliver payer emails.",
+	}, []string{"template"})
+)
+
+type NotificationDispatcher struct {
+	DB                     *gorm.DB
+	Log                    *logrus.Logger
+	NotificationRepository *repository.NotificationRepository
+	NotificationUseCase    *usecase.NotificationUseCase
+	Interval               time.Duration
+	BatchSize              int
+	MaxAttempts            int
+	RetryBackoff           time.Duration
+	MaxBackoff             time.Duration
+	ClaimLease             time.Duration
+}
+
+func NewNotificationDispatcher(db *gorm.DB, log *logrus.Logger, viper *viper.Viper, notificationRepository *repository.NotificationRepository, notificationUseCase *usecase.NotificationUseCase) *NotificationDispatcher {
+	dispatcher := &NotificationDispatcher{
+		DB:                     db,
+		Log:                    log,
+		NotificationRepository: notificationRepository,
+		NotificationUseCase:    notificationUseCase,
+		Interval:               viper.GetDuration("NOTIFICATION_POLL_INTERVAL"),
+		BatchSize:              viper.GetInt("NOTIFICATION_BATCH_SIZE"),
+		MaxAttempts:            viper.GetInt("NOTIFICATION_MAX_ATTEMPTS"),
+		RetryBackoff:           viper.GetDuration("NOTIFICATION_RETRY_BACKOFF"),
+		MaxBackoff:             viper.GetDuration("NOTIFICATION_MAX_BACKOFF"),
+		ClaimLease:             viper.GetDuration("NOTIFICATION_CLAIM_LEASE"),
+	}
+
+	if dispatcher.Interval <= 0 {
+		dispatcher.Interval = 10 * time.Second
+	}
+	if dispatcher.BatchSize <= 0 {
+		dispatcher.BatchSize = 20
+	}
+	if dispatcher.MaxAttempts <= 0 {
+		dispatcher.MaxAttempts = 8
+	}
+	if dispatcher.RetryBackoff <= 0 {
+		dispatcher.RetryBackoff = 30 * time.Second
+	}
+	if dispatcher.MaxBackoff <= 0 {
+		dispatcher.MaxBackoff = time.Hour
+	}
+	if dispatcher.ClaimLease <= 0 {
+		dispatcher.ClaimLease = 5 * time.Minute
+	}
+
+	return dispatcher
+}
+
+func (d *NotificationDispatcher) Name() string {
+	return "notification-dispatcher"
+}
+
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	d.Log.Infof("Notification dispatcher started, polling every %s", d.Interval)
+
+	ticker := time.NewTicker(d.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchBatch(ctx); err != nil && ctx.Err() == nil {
+			d.Log.WithError(err).Error("Notification dispatch batch failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			d.Log.Info("Notification dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchBatch claims due notifications with SKIP LOCKED and commits the
+// claim before sending, so several replicas can dispatch concurrently and no
+// row stays locked while the mail server is talking. Each outcome is recorded
+// on its own, so one failed write cannot resend the rest of the batch.
+func (d *NotificationDispatcher) DispatchBatch(ctx context.Context) error {
+	notifications, err := d.claim(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, notification := range notifications {
+		if err := d.dispatch(ctx, &notification); err != nil {
+			d.Log.WithError(err).WithField("notification_id", notification.ID).Error("Failed to record notification outcome")
+		}
+	}
+	return nil
+}
+
+// claim leases the batch to this dispatcher. A dispatcher that dies mid-batch
+// leaves its rows SENDING until the lease runs out, after which they are due
+// again.
+func (d *NotificationDispatcher) claim(ctx context.Context) ([]entity.Notification, error) {
+	return claimDue(ctx, d.DB, &d.NotificationRepository.LeaseQueue, d.BatchSize, d.ClaimLease, func(notification *entity.Notification) any {
+		return notification.ID
+	}, nil)
+}
+
+func (d *NotificationDispatcher) dispatch(ctx context.Context, notification *entity.Notification) error {
+	db := d.DB.WithContext(ctx)
+	fields := logrus.Fields{
+		"notification_id": notification.ID,
+		"invoice_id":      notification.InvoiceID,
+		"template":        notification.Template,
+		"attempts":        notification.Attempts + 1,
+	}
+
+	err := d.NotificationUseCase.Deliver(ctx, notification)
+	switch {
+	case err == nil:
+		notificationsSent.WithLabelValues(notification.Template).Inc()
+		return d.NotificationRepository.MarkDone(db, notification.ID, time.Now())
+	case errors.Is(err, usecase.ErrNotificationObsolete):
+		d.Log.WithFields(fields).Info("Skipping obsolete notification")
+		return d.NotificationRepository.MarkClosed(db, notification.ID, entity.NotificationStatusSkipped, err.Error())
+	}
+
+	notificationFailures.WithLabelValues(notification.Template).Inc()
+	policy := retryPolicy{MaxAttempts: d.MaxAttempts, Backoff: d.RetryBackoff, MaxBackoff: d.MaxBackoff}
+	return recordFailure(db, &d.NotificationRepository.LeaseQueue, policy, d.Log.WithFields(fields), notification.ID, notification.Attempts+1, entity.NotificationStatusFailed, err)
+}
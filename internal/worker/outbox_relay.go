@@ -0,0 +1,255 @@
+package worker
+
+import (
+	"context"
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/repository"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+var (
+	outboxPendingEvents = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "payment_outbox_pending_events",
+		Help: "Number of outbox events waiting to be published.",
+	})
+	outboxOldestPendingAge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "payment_outbox_oldest_pending_age_seconds",
+		Help: "Age of the oldest unpublished outbox event.",
+	})
+	outboxPublishLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "payment_outbox_publish_lag_seconds",
+		Help:    "Delay between an outbox event being written and published to Kafka.",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 14),
+	})
+	outboxPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_outbox_published_total",
+		Help: "Outbox events published to Kafka.",
+	}, []string{"event_type"})
+	outboxPublishFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_outbox_publish_failures_total",
+		Help: "Failed attempts to publish outbox events to Kafka.",
+	}, []string{"event_type"})
+	outboxDeadEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "payment_outbox_dead_events_total",
+		Help: "Outbox events given up on after too many failed publishes.",
+	}, []string{"event_type"})
+)
+
+type OutboxRelay struct {
+	DB               *gorm.DB
+	Log              *logrus.Logger
+	OutboxRepository *repository.OutboxRepository
+	KafkaWriter      *kafka.Writer
+	Interval         time.Duration
+	BatchSize        int
+	PublishTimeout   time.Duration
+	MaxBackoff       time.Duration
+	MaxAttempts      int
+	ClaimLease       time.Duration
+}
+
+func NewOutboxRelay(db *gorm.DB, log *logrus.Logger, viper *viper.Viper, outboxRepository *repository.OutboxRepository, kafkaWriter *kafka.Writer) *OutboxRelay {
+	relay := &OutboxRelay{
+		DB:               db,
+		Log:              log,
+		OutboxRepository: outboxRepository,
+		KafkaWriter:      kafkaWriter,
+		Interval:         viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+		BatchSize:        viper.GetInt("OUTBOX_BATCH_SIZE"),
+		PublishTimeout:   5 * time.Second,
+		MaxBackoff:       viper.GetDuration("OUTBOX_MAX_BACKOFF"),
+		MaxAttempts:      viper.GetInt("OUTBOX_MAX_ATTEMPTS"),
+		ClaimLease:       viper.GetDuration("OUTBOX_CLAIM_LEASE"),
+	}
+
+	if relay.Interval <= 0 {
+		relay.Interval = time.Second
+	}
+	if relay.BatchSize <= 0 {
+		relay.BatchSize = 100
+	}
+	if relay.MaxBackoff <= 0 {
+		relay.MaxBackoff = 5 * time.Minute
+	}
+	if relay.MaxAttempts <= 0 {
+		relay.MaxAttempts = 20
+	}
+	if relay.ClaimLease <= 0 {
+		relay.ClaimLease = 10 * time.Minute
+	}
+
+	return relay
+}
+
+func (r *OutboxRelay) Name() string {
+	return "outbox-relay"
+}
+
+func (r *OutboxRelay) Run(ctx context.Context) {
+	r.Log.Infof("Outbox relay started, polling every %s", r.Interval)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RelayBatch(ctx); err != nil && ctx.Err() == nil {
+			r.Log.WithError(err).Error("Outbox relay batch failed")
+		}
+		r.observeLag(ctx)
+
+		select {
+		case <-ctx.Done():
+			r.Log.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayBatch publishes due events in insertion order. Events are claimed and
+// committed before Kafka is called, so no row stays locked during a publish.
+// Once an event for an order cannot be published, later events for that order
+// wait for it so the order service always observes them in sequence.
+func (r *OutboxRelay) RelayBatch(ctx context.Context) error {
+	events, err := r.claim(ctx)
+	if err != nil {
+		return err
+	}
+
+	db := r.DB.WithContext(ctx)
+	blocked := make(map[string]bool)
+	var skipped []uint64
+
+	for _, event := range events {
+		if blocked[event.AggregateID] {
+			skipped = append(skipped, event.ID)
+			continue
+		}
+
+		if err := r.publish(ctx, &event); err != nil {
+			blocked[event.AggregateID] = true
+			outboxPublishFailures.WithLabelValues(event.EventType).Inc()
+			if err := r.recordFailure(db, &event, err); err != nil {
+				r.Log.WithError(err).WithField("outbox_id", event.ID).Error("Failed to record outbox publish failure")
+			}
+			continue
+		}
+
+		publishedAt := time.Now()
+		if err := r.OutboxRepository.MarkDone(db, event.ID, publishedAt); err != nil {
+			// The event is published again once its lease runs out, so later
+			// events of the order must not overtake it.
+			blocked[event.AggregateID] = true
+			r.Log.WithError(err).WithField("outbox_id", event.ID).Error("Failed to mark outbox event published")
+			continue
+		}
+		outboxPublished.WithLabelValues(event.EventType).Inc()
+		outboxPublishLag.Observe(publishedAt.Sub(event.CreatedAt).Seconds())
+	}
+
+	if len(skipped) > 0 {
+		return r.OutboxRepository.Release(db, skipped, time.Now())
+	}
+	return nil
+}
+
+// claim leases due events to this relay. An event is only claimed together
+// with every earlier unfinished event of its order, so an event that is
+// backing off or held by another relay also holds back the rest of its order.
+func (r *OutboxRelay) claim(ctx context.Context) ([]entity.OutboxEvent, error) {
+	return claimDue(ctx, r.DB, &r.OutboxRepository.LeaseQueue, r.BatchSize, r.ClaimLease, func(event *entity.OutboxEvent) any {
+		return event.ID
+	}, r.inOrder)
+}
+
+// inOrder drops the due events that an earlier unfinished event of the same
+// order has to go before.
+func (r *OutboxRelay) inOrder(tx *gorm.DB, due []entity.OutboxEvent) ([]entity.OutboxEvent, error) {
+	dueIDs := make(map[uint64]bool, len(due))
+	var aggregateIDs []string
+	for _, event := range due {
+		dueIDs[event.ID] = true
+		if !slices.Contains(aggregateIDs, event.AggregateID) {
+			aggregateIDs = append(aggregateIDs, event.AggregateID)
+		}
+	}
+
+	var open []entity.OutboxEvent
+	if err := r.OutboxRepository.FindOpenByAggregates(tx, aggregateIDs, due[len(due)-1].ID, &open); err != nil {
+		return nil, err
+	}
+
+	ready := make(map[uint64]bool, len(due))
+	waiting := make(map[string]bool)
+	for _, event := range open {
+		if waiting[event.AggregateID] {
+			continue
+		}
+		if !dueIDs[event.ID] {
+			waiting[event.AggregateID] = true
+			continue
+		}
+		ready[event.ID] = true
+	}
+
+	var events []entity.OutboxEvent
+	for _, event := range due {
+		if ready[event.ID] {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// recordFailure schedules a retry, or gives up on the event after
+// MaxAttempts so a poison event cannot hold back its order for ever.
+func (r *OutboxRelay) recordFailure(db *gorm.DB, event *entity.OutboxEvent, cause error) error {
+	log := r.Log.WithFields(logrus.Fields{
+		"outbox_id": event.ID,
+		"order_id":  event.AggregateID,
+		"attempts":  event.Attempts + 1,
+	})
+
+	policy := retryPolicy{MaxAttempts: r.MaxAttempts, Backoff: time.Second, MaxBackoff: r.MaxBackoff}
+	if event.Attempts+1 >= policy.MaxAttempts {
+		outboxDeadEvents.WithLabelValues(event.EventType).Inc()
+	}
+	return recordFailure(db, &r.OutboxRepository.LeaseQueue, policy, log, event.ID, event.Attempts+1, entity.OutboxEventStatusFailed, cause)
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, event *entity.OutboxEvent) error {
+	ctxKafka, cancel := context.WithTimeout(ctx, r.PublishTimeout)
+	defer cancel()
+
+	return r.KafkaWriter.WriteMessages(ctxKafka, kafka.Message{
+		Key:   []byte(event.AggregateID),
+		Value: event.Payload,
+		Headers: []kafka.Header{
+			{Key: "event-type", Value: []byte(event.EventType)},
+			{Key: "outbox-id", Value: []byte(strconv.FormatUint(event.ID, 10))},
+		},
+	})
+}
+
+func (r *OutboxRelay) observeLag(ctx context.Context) {
+	total, oldest, err := r.OutboxRepository.PendingStats(r.DB.WithContext(ctx))
+	if err != nil {
+		return
+	}
+
+	outboxPendingEvents.Set(float64(total))
+	if oldest == nil {
+		outboxOldestPendingAge.Set(0)
+		return
+	}
+	outboxOldestPendingAge.Set(time.Since(*oldest).Seconds())
+}
@@ -0,0 +1,158 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"golectro-payment/internal/entity"
+	"golectro-payment/internal/migrations"
+	"golectro-payment/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newTestRelay(t *testing.T) *OutboxRelay {
+	t.Helper()
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared&_busy_timeout=5000", uuid.NewString())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	require.NoError(t, migrations.Migrate(db))
+
+	log := logrus.New()
+	log.SetOutput(io.Discard)
+
+	v := viper.New()
+	v.Set("OUTBOX_MAX_ATTEMPTS", 3)
+	return NewOutboxRelay(db, log, v, repository.NewOutboxRepository(log), nil)
+}
+
+func addOutboxEvent(t *testing.T, relay *OutboxRelay, orderID string, nextAttemptAt time.Time) *entity.OutboxEvent {
+	t.Helper()
+
+	event := &entity.OutboxEvent{
+		AggregateType: "order",
+		AggregateID:   orderID,
+		EventType:     "invoice.updated",
+		Payload:       []byte(`{}`),
+		Status:        entity.OutboxEventStatusPending,
+		NextAttemptAt: nextAttemptAt,
+	}
+	require.NoError(t, relay.DB.Create(event).Error)
+	return event
+}
+
+func findOutboxEvent(t *testing.T, relay *OutboxRelay, id uint64) *entity.OutboxEvent {
+	t.Helper()
+
+	event := new(entity.OutboxEvent)
+	require.NoError(t, relay.DB.Take(event, "id = ?", id).Error)
+	return event
+}
+
+func TestOutboxRelayClaimKeepsOrderSequence(t *testing.T) {
+	relay := newTestRelay(t)
+	now := time.Now()
+
+	backingOff := addOutboxEvent(t, relay, "order-a", now.Add(time.Minute))
+	heldBack := addOutboxEvent(t, relay, "order-a", now.Add(-time.Second))
+	first := addOutboxEvent(t, relay, "order-b", now.Add(-time.Second))
+	second := addOutboxEvent(t, relay, "order-b", now.Add(-time.Second))
+
+	claimed, err := relay.claim(context.Background())
+	require.NoError(t, err)
+
+	ids := make([]uint64, 0, len(claimed))
+	for _, event := range claimed {
+		ids = append(ids, event.ID)
+	}
+	assert.Equal(t, []uint64{first.ID, second.ID}, ids)
+
+	assert.Equal(t, entity.OutboxEventStatusPending, findOutboxEvent(t, relay, backingOff.ID).Status)
+	assert.Equal(t, entity.OutboxEventStatusPending, findOutboxEvent(t, relay, heldBack.ID).Status)
+	leased := findOutboxEvent(t, relay, first.ID)
+	assert.Equal(t, entity.OutboxEventStatusPublishing, leased.Status)
+	assert.True(t, leased.NextAttemptAt.After(now.Add(relay.ClaimLease-time.Minute)))
+
+	// Claimed events are not due again until their lease runs out.
+	claimed, err = relay.claim(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, relay.DB.Model(&entity.OutboxEvent{}).Where("id = ?", first.ID).Update("next_attempt_at", now.Add(-time.Second)).Error)
+	claimed, err = relay.claim(context.Background())
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, first.ID, claimed[0].ID)
+}
+
+func TestOutboxRelayRecordFailure(t *testing.T) {
+	relay := newTestRelay(t)
+	event := addOutboxEvent(t, relay, "order-a", time.Now())
+	cause := fmt.Errorf("broker unavailable")
+
+	for attempt := 1; attempt < relay.MaxAttempts; attempt++ {
+		before := time.Now()
+		require.NoError(t, relay.recordFailure(relay.DB, findOutboxEvent(t, relay, event.ID), cause))
+
+		retried := findOutboxEvent(t, relay, event.ID)
+		assert.Equal(t, entity.OutboxEventStatusPending, retried.Status)
+		assert.Equal(t, attempt, retried.Attempts)
+		assert.Equal(t, cause.Error(), retried.LastError)
+		assert.False(t, retried.NextAttemptAt.Before(before.Add(time.Second<<(attempt-1))))
+	}
+
+	require.NoError(t, relay.recordFailure(relay.DB, findOutboxEvent(t, relay, event.ID), cause))
+	failed := findOutboxEvent(t, relay, event.ID)
+	assert.Equal(t, entity.OutboxEventStatusFailed, failed.Status)
+	assert.Equal(t, relay.MaxAttempts, failed.Attempts)
+}
+
+func TestOutboxRelayReleaseOnlyTouchesClaimedEvents(t *testing.T) {
+	relay := newTestRelay(t)
+	now := time.Now()
+	claimed := addOutboxEvent(t, relay, "order-a", now)
+	published := addOutboxEvent(t, relay, "order-b", now)
+
+	_, err := relay.claim(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, relay.OutboxRepository.MarkDone(relay.DB, published.ID, now))
+	require.NoError(t, relay.OutboxRepository.Release(relay.DB, []uint64{claimed.ID, published.ID}, now))
+
+	assert.Equal(t, entity.OutboxEventStatusPending, findOutboxEvent(t, relay, claimed.ID).Status)
+	done := findOutboxEvent(t, relay, published.ID)
+	assert.Equal(t, entity.OutboxEventStatusPublished, done.Status)
+	assert.NotNil(t, done.PublishedAt)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := retryPolicy{MaxAttempts: 10, Backoff: 30 * time.Second, MaxBackoff: 3 * time.Minute}
+
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{4, 3 * time.Minute},
+		{9, 3 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, policy.backoff(tt.attempts), "attempt %d", tt.attempts)
+	}
+}
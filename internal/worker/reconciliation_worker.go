@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"golectro-payment/internal/usecase"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type ReconciliationWorker struct {
+	Log                   *logrus.Logger
+	ReconciliationUseCase *usecase.ReconciliationUseCase
+	Interval              time.Duration
+}
+
+func NewReconciliationWorker(log *logrus.Logger, viper *viper.Viper, reconciliationUseCase *usecase.ReconciliationUseCase) *ReconciliationWorker {
+	worker := &ReconciliationWorker{
+		Log:                   log,
+		ReconciliationUseCase: reconciliationUseCase,
+		Interval:              viper.GetDuration("RECONCILE_INTERVAL"),
+	}
+
+	if worker.Interval <= 0 {
+		worker.Interval = 15 * time.Minute
+	}
+
+	return worker
+}
+
+func (w *ReconciliationWorker) Name() string {
+	return "reconciliation"
+}
+
+func (w *ReconciliationWorker) Run(ctx context.Context) {
+	w.Log.Infof("Reconciliation worker started, running every %s", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.Log.Info("Reconciliation worker stopped")
+			return
+		case <-ticker.C:
+			if _, err := w.ReconciliationUseCase.Reconcile(ctx, "scheduled", 0); err != nil && ctx.Err() == nil {
+				w.Log.WithError(err).Error("Scheduled reconciliation failed")
+			}
+		}
+	}
+}
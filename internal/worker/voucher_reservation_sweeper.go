@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"golectro-payment/internal/usecase"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+type VoucherReservationSweeper struct {
+	Log            *logrus.Logger
+	VoucherUseCase *usecase.VoucherUseCase
+	Interval       time.Duration
+	BatchSize      int
+}
+
+func NewVoucherReservationSweeper(log *logrus.Logger, viper *viper.Viper, voucherUseCase *usecase.VoucherUseCase) *VoucherReservationSweeper {
+	sweeper := &VoucherReservationSweeper{
+		Log:            log,
+		VoucherUseCase: voucherUseCase,
+		Interval:       viper.GetDuration("VOUCHER_SWEEP_INTERVAL"),
+		BatchSize:      viper.GetInt("VOUCHER_SWEEP_BATCH_SIZE"),
+	}
+
+	if sweeper.Interval <= 0 {
+		sweeper.Interval = time.Minute
+	}
+	if sweeper.BatchSize <= 0 {
+		sweeper.BatchSize = 100
+	}
+
+	return sweeper
+}
+
+func (w *VoucherReservationSweeper) Name() string {
+	return "voucher-reservation-sweeper"
+}
+
+func (w *VoucherReservationSweeper) Run(ctx context.Context) {
+	w.Log.Infof("Voucher reservation sweeper started, running every %s", w.Interval)
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.Log.Info("Voucher reservation sweeper stopped")
+			return
+		case <-ticker.C:
+			if _, err := w.VoucherUseCase.ReleaseExpiredReservations(ctx, w.BatchSize); err != nil && ctx.Err() == nil {
+				w.Log.WithError(err).Error("Failed to release expired voucher reservations")
+			}
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package worker
+
+import "context"
+
+type Worker interface {
+	Name() string
+	Run(ctx context.Context)
+}